@@ -4,15 +4,26 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"net/http"
+	"os"
+	"time"
 
 	"api/internal/config"
 	"api/internal/handler"
 	"api/internal/svc"
+	"idrm/pkg/health"
+	"idrm/pkg/lifecycle"
+	"idrm/pkg/middleware"
+	"idrm/pkg/ratelimit"
+	"idrm/pkg/telemetry"
+	"idrm/pkg/telemetry/metrics"
 
 	"github.com/zeromicro/go-zero/core/conf"
 	"github.com/zeromicro/go-zero/rest"
+	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
 var configFile = flag.String("f", "etc/api.yaml", "the config file")
@@ -22,13 +33,82 @@ func main() {
 
 	var c config.Config
 	conf.MustLoad(*configFile, &c)
+	// logx 只按第一次 SetUp 的配置初始化，由 rest 服务按 Observability.Log 初始化
+	telemetry.ApplyLogConf(&c.Log, c.Observability)
 
-	server := rest.MustNewServer(c.RestConf)
+	server := rest.MustNewServer(c.RestConf, serverOptions(c)...)
 	defer server.Stop()
 
 	ctx := svc.NewServiceContext(c)
 	handler.RegisterHandlers(server, ctx)
 
+	// 全局中间件，遥测组件已在 NewServiceContext 中初始化
+	useMiddlewares(server, c)
+	if c.Observability.Metrics.Enabled {
+		server.AddRoute(rest.Route{
+			Method:  http.MethodGet,
+			Path:    c.Observability.Metrics.Path,
+			Handler: metrics.Handler(),
+		})
+	}
+	if c.Health.Enabled {
+		addHealthRoutes(server, c, ctx.Health)
+	}
+
+	// 退出时逆序停止：先标记未就绪，再停止接收请求并等待处理中的请求，然后停止指标采集、写完审计日志、关闭数据库，最后刷新遥测数据
+	lc := lifecycle.New(time.Duration(c.Lifecycle.StopTimeout) * time.Second)
+	lc.Append(ctx.Hooks()...)
+	if c.RateLimit.ReloadInterval > 0 {
+		lc.Append(ratelimit.Watch(ctx.Limiter, *configFile, time.Duration(c.RateLimit.ReloadInterval)*time.Second))
+	}
+	lc.Append(lifecycle.RestServer(lc, server, time.Duration(c.Lifecycle.ShutdownTimeout)*time.Second))
+	if c.Health.Enabled {
+		lc.Append(ctx.Health.Hook(time.Duration(c.Health.DrainDelay) * time.Second))
+	}
+
 	fmt.Printf("Starting server at %s:%d...\n", c.Host, c.Port)
-	server.Start()
+	if err := lc.Run(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "服务退出: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// addHealthRoutes 注册存活、就绪探针和服务状态接口
+func addHealthRoutes(server *rest.Server, c config.Config, checker *health.Checker) {
+	build := health.NewBuildInfo(c.Observability.ServiceName, c.Observability.ServiceVersion, c.Observability.Environment)
+	server.AddRoutes([]rest.Route{
+		{Method: http.MethodGet, Path: "/healthz", Handler: health.LivenessHandler()},
+		{Method: http.MethodGet, Path: "/readyz", Handler: checker.ReadinessHandler()},
+		{Method: http.MethodGet, Path: "/status", Handler: checker.StatusHandler(build)},
+	})
+}
+
+// serverOptions 按配置组装服务选项
+// CORS 在路由层处理，未匹配路由的预检请求同样返回跨域响应头，而不是 405
+func serverOptions(c config.Config) []rest.RunOption {
+	var opts []rest.RunOption
+	if c.GlobalMiddlewares.Cors {
+		opts = append(opts, middleware.CorsOption(c.Cors.AllowOrigins, c.Cors.AllowMethods, c.Cors.AllowHeaders))
+	}
+	return opts
+}
+
+// useMiddlewares 按配置组装全局中间件链
+func useMiddlewares(server *rest.Server, c config.Config) {
+	m := c.GlobalMiddlewares
+	chain := middleware.Chain(middleware.ChainConfig{
+		RequestID: m.RequestID,
+		Trace:     m.Trace,
+		Logger:    m.Logger,
+		Metrics:   m.Metrics,
+		Recovery:  m.Recovery,
+	})
+	for _, mw := range chain {
+		server.Use(mw.Handle)
+	}
+
+	// 错误响应时记录 errorx 错误码，保持 httpx 默认的错误响应格式
+	if m.Metrics {
+		httpx.SetErrorHandlerCtx(metrics.ErrorHandler(nil))
+	}
 }
@@ -6,11 +6,55 @@ package config
 import (
 	"github.com/zeromicro/go-zero/rest"
 	"idrm/pkg/config"
+	"idrm/pkg/idempotency"
+	"idrm/pkg/ratelimit"
+	"idrm/pkg/telemetry"
 )
 
 type Config struct {
 	rest.RestConf
 
-	// 数据库配置
+	// 数据库配置（默认数据源）
 	Database config.DatabaseConfig
+
+	// 数据库迁移配置（可选，开启后启动时执行未执行的迁移）
+	Migration config.MigrationConfig `json:",optional"`
+
+	// 业务数据源配置（可选，首次使用时连接）
+	DataSources config.DataSourcesConfig `json:",optional"`
+
+	// JWT配置，业务接口的认证主体（租户、用户）取自令牌的 claim
+	Auth config.AuthConfig
+
+	// 多租户配置，租户从认证主体解析
+	Tenant config.TenantConfig `json:",optional"`
+
+	// 可信反向代理（可选），IP 或 CIDR；仅直连地址属于可信代理时才按 X-Forwarded-For 识别客户端IP
+	TrustedProxies []string `json:",optional"`
+
+	// Redis配置（可选，配置后启用标签缓存）
+	Redis config.RedisConfig `json:",optional"`
+
+	// 进程生命周期配置（可选），控制优雅退出的等待时间
+	Lifecycle config.LifecycleConfig `json:",optional"`
+
+	// 健康检查配置（可选，默认启用）
+	Health config.HealthConfig `json:",optional"`
+
+	// 限流配置（可选，默认关闭），修改规则后无需重启
+	RateLimit ratelimit.Config `json:",optional"`
+
+	// 幂等键配置（可选，默认关闭），写接口携带 Idempotency-Key 时重放首次响应
+	Idempotency idempotency.Config `json:",optional"`
+
+	// 全局中间件配置（可选，默认全部启用）
+	// 不使用 Middlewares 作为键名，避免与 RestConf 内置中间件配置冲突
+	GlobalMiddlewares config.MiddlewareConfig `json:",optional"`
+
+	// CORS配置（可选）
+	Cors config.CorsConfig `json:",optional"`
+
+	// 可观测性配置（日志、链路追踪、审计日志）
+	// 不使用 Telemetry 作为键名，避免与 RestConf 内置的链路追踪配置冲突
+	Observability telemetry.Config `json:",optional"`
 }
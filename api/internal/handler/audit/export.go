@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"api/internal/logic/audit"
+)
+
+// exportWriter 首次写入时才设置下载响应头，写入前发生的错误仍可按 JSON 错误返回
+type exportWriter struct {
+	w       http.ResponseWriter
+	format  string
+	started bool
+}
+
+func newExportWriter(w http.ResponseWriter, format string) *exportWriter {
+	return &exportWriter{w: w, format: format}
+}
+
+// Write 写入导出内容
+func (e *exportWriter) Write(p []byte) (int, error) {
+	if !e.started {
+		e.started = true
+		contentType := "text/csv; charset=utf-8"
+		if e.format == audit.FormatJSON {
+			contentType = "application/json; charset=utf-8"
+		}
+		filename := fmt.Sprintf("audit_logs_%s.%s", time.Now().Format("20060102150405"), e.format)
+		e.w.Header().Set("Content-Type", contentType)
+		e.w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		e.w.WriteHeader(http.StatusOK)
+	}
+	return e.w.Write(p)
+}
@@ -0,0 +1,37 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package audit
+
+import (
+	"net/http"
+
+	"api/internal/logic/audit"
+	"api/internal/svc"
+	"api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 审计日志导出
+func ExportAuditLogsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ExportAuditLogsReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := audit.NewExportAuditLogsLogic(r.Context(), svcCtx)
+		out := newExportWriter(w, req.Format)
+		if err := l.ExportAuditLogs(&req, out); err != nil {
+			// 已开始输出文件时无法再返回错误响应，只记录日志
+			if out.started {
+				logx.WithContext(r.Context()).Errorf("审计日志导出中断: %v", err)
+				return
+			}
+			httpx.ErrorCtx(r.Context(), w, err)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package audit
+
+import (
+	"net/http"
+
+	"api/internal/logic/audit"
+	"api/internal/svc"
+	"api/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 审计日志查询
+func ListAuditLogsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ListAuditLogsReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := audit.NewListAuditLogsLogic(r.Context(), svcCtx)
+		resp, err := l.ListAuditLogs(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -5,7 +5,9 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
+	audit "api/internal/handler/audit"
 	tag_management "api/internal/handler/tag_management"
 	"api/internal/svc"
 
@@ -15,7 +17,46 @@ import (
 func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 	server.AddRoutes(
 		rest.WithMiddlewares(
-			[]rest.Middleware{serverCtx.Auth},
+			[]rest.Middleware{serverCtx.Auth, serverCtx.RateLimit, serverCtx.Idempotency},
+			[]rest.Route{
+				{
+					// 审计日志查询
+					Method:  http.MethodGet,
+					Path:    "/audit/logs",
+					Handler: audit.ListAuditLogsHandler(serverCtx),
+				},
+				{
+					// 审计日志详情
+					Method:  http.MethodGet,
+					Path:    "/audit/logs/:id",
+					Handler: audit.GetAuditLogHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithJwt(serverCtx.Config.Auth.AccessSecret),
+		rest.WithPrefix("/api/v1"),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.Auth, serverCtx.RateLimit, serverCtx.Idempotency},
+			[]rest.Route{
+				{
+					// 审计日志导出
+					Method:  http.MethodGet,
+					Path:    "/audit/logs/export",
+					Handler: audit.ExportAuditLogsHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithJwt(serverCtx.Config.Auth.AccessSecret),
+		rest.WithPrefix("/api/v1"),
+		rest.WithTimeout(120000*time.Millisecond),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.Auth, serverCtx.RateLimit, serverCtx.Idempotency},
 			[]rest.Route{
 				{
 					// 按标签搜索数据
@@ -23,12 +64,30 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 					Path:    "/resources/search",
 					Handler: tag_management.SearchByTagsHandler(serverCtx),
 				},
+				{
+					// 批量获取资源标签
+					Method:  http.MethodGet,
+					Path:    "/resources/tags",
+					Handler: tag_management.GetResourcesTagsHandler(serverCtx),
+				},
+				{
+					// 资源在指定时间点的标签
+					Method:  http.MethodGet,
+					Path:    "/resources/tags/as-of",
+					Handler: tag_management.GetResourceTagsAsOfHandler(serverCtx),
+				},
 				{
 					// 为数据打标签
 					Method:  http.MethodPost,
 					Path:    "/resources/tags/assign",
 					Handler: tag_management.AssignTagsHandler(serverCtx),
 				},
+				{
+					// 资源标签变更历史
+					Method:  http.MethodGet,
+					Path:    "/resources/tags/history",
+					Handler: tag_management.GetResourceTagHistoryHandler(serverCtx),
+				},
 				{
 					// 移除数据标签
 					Method:  http.MethodPost,
@@ -59,14 +118,27 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 					Path:    "/tags/:id",
 					Handler: tag_management.GetTagHandler(serverCtx),
 				},
+				{
+					// 部分更新标签
+					Method:  http.MethodPatch,
+					Path:    "/tags/:id",
+					Handler: tag_management.PatchTagHandler(serverCtx),
+				},
 				{
 					// 删除标签
 					Method:  http.MethodDelete,
 					Path:    "/tags/:id",
 					Handler: tag_management.DeleteTagHandler(serverCtx),
 				},
+				{
+					// 标签变更历史
+					Method:  http.MethodGet,
+					Path:    "/tags/:id/history",
+					Handler: tag_management.GetTagHistoryHandler(serverCtx),
+				},
 			}...,
 		),
+		rest.WithJwt(serverCtx.Config.Auth.AccessSecret),
 		rest.WithPrefix("/api/v1"),
 	)
 }
@@ -0,0 +1,49 @@
+package tag_management
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"idrm/pkg/errorx"
+)
+
+// formatETag 以标签版本号生成强校验 ETag
+func formatETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// parseIfMatch 解析 If-Match 头中的版本号
+// 未携带或为 * 时 ok 返回 false，表示不做额外的前置条件校验
+func parseIfMatch(r *http.Request) (version int64, ok bool, err error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, false, nil
+	}
+
+	value = strings.TrimPrefix(value, "W/")
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return 0, false, errorx.NewWithMsg(errorx.ErrCodeParamFormat, "If-Match 格式错误")
+	}
+	version, err = strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false, errorx.NewWithMsg(errorx.ErrCodeParamFormat, "If-Match 格式错误")
+	}
+	return version, true, nil
+}
+
+// isVersionConflict 判断是否为版本冲突
+func isVersionConflict(err error) bool {
+	e, ok := err.(*errorx.CodeError)
+	return ok && e.GetCode() == errorx.ErrCodeTagVersionConflict
+}
+
+// conflictStatus 版本冲突的 HTTP 状态码
+// 版本号来自 If-Match 时为前置条件不满足（412），来自请求体时为资源状态冲突（409）
+func conflictStatus(ifMatch bool) int {
+	if ifMatch {
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusConflict
+}
@@ -29,6 +29,7 @@ func GetTagHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 		if err != nil {
 			httpx.ErrorCtx(r.Context(), w, err)
 		} else {
+			w.Header().Set("ETag", formatETag(resp.Version))
 			httpx.OkJsonCtx(r.Context(), w, resp)
 		}
 	}
@@ -0,0 +1,55 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package tag_management
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"api/internal/logic/tag_management"
+	"api/internal/svc"
+	"api/internal/types"
+
+	"idrm/pkg/errorx"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 部分更新标签
+// 请求体按 JSON Merge Patch 解析，版本号可通过 If-Match 头或 version 字段传递
+func PatchTagHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.PatchTagReq
+		if err := httpx.ParsePath(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		version, ifMatch, err := parseIfMatch(r)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		// 补丁必须是 JSON 对象，null 或数组等不视为合法补丁
+		var patch map[string]json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || patch == nil {
+			httpx.ErrorCtx(r.Context(), w, errorx.NewWithMsg(errorx.ErrCodeParamFormat, "请求体必须为 JSON 对象"))
+			return
+		}
+
+		l := tag_management.NewPatchTagLogic(r.Context(), svcCtx)
+		resp, err := l.PatchTag(req.Id, version, patch)
+		if err != nil {
+			if isVersionConflict(err) {
+				httpx.WriteJsonCtx(r.Context(), w, conflictStatus(ifMatch), err)
+				return
+			}
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			w.Header().Set("ETag", formatETag(resp.Version))
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
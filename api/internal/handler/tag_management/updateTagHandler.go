@@ -9,24 +9,46 @@ import (
 	"api/internal/logic/tag_management"
 	"api/internal/svc"
 	"api/internal/types"
+
+	"idrm/pkg/errorx"
+
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
 // 更新标签
+// 版本号可通过请求体 version 或 If-Match 头传递，两者同时存在时必须一致
 func UpdateTagHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req types.UpdateTagResp
+		var req types.UpdateTagReq
 		if err := httpx.Parse(r, &req); err != nil {
 			httpx.ErrorCtx(r.Context(), w, err)
 			return
 		}
 
+		version, ok, err := parseIfMatch(r)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		if ok {
+			if req.Version != 0 && req.Version != version {
+				httpx.ErrorCtx(r.Context(), w, errorx.NewWithMsg(errorx.ErrCodeParamInvalid, "请求体版本号与 If-Match 不一致"))
+				return
+			}
+			req.Version = version
+		}
+
 		l := tag_management.NewUpdateTagLogic(r.Context(), svcCtx)
-		err := l.UpdateTag(&req)
+		resp, err := l.UpdateTag(&req)
 		if err != nil {
+			if isVersionConflict(err) {
+				httpx.WriteJsonCtx(r.Context(), w, conflictStatus(ok), err)
+				return
+			}
 			httpx.ErrorCtx(r.Context(), w, err)
 		} else {
-			httpx.Ok(w)
+			w.Header().Set("ETag", formatETag(resp.Version))
+			httpx.OkJsonCtx(r.Context(), w, resp)
 		}
 	}
 }
@@ -0,0 +1,179 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"api/internal/config"
+	"api/internal/logic/audit/mocks"
+	"api/internal/svc"
+	"api/internal/types"
+
+	"idrm/model/audit/audit_log"
+	pkgconfig "idrm/pkg/config"
+	"idrm/pkg/errorx"
+	"idrm/pkg/operator"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// adminContext 携带管理员角色的上下文
+func adminContext() context.Context {
+	return operator.WithRoles(context.Background(), []string{"admin"})
+}
+
+// newTestSvcCtx 创建配置了管理员角色的服务上下文
+func newTestSvcCtx(model audit_log.AuditLogModel) *svc.ServiceContext {
+	return &svc.ServiceContext{
+		Config:        config.Config{Auth: pkgconfig.AuthConfig{AdminRole: "admin"}},
+		AuditLogModel: model,
+	}
+}
+
+// TestAuditLogic_RequireAdmin 测试非管理员不能查询和导出审计日志
+func TestAuditLogic_RequireAdmin(t *testing.T) {
+	mockModel := new(mocks.MockAuditLogModel)
+	svcCtx := newTestSvcCtx(mockModel)
+	ctx := operator.WithRoles(context.Background(), []string{"viewer"})
+
+	_, err := NewListAuditLogsLogic(ctx, svcCtx).ListAuditLogs(&types.ListAuditLogsReq{})
+	assert.Equal(t, errorx.ErrCodeForbidden, err.(*errorx.CodeError).GetCode())
+
+	_, err = NewGetAuditLogLogic(ctx, svcCtx).GetAuditLog(&types.AuditLogReq{Id: 1})
+	assert.Equal(t, errorx.ErrCodeForbidden, err.(*errorx.CodeError).GetCode())
+
+	var buf bytes.Buffer
+	err = NewExportAuditLogsLogic(ctx, svcCtx).ExportAuditLogs(&types.ExportAuditLogsReq{Format: FormatCSV}, &buf)
+	assert.Equal(t, errorx.ErrCodeForbidden, err.(*errorx.CodeError).GetCode())
+	assert.Zero(t, buf.Len())
+
+	// 未配置角色时同样拒绝
+	_, err = NewListAuditLogsLogic(context.Background(), svcCtx).ListAuditLogs(&types.ListAuditLogsReq{})
+	assert.Equal(t, errorx.ErrCodeForbidden, err.(*errorx.CodeError).GetCode())
+	mockModel.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestListAuditLogsLogic_Cursor 测试游标分页
+func TestListAuditLogsLogic_Cursor(t *testing.T) {
+	mockModel := new(mocks.MockAuditLogModel)
+	ctx := adminContext()
+	svcCtx := newTestSvcCtx(mockModel)
+
+	logs := []*audit_log.AuditLog{{Id: 9}, {Id: 8}, {Id: 7}}
+	mockModel.On("List", ctx, mock.AnythingOfType("*audit_log.Filter"), int64(0), 3).Return(logs, nil)
+	mockModel.On("List", ctx, mock.AnythingOfType("*audit_log.Filter"), int64(8), 3).Return(logs[2:], nil)
+
+	resp, err := NewListAuditLogsLogic(ctx, svcCtx).ListAuditLogs(&types.ListAuditLogsReq{Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, resp.List, 2)
+	assert.True(t, resp.HasMore)
+
+	resp, err = NewListAuditLogsLogic(ctx, svcCtx).ListAuditLogs(&types.ListAuditLogsReq{Limit: 2, Cursor: resp.NextCursor})
+	assert.NoError(t, err)
+	assert.Len(t, resp.List, 1)
+	assert.False(t, resp.HasMore)
+	assert.Empty(t, resp.NextCursor)
+}
+
+// TestListAuditLogsLogic_Limit 测试每页条数的修正
+func TestListAuditLogsLogic_Limit(t *testing.T) {
+	mockModel := new(mocks.MockAuditLogModel)
+	ctx := adminContext()
+	svcCtx := newTestSvcCtx(mockModel)
+
+	tests := []struct {
+		limit    int
+		expected int
+	}{
+		{0, defaultLimit},
+		{-1, defaultLimit},
+		{maxLimit + 1, maxLimit},
+		{5, 5},
+	}
+	for _, tt := range tests {
+		logs := make([]*audit_log.AuditLog, tt.expected+1)
+		for i := range logs {
+			logs[i] = &audit_log.AuditLog{Id: int64(len(logs) - i)}
+		}
+		mockModel.On("List", ctx, mock.AnythingOfType("*audit_log.Filter"), int64(0), tt.expected+1).Return(logs, nil).Once()
+
+		resp, err := NewListAuditLogsLogic(ctx, svcCtx).ListAuditLogs(&types.ListAuditLogsReq{Limit: tt.limit})
+		assert.NoError(t, err)
+		assert.Len(t, resp.List, tt.expected)
+		assert.True(t, resp.HasMore)
+	}
+	mockModel.AssertExpectations(t)
+}
+
+// TestListAuditLogsLogic_InvalidParam 测试时间和游标参数校验
+func TestListAuditLogsLogic_InvalidParam(t *testing.T) {
+	logic := NewListAuditLogsLogic(adminContext(), newTestSvcCtx(nil))
+
+	tests := []*types.ListAuditLogsReq{
+		{Limit: 20, From: "昨天"},
+		{Limit: 20, From: "2026-01-10", To: "2026-01-09"},
+		{Limit: 20, Cursor: "!!"},
+	}
+	for _, req := range tests {
+		_, err := logic.ListAuditLogs(req)
+		assert.IsType(t, &errorx.CodeError{}, err)
+	}
+}
+
+// TestGetAuditLogLogic_Diff 测试详情返回快照差异
+func TestGetAuditLogLogic_Diff(t *testing.T) {
+	mockModel := new(mocks.MockAuditLogModel)
+	ctx := adminContext()
+	mockModel.On("FindOne", ctx, int64(1)).Return(&audit_log.AuditLog{
+		Id:         1,
+		BeforeData: `{"name":"财务","version":1}`,
+		AfterData:  `{"name":"财务部","version":2}`,
+		Extra:      `{"reason":"改名"}`,
+	}, nil)
+	mockModel.On("FindOne", ctx, int64(2)).Return(nil, audit_log.ErrNotFound)
+
+	logic := NewGetAuditLogLogic(ctx, newTestSvcCtx(mockModel))
+	resp, err := logic.GetAuditLog(&types.AuditLogReq{Id: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.AuditChange{
+		{Path: "name", Op: "changed", Before: "财务", After: "财务部"},
+		{Path: "version", Op: "changed", Before: 1.0, After: 2.0},
+	}, resp.Changes)
+	assert.Equal(t, "改名", resp.Extra["reason"])
+
+	_, err = logic.GetAuditLog(&types.AuditLogReq{Id: 2})
+	assert.Equal(t, errorx.ErrCodeNotFound, err.(*errorx.CodeError).GetCode())
+}
+
+// TestExportAuditLogsLogic 测试 CSV 和 JSON 导出
+func TestExportAuditLogsLogic(t *testing.T) {
+	mockModel := new(mocks.MockAuditLogModel)
+	ctx := adminContext()
+	logs := []*audit_log.AuditLog{
+		{Id: 2, OccurredAt: time.Now(), Action: "update", ErrorMsg: "=HYPERLINK()", AfterData: `{"name":"财务部"}`},
+		{Id: 1, OccurredAt: time.Now(), Action: "create", Success: true},
+	}
+	mockModel.On("Each", ctx, mock.AnythingOfType("*audit_log.Filter"), exportBatchSize, mock.Anything).
+		Return(func(_ context.Context, _ *audit_log.Filter, _ int, fn func([]*audit_log.AuditLog) error) error {
+			return fn(logs)
+		})
+	logic := NewExportAuditLogsLogic(ctx, newTestSvcCtx(mockModel))
+
+	var buf bytes.Buffer
+	assert.NoError(t, logic.ExportAuditLogs(&types.ExportAuditLogsReq{Format: FormatCSV}, &buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[1], "'=HYPERLINK()")
+
+	buf.Reset()
+	assert.NoError(t, logic.ExportAuditLogs(&types.ExportAuditLogsReq{Format: FormatJSON}, &buf))
+	var records []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &records))
+	assert.Len(t, records, 2)
+	assert.Equal(t, map[string]interface{}{"name": "财务部"}, records[0]["after"])
+}
@@ -0,0 +1,215 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package audit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"api/internal/svc"
+	"api/internal/types"
+
+	"idrm/model/audit/audit_log"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// FormatCSV CSV 导出格式
+	FormatCSV = "csv"
+	// FormatJSON JSON 导出格式
+	FormatJSON = "json"
+
+	// maxExportRows 单次导出的最大条数，超出部分截断
+	// 超时中间件会在内存中缓冲整个响应，条数不宜过大，更早的记录需缩小时间范围分批导出
+	maxExportRows = 10000
+	// exportBatchSize 导出时每批查询的条数
+	exportBatchSize = 500
+)
+
+// errExportLimit 达到导出条数上限
+var errExportLimit = errors.New("达到导出条数上限")
+
+// csvHeader CSV 导出的表头
+var csvHeader = []string{
+	"id", "eventId", "occurredAt", "serviceName", "tenantId", "action", "resource",
+	"userId", "username", "ip", "method", "path", "traceId", "success", "errorMsg",
+	"duration", "before", "after", "extra",
+}
+
+// exportRecord JSON 导出的单条记录，快照按原始 JSON 输出
+type exportRecord struct {
+	types.AuditLogInfo
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+	Extra  json.RawMessage `json:"extra,omitempty"`
+}
+
+type ExportAuditLogsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 审计日志导出
+func NewExportAuditLogsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExportAuditLogsLogic {
+	return &ExportAuditLogsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ExportAuditLogs 按条件导出审计日志到 w，按时间倒序，最多导出 maxExportRows 条
+// 权限或参数校验失败时不会写入 w，调用方可据此返回错误响应
+func (l *ExportAuditLogsLogic) ExportAuditLogs(req *types.ExportAuditLogsReq, w io.Writer) error {
+	if err := requireAdmin(l.ctx, l.svcCtx); err != nil {
+		return err
+	}
+	filter, err := buildFilter(req.Action, req.Resource, req.UserId, req.TraceId, req.Success, req.From, req.To)
+	if err != nil {
+		return err
+	}
+
+	var write func([]*audit_log.AuditLog) error
+	var finish func() error
+	switch req.Format {
+	case FormatJSON:
+		write, finish = jsonExporter(w)
+	default:
+		write, finish = csvExporter(w)
+	}
+
+	var rows int
+	err = l.svcCtx.AuditLogModel.Each(l.ctx, filter, exportBatchSize, func(batch []*audit_log.AuditLog) error {
+		if rows+len(batch) > maxExportRows {
+			batch = batch[:maxExportRows-rows]
+		}
+		if err := write(batch); err != nil {
+			return err
+		}
+		rows += len(batch)
+		if rows >= maxExportRows {
+			return errExportLimit
+		}
+		return nil
+	})
+	if errors.Is(err, errExportLimit) {
+		l.Infof("审计日志导出达到上限 %d 条，已截断", maxExportRows)
+		err = nil
+	}
+	if err != nil {
+		l.Errorf("导出审计日志失败: %v", err)
+		return fmt.Errorf("导出审计日志失败: %w", err)
+	}
+	return finish()
+}
+
+// csvExporter 以 CSV 格式写出，首次写入时输出表头
+func csvExporter(w io.Writer) (write func([]*audit_log.AuditLog) error, finish func() error) {
+	cw := csv.NewWriter(w)
+	headerWritten := false
+
+	writeHeader := func() error {
+		if headerWritten {
+			return nil
+		}
+		headerWritten = true
+		return cw.Write(csvHeader)
+	}
+
+	write = func(batch []*audit_log.AuditLog) error {
+		if err := writeHeader(); err != nil {
+			return err
+		}
+		for _, log := range batch {
+			row := []string{
+				strconv.FormatInt(log.Id, 10), log.EventId, log.OccurredAt.Format("2006-01-02 15:04:05"),
+				log.ServiceName, log.TenantId, log.Action, log.Resource, log.UserId, log.Username,
+				log.Ip, log.Method, log.Path, log.TraceId, strconv.FormatBool(log.Success), log.ErrorMsg,
+				strconv.FormatInt(log.Duration, 10), log.BeforeData, log.AfterData, log.Extra,
+			}
+			for i := range row {
+				row[i] = escapeCell(row[i])
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	finish = func() error {
+		if err := writeHeader(); err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return write, finish
+}
+
+// jsonExporter 以 JSON 数组格式流式写出
+func jsonExporter(w io.Writer) (write func([]*audit_log.AuditLog) error, finish func() error) {
+	started := false
+
+	write = func(batch []*audit_log.AuditLog) error {
+		for _, log := range batch {
+			data, err := json.Marshal(exportRecord{
+				AuditLogInfo: toAuditLogInfo(log),
+				Before:       rawJSON(log.BeforeData),
+				After:        rawJSON(log.AfterData),
+				Extra:        rawJSON(log.Extra),
+			})
+			if err != nil {
+				return err
+			}
+			sep := ","
+			if !started {
+				sep = "["
+				started = true
+			}
+			if _, err := io.WriteString(w, sep); err != nil {
+				return err
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	finish = func() error {
+		end := "]"
+		if !started {
+			end = "[]"
+		}
+		_, err := io.WriteString(w, end)
+		return err
+	}
+	return write, finish
+}
+
+// escapeCell 防止 CSV 单元格在表格软件中被当作公式执行
+func escapeCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// rawJSON 将 JSON 文本转换为原始消息，空文本或格式错误时省略
+func rawJSON(text string) json.RawMessage {
+	if text == "" || !json.Valid([]byte(text)) {
+		return nil
+	}
+	return json.RawMessage(text)
+}
@@ -0,0 +1,84 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"api/internal/svc"
+	"api/internal/types"
+
+	"idrm/model/audit/audit_log"
+	"idrm/pkg/errorx"
+	pkgaudit "idrm/pkg/telemetry/audit"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetAuditLogLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 审计日志详情
+func NewGetAuditLogLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetAuditLogLogic {
+	return &GetAuditLogLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetAuditLog 查询审计日志详情，返回变更前后快照及字段级差异
+func (l *GetAuditLogLogic) GetAuditLog(req *types.AuditLogReq) (resp *types.AuditLogResp, err error) {
+	if err := requireAdmin(l.ctx, l.svcCtx); err != nil {
+		return nil, err
+	}
+	log, err := l.svcCtx.AuditLogModel.FindOne(l.ctx, req.Id)
+	if err != nil {
+		if err == audit_log.ErrNotFound {
+			return nil, errorx.NewWithMsg(errorx.ErrCodeNotFound, "审计日志不存在")
+		}
+		l.Errorf("查询审计日志失败: %v", err)
+		return nil, fmt.Errorf("查询审计日志失败: %w", err)
+	}
+
+	before := l.decode(log.BeforeData)
+	after := l.decode(log.AfterData)
+	extra, _ := l.decode(log.Extra).(map[string]interface{})
+
+	changes := make([]types.AuditChange, 0)
+	for _, c := range pkgaudit.Diff(before, after) {
+		changes = append(changes, types.AuditChange{
+			Path:   c.Path,
+			Op:     c.Op,
+			Before: c.Before,
+			After:  c.After,
+		})
+	}
+
+	return &types.AuditLogResp{
+		AuditLogInfo: toAuditLogInfo(log),
+		Before:       before,
+		After:        after,
+		Extra:        extra,
+		Changes:      changes,
+	}, nil
+}
+
+// decode 解析 JSON 文本，空文本或格式错误时返回 nil
+func (l *GetAuditLogLogic) decode(text string) interface{} {
+	if text == "" {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(text), &v); err != nil {
+		l.Errorf("解析审计日志快照失败: %v", err)
+		return nil
+	}
+	return v
+}
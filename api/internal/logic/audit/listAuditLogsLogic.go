@@ -0,0 +1,169 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package audit
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+
+	"api/internal/svc"
+	"api/internal/types"
+
+	"idrm/model/audit/audit_log"
+	"idrm/pkg/errorx"
+	"idrm/pkg/operator"
+	"idrm/pkg/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// defaultLimit 默认每页条数
+	defaultLimit = 20
+	// maxLimit 每页最大条数
+	maxLimit = 100
+)
+
+type ListAuditLogsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 审计日志查询
+func NewListAuditLogsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListAuditLogsLogic {
+	return &ListAuditLogsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListAuditLogs 按条件游标分页查询审计日志，按时间倒序
+func (l *ListAuditLogsLogic) ListAuditLogs(req *types.ListAuditLogsReq) (resp *types.ListAuditLogsResp, err error) {
+	if err := requireAdmin(l.ctx, l.svcCtx); err != nil {
+		return nil, err
+	}
+	filter, err := buildFilter(req.Action, req.Resource, req.UserId, req.TraceId, req.Success, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := decodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := normalizeLimit(req.Limit)
+
+	// 多查一条判断是否还有下一页
+	logs, err := l.svcCtx.AuditLogModel.List(l.ctx, filter, cursor, limit+1)
+	if err != nil {
+		l.Errorf("查询审计日志失败: %v", err)
+		return nil, fmt.Errorf("查询审计日志失败: %w", err)
+	}
+	hasMore := len(logs) > limit
+	if hasMore {
+		logs = logs[:limit]
+	}
+
+	list := make([]types.AuditLogInfo, 0, len(logs))
+	for _, log := range logs {
+		list = append(list, toAuditLogInfo(log))
+	}
+	resp = &types.ListAuditLogsResp{List: list, HasMore: hasMore}
+	if hasMore {
+		resp.NextCursor = encodeCursor(logs[len(logs)-1].Id)
+	}
+	return resp, nil
+}
+
+// requireAdmin 审计日志仅允许管理员角色查询和导出
+func requireAdmin(ctx context.Context, svcCtx *svc.ServiceContext) error {
+	if !operator.HasRole(ctx, svcCtx.Config.Auth.AdminRole) {
+		return errorx.NewWithMsg(errorx.ErrCodeForbidden, "仅管理员可以查看审计日志")
+	}
+	return nil
+}
+
+// normalizeLimit 修正每页条数，未传或非正数时取默认值，超过上限时取上限
+func normalizeLimit(limit int) int {
+	if limit <= 0 {
+		return defaultLimit
+	}
+	if limit > maxLimit {
+		return maxLimit
+	}
+	return limit
+}
+
+// buildFilter 校验并转换查询条件
+func buildFilter(action, resource, userId, traceId string, success *bool, from, to string) (*audit_log.Filter, error) {
+	filter := &audit_log.Filter{
+		Action:   action,
+		Resource: resource,
+		UserId:   userId,
+		TraceId:  traceId,
+		Success:  success,
+	}
+
+	var err error
+	if from != "" {
+		if filter.From, err = utils.ParseTime(from); err != nil {
+			return nil, err
+		}
+	}
+	if to != "" {
+		if filter.To, err = utils.ParseTime(to); err != nil {
+			return nil, err
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, errorx.NewWithMsg(errorx.ErrCodeParamInvalid, "开始时间必须早于结束时间")
+	}
+	return filter, nil
+}
+
+// encodeCursor 将最后一条记录的ID编码为不透明游标
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+// decodeCursor 解析游标，空游标表示第一页
+func decodeCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if id, err := strconv.ParseInt(string(data), 10, 64); err == nil && id > 0 {
+			return id, nil
+		}
+	}
+	return 0, errorx.NewWithMsg(errorx.ErrCodeParamFormat, "游标无效")
+}
+
+// toAuditLogInfo 转换为审计日志响应
+func toAuditLogInfo(log *audit_log.AuditLog) types.AuditLogInfo {
+	return types.AuditLogInfo{
+		Id:          log.Id,
+		EventId:     log.EventId,
+		OccurredAt:  log.OccurredAt.Format(time.RFC3339),
+		ServiceName: log.ServiceName,
+		TenantId:    log.TenantId,
+		Action:      log.Action,
+		Resource:    log.Resource,
+		UserId:      log.UserId,
+		Username:    log.Username,
+		Ip:          log.Ip,
+		Method:      log.Method,
+		Path:        log.Path,
+		TraceId:     log.TraceId,
+		Success:     log.Success,
+		ErrorMsg:    log.ErrorMsg,
+		Duration:    log.Duration,
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"idrm/model/tag_management/tag"
 	"idrm/pkg/errorx"
+	"idrm/pkg/operator"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -36,6 +37,10 @@ func (l *CreateTagLogic) CreateTag(req *types.CreateTagReq) (resp *types.CreateT
 	if err := l.validateReq(req); err != nil {
 		return nil, err
 	}
+	userID, ok := operator.FromContext(l.ctx)
+	if !ok {
+		return nil, errorx.NewWithMsg(errorx.ErrCodeUnauthorized, "无法识别当前用户")
+	}
 
 	// 2. 检查名称是否已存在
 	existing, err := l.svcCtx.TagModel.FindByName(l.ctx, req.Name)
@@ -44,7 +49,7 @@ func (l *CreateTagLogic) CreateTag(req *types.CreateTagReq) (resp *types.CreateT
 		return nil, fmt.Errorf("查询标签失败: %w", err)
 	}
 	if existing != nil {
-		return nil, errorx.New(errorx.ErrCodeTagAlreadyExists)
+		return nil, errorx.NewWithCode(errorx.ErrCodeTagAlreadyExists)
 	}
 
 	// 3. 设置默认颜色
@@ -59,7 +64,7 @@ func (l *CreateTagLogic) CreateTag(req *types.CreateTagReq) (resp *types.CreateT
 		Description: req.Description,
 		Color:       color,
 		Status:      tag.StatusEnabled,
-		CreatedBy:   1, // TODO: 从上下文获取用户ID
+		CreatedBy:   userID,
 	}
 
 	// 5. 插入数据库
@@ -12,6 +12,7 @@ import (
 
 	"idrm/model/tag_management/tag"
 	"idrm/pkg/errorx"
+	"idrm/pkg/tenant"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -33,13 +34,17 @@ func NewDeleteTagLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteT
 
 func (l *DeleteTagLogic) DeleteTag(id int64) (resp *types.DeleteTagResp, err error) {
 	// 1. 验证标签存在
-	_, err = l.svcCtx.TagModel.FindOne(l.ctx, id)
+	existing, err := l.svcCtx.TagModel.FindOne(l.ctx, id)
 	if err != nil {
 		if err == tag.ErrNotFound {
-			return nil, errorx.New(errorx.ErrCodeTagNotFound)
+			return nil, errorx.NewWithCode(errorx.ErrCodeTagNotFound)
 		}
 		return nil, fmt.Errorf("查询标签失败: %w", err)
 	}
+	// 共享标签对租户只读
+	if !tenant.Owns(l.ctx, existing.TenantId) {
+		return nil, errorx.NewWithCode(errorx.ErrCodeTagReadOnly)
+	}
 
 	// 2. 检查是否被使用
 	count, err := l.svcCtx.ResourceTagModel.CountByTag(l.ctx, id)
@@ -47,7 +52,7 @@ func (l *DeleteTagLogic) DeleteTag(id int64) (resp *types.DeleteTagResp, err err
 		return nil, fmt.Errorf("检查标签使用情况失败: %w", err)
 	}
 	if count > 0 {
-		return nil, errorx.New(errorx.ErrCodeTagInUse)
+		return nil, errorx.NewWithCode(errorx.ErrCodeTagInUse)
 	}
 
 	// 3. 删除标签
@@ -0,0 +1,57 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package tag_management
+
+import (
+	"context"
+	"fmt"
+
+	"api/internal/svc"
+	"api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetResourceTagHistoryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 资源标签变更历史
+func NewGetResourceTagHistoryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetResourceTagHistoryLogic {
+	return &GetResourceTagHistoryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetResourceTagHistory 查询资源的打标签/移除标签记录
+func (l *GetResourceTagHistoryLogic) GetResourceTagHistory(req *types.ResourceTagHistoryReq) (resp *types.ResourceTagHistoryResp, err error) {
+	page, pageSize := normalizePage(req.Page, req.PageSize)
+
+	entries, total, err := l.svcCtx.HistoryModel.ListResourceHistory(l.ctx, req.ResourceId, req.ResourceType, page, pageSize)
+	if err != nil {
+		l.Errorf("查询资源标签变更历史失败: %v", err)
+		return nil, fmt.Errorf("查询资源标签变更历史失败: %w", err)
+	}
+
+	list := make([]types.ResourceTagChange, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, types.ResourceTagChange{
+			Id:        e.Id,
+			TagId:     e.TagId,
+			Action:    e.Action,
+			Actor:     e.Actor,
+			Reason:    e.Reason,
+			CreatedAt: e.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return &types.ResourceTagHistoryResp{
+		Total: total,
+		List:  list,
+	}, nil
+}
@@ -0,0 +1,60 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package tag_management
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"api/internal/svc"
+	"api/internal/types"
+
+	"idrm/pkg/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetResourceTagsAsOfLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 资源在指定时间点的标签
+func NewGetResourceTagsAsOfLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetResourceTagsAsOfLogic {
+	return &GetResourceTagsAsOfLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetResourceTagsAsOf 查询资源在指定时间点关联的标签，名称和颜色为该时间点的取值
+func (l *GetResourceTagsAsOfLogic) GetResourceTagsAsOf(req *types.ResourceTagsAsOfReq) (resp *types.ResourceTagsAsOfResp, err error) {
+	at, err := utils.ParseTime(req.At)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := l.svcCtx.HistoryModel.TagsAsOf(l.ctx, req.ResourceId, req.ResourceType, at)
+	if err != nil {
+		l.Errorf("查询历史时间点标签失败: %v", err)
+		return nil, fmt.Errorf("查询历史时间点标签失败: %w", err)
+	}
+
+	tags := make([]types.TagBrief, 0, len(snapshots))
+	for _, s := range snapshots {
+		tags = append(tags, types.TagBrief{
+			Id:    s.TagId,
+			Name:  s.Name,
+			Color: s.Color,
+		})
+	}
+
+	return &types.ResourceTagsAsOfResp{
+		At:   at.Format(time.RFC3339),
+		Tags: tags,
+	}, nil
+}
@@ -0,0 +1,86 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package tag_management
+
+import (
+	"context"
+	"fmt"
+
+	"api/internal/svc"
+	"api/internal/types"
+
+	"idrm/model/tag_management/resource_tag"
+	"idrm/model/tag_management/tag"
+	"idrm/pkg/errorx"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetResourcesTagsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 批量获取资源标签
+func NewGetResourcesTagsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetResourcesTagsLogic {
+	return &GetResourcesTagsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetResourcesTagsLogic) GetResourcesTags(req *types.GetResourcesTagsReq) (resp *types.GetResourcesTagsResp, err error) {
+	// 1. 参数验证
+	if len(req.ResourceIds) == 0 {
+		return nil, errorx.NewWithMsg(errorx.ErrCodeParamInvalid, "资源ID不能为空")
+	}
+	if len(req.ResourceIds) > resource_tag.MaxBatchResources {
+		return nil, errorx.NewWithMsg(errorx.ErrCodeParamInvalid,
+			fmt.Sprintf("单次最多查询%d个资源", resource_tag.MaxBatchResources))
+	}
+
+	// 2. 去重，保持请求顺序
+	seen := make(map[int64]struct{}, len(req.ResourceIds))
+	resourceIDs := make([]int64, 0, len(req.ResourceIds))
+	for _, id := range req.ResourceIds {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		resourceIDs = append(resourceIDs, id)
+	}
+
+	// 3. 批量查询
+	tagsByResource, err := l.svcCtx.ResourceTagModel.GetTagsForResources(l.ctx, req.ResourceType, resourceIDs)
+	if err != nil {
+		l.Errorf("批量获取资源标签失败: %v", err)
+		return nil, fmt.Errorf("批量获取资源标签失败: %w", err)
+	}
+
+	// 4. 转换为响应格式，只返回启用的标签
+	list := make([]types.ResourceTags, 0, len(resourceIDs))
+	for _, resourceID := range resourceIDs {
+		tags := make([]types.TagBrief, 0, len(tagsByResource[resourceID]))
+		for _, t := range tagsByResource[resourceID] {
+			if t.Status != tag.StatusEnabled {
+				continue
+			}
+			tags = append(tags, types.TagBrief{
+				Id:    t.Id,
+				Name:  t.Name,
+				Color: t.Color,
+			})
+		}
+		list = append(list, types.ResourceTags{
+			ResourceId: resourceID,
+			Tags:       tags,
+		})
+	}
+
+	return &types.GetResourcesTagsResp{
+		List: list,
+	}, nil
+}
@@ -0,0 +1,82 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package tag_management
+
+import (
+	"context"
+	"fmt"
+
+	"api/internal/svc"
+	"api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetTagHistoryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 标签变更历史
+func NewGetTagHistoryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetTagHistoryLogic {
+	return &GetTagHistoryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetTagHistory 查询标签的变更历史，标签删除后历史仍可查询
+func (l *GetTagHistoryLogic) GetTagHistory(req *types.TagHistoryReq) (resp *types.TagHistoryResp, err error) {
+	page, pageSize := normalizePage(req.Page, req.PageSize)
+
+	entries, total, err := l.svcCtx.HistoryModel.ListTagHistory(l.ctx, req.Id, page, pageSize)
+	if err != nil {
+		l.Errorf("查询标签变更历史失败: %v", err)
+		return nil, fmt.Errorf("查询标签变更历史失败: %w", err)
+	}
+
+	list := make([]types.TagChange, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, types.TagChange{
+			Id:          e.Id,
+			Action:      e.Action,
+			Version:     e.Version,
+			Name:        e.Name,
+			Description: e.Description,
+			Color:       e.Color,
+			Status:      e.Status,
+			Actor:       e.Actor,
+			Reason:      e.Reason,
+			CreatedAt:   e.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return &types.TagHistoryResp{
+		Total: total,
+		List:  list,
+	}, nil
+}
+
+const (
+	// defaultHistoryPageSize 变更历史默认每页条数
+	defaultHistoryPageSize = 20
+	// maxHistoryPageSize 变更历史每页最大条数
+	maxHistoryPageSize = 100
+)
+
+// normalizePage 补全默认分页参数，每页条数超过上限时取上限
+func normalizePage(page, pageSize int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultHistoryPageSize
+	}
+	if pageSize > maxHistoryPageSize {
+		pageSize = maxHistoryPageSize
+	}
+	return page, pageSize
+}
@@ -36,7 +36,7 @@ func (l *GetTagLogic) GetTag(id int64) (resp *types.GetTagResp, err error) {
 	result, err := l.svcCtx.TagModel.FindOne(l.ctx, id)
 	if err != nil {
 		if err == tag.ErrNotFound {
-			return nil, errorx.New(errorx.ErrCodeTagNotFound)
+			return nil, errorx.NewWithCode(errorx.ErrCodeTagNotFound)
 		}
 		l.Errorf("查询标签失败: %v", err)
 		return nil, fmt.Errorf("查询标签失败: %w", err)
@@ -54,6 +54,7 @@ func (l *GetTagLogic) GetTag(id int64) (resp *types.GetTagResp, err error) {
 			Status:      result.Status,
 			UsageCount:  usageCount,
 			CreatedAt:   result.CreatedAt.Format("2006-01-02 15:04:05"),
+			Version:     result.Version,
 		},
 	}, nil
 }
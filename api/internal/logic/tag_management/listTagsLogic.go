@@ -10,7 +10,6 @@ import (
 	"api/internal/svc"
 	"api/internal/types"
 
-	"idrm/model/tag_management/resource_tag"
 	"idrm/model/tag_management/tag"
 
 	"github.com/zeromicro/go-zero/core/logx"
@@ -71,6 +70,7 @@ func (l *ListTagsLogic) ListTags(req *types.ListTagsReq) (resp *types.ListTagsRe
 			Status:      t.Status,
 			UsageCount:  usageCount,
 			CreatedAt:   t.CreatedAt.Format("2006-01-02 15:04:05"),
+			Version:     t.Version,
 		})
 	}
 
@@ -65,6 +65,29 @@ func (_m *MockResourceTagModel) GetResourceTags(ctx context.Context, resourceID
 	return r0, r1
 }
 
+// GetTagsForResources provides a mock function with given fields: ctx, resourceType, resourceIDs
+func (_m *MockResourceTagModel) GetTagsForResources(ctx context.Context, resourceType string, resourceIDs []int64) (map[int64][]resource_tag.TagInfo, error) {
+	ret := _m.Called(ctx, resourceType, resourceIDs)
+
+	var r0 map[int64][]resource_tag.TagInfo
+	if rf, ok := ret.Get(0).(func(context.Context, string, []int64) map[int64][]resource_tag.TagInfo); ok {
+		r0 = rf(ctx, resourceType, resourceIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[int64][]resource_tag.TagInfo)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, []int64) error); ok {
+		r1 = rf(ctx, resourceType, resourceIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // BatchAssign provides a mock function with given fields: ctx, resourceID, resourceType, tagIDs
 func (_m *MockResourceTagModel) BatchAssign(ctx context.Context, resourceID int64, resourceType string, tagIDs []int64) error {
 	ret := _m.Called(ctx, resourceID, resourceType, tagIDs)
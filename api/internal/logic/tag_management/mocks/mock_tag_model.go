@@ -171,6 +171,20 @@ func (_m *MockTagModel) Search(ctx context.Context, keyword string, page int, pa
 	return r0, r1, r2
 }
 
+// Patch provides a mock function with given fields: ctx, id, version, fields
+func (_m *MockTagModel) Patch(ctx context.Context, id int64, version int64, fields map[string]interface{}) error {
+	ret := _m.Called(ctx, id, version, fields)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, map[string]interface{}) error); ok {
+		r0 = rf(ctx, id, version, fields)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Trans provides a mock function with given fields: ctx, fn
 func (_m *MockTagModel) Trans(ctx context.Context, fn func(ctx context.Context, model tag.TagModel) error) error {
 	ret := _m.Called(ctx, fn)
@@ -215,6 +229,29 @@ func (_m *MockTagModel) WithTx(tx interface{}) tag.TagModel {
 	return r0
 }
 
+// FindByIds provides a mock function with given fields: ctx, ids
+func (_m *MockTagModel) FindByIds(ctx context.Context, ids []int64) ([]*tag.Tag, error) {
+	ret := _m.Called(ctx, ids)
+
+	var r0 []*tag.Tag
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) []*tag.Tag); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*tag.Tag)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindAll provides a mock function with given fields: ctx
 func (_m *MockTagModel) FindAll(ctx context.Context) ([]*tag.Tag, error) {
 	ret := _m.Called(ctx)
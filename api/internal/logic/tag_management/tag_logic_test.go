@@ -2,6 +2,7 @@ package tag_management
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
 	"time"
@@ -10,8 +11,12 @@ import (
 	"api/internal/svc"
 	"api/internal/types"
 
+	"idrm/model/tag_management/history"
+	"idrm/model/tag_management/resource_tag"
 	"idrm/model/tag_management/tag"
 	"idrm/pkg/errorx"
+	"idrm/pkg/operator"
+	"idrm/pkg/tenant"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -23,14 +28,16 @@ func TestCreateTagLogic_CreateTag_Success(t *testing.T) {
 	mockTagModel := new(mocks.MockTagModel)
 	mockResourceTagModel := new(mocks.MockResourceTagModel)
 
-	ctx := context.Background()
+	ctx := operator.WithOperator(context.Background(), 7)
 
 	// Mock FindByName 返回不存在（名称可用）
 	mockTagModel.On("FindByName", ctx, "测试标签").Return((*tag.Tag)(nil), nil)
 
 	// Mock Insert 返回成功
 	insertedTag := &tag.Tag{Id: 1, Name: "测试标签"}
-	mockTagModel.On("Insert", ctx, mock.AnythingOfType("*tag.Tag")).Return(insertedTag, nil)
+	mockTagModel.On("Insert", ctx, mock.MatchedBy(func(t *tag.Tag) bool {
+		return t.CreatedBy == 7
+	})).Return(insertedTag, nil)
 
 	// 创建Logic
 	svcCtx := &svc.ServiceContext{
@@ -60,7 +67,7 @@ func TestCreateTagLogic_CreateTag_NameExists(t *testing.T) {
 	mockTagModel := new(mocks.MockTagModel)
 	mockResourceTagModel := new(mocks.MockResourceTagModel)
 
-	ctx := context.Background()
+	ctx := operator.WithOperator(context.Background(), 7)
 
 	// Mock FindByName 返回已存在
 	existingTag := &tag.Tag{Id: 1, Name: "已存在标签"}
@@ -90,7 +97,7 @@ func TestCreateTagLogic_CreateTag_ValidationError(t *testing.T) {
 	mockTagModel := new(mocks.MockTagModel)
 	mockResourceTagModel := new(mocks.MockResourceTagModel)
 
-	ctx := context.Background()
+	ctx := operator.WithOperator(context.Background(), 7)
 	svcCtx := &svc.ServiceContext{
 		TagModel:         mockTagModel,
 		ResourceTagModel: mockResourceTagModel,
@@ -235,17 +242,19 @@ func TestUpdateTagLogic_UpdateTag_Success(t *testing.T) {
 	mockTagModel := new(mocks.MockTagModel)
 	mockResourceTagModel := new(mocks.MockResourceTagModel)
 
-	ctx := context.Background()
+	ctx := operator.WithOperator(tenant.WithTenant(context.Background(), "t1"), 7)
 
 	// Mock FindOne 返回现有标签
-	existingTag := &tag.Tag{Id: 1, Name: "旧名称", Description: "旧描述", Color: "#1890ff", Status: 1}
+	existingTag := &tag.Tag{Id: 1, TenantId: "t1", Name: "旧名称", Description: "旧描述", Color: "#1890ff", Status: 1, Version: 3}
 	mockTagModel.On("FindOne", ctx, int64(1)).Return(existingTag, nil)
 
 	// Mock FindByName 新名称不存在
 	mockTagModel.On("FindByName", ctx, "新名称").Return((*tag.Tag)(nil), nil)
 
-	// Mock Update 成功
-	mockTagModel.On("Update", ctx, mock.AnythingOfType("*tag.Tag")).Return(nil)
+	// Mock Update 成功，版本号递增
+	mockTagModel.On("Update", ctx, mock.AnythingOfType("*tag.Tag")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*tag.Tag).Version++
+	})
 
 	svcCtx := &svc.ServiceContext{
 		TagModel:         mockTagModel,
@@ -259,14 +268,145 @@ func TestUpdateTagLogic_UpdateTag_Success(t *testing.T) {
 		Description: "新描述",
 		Color:       "#52c41a",
 		Status:      1,
+		Version:     3,
 	}
 	resp, err := logic.UpdateTag(req)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.True(t, resp.Success)
+	assert.Equal(t, int64(4), resp.Version)
+	assert.Equal(t, int64(7), *existingTag.UpdatedBy)
+
+	mockTagModel.AssertExpectations(t)
+}
+
+// TestUpdateTagLogic_UpdateTag_VersionConflict 测试版本冲突由带版本条件的更新判断
+func TestUpdateTagLogic_UpdateTag_VersionConflict(t *testing.T) {
+	mockTagModel := new(mocks.MockTagModel)
+	mockResourceTagModel := new(mocks.MockResourceTagModel)
+
+	ctx := operator.WithOperator(tenant.WithTenant(context.Background(), "t1"), 7)
+
+	// 缓存中仍是旧版本
+	mockTagModel.On("FindOne", ctx, int64(1)).Return(func(context.Context, int64) *tag.Tag {
+		return &tag.Tag{Id: 1, TenantId: "t1", Name: "标签", Status: 1, Version: 1}
+	}, nil)
+
+	svcCtx := &svc.ServiceContext{
+		TagModel:         mockTagModel,
+		ResourceTagModel: mockResourceTagModel,
+	}
+
+	// 缺少版本号
+	resp, err := NewUpdateTagLogic(ctx, svcCtx).UpdateTag(&types.UpdateTagReq{Id: 1, Name: "标签", Status: 1})
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, errorx.ErrCodeParamMissing, err.(*errorx.CodeError).GetCode())
+
+	// 过期的缓存不会误判冲突，写入时使用客户端带回的版本号
+	mockTagModel.On("Update", ctx, mock.MatchedBy(func(data *tag.Tag) bool { return data.Version == 2 })).Return(nil).Once()
+	resp, err = NewUpdateTagLogic(ctx, svcCtx).UpdateTag(&types.UpdateTagReq{Id: 1, Name: "标签", Status: 1, Version: 2})
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+
+	// 写入时版本已被他人修改
+	mockTagModel.On("Update", ctx, mock.AnythingOfType("*tag.Tag")).Return(tag.ErrVersionConflict).Once()
+	resp, err = NewUpdateTagLogic(ctx, svcCtx).UpdateTag(&types.UpdateTagReq{Id: 1, Name: "标签", Status: 1, Version: 1})
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, errorx.ErrCodeTagVersionConflict, err.(*errorx.CodeError).GetCode())
+
+	// 写入时记录已被他人删除
+	mockTagModel.On("Update", ctx, mock.AnythingOfType("*tag.Tag")).Return(tag.ErrNotFound).Once()
+	resp, err = NewUpdateTagLogic(ctx, svcCtx).UpdateTag(&types.UpdateTagReq{Id: 1, Name: "标签", Status: 1, Version: 2})
+	assert.Nil(t, resp)
+	assert.Equal(t, errorx.ErrCodeTagNotFound, err.(*errorx.CodeError).GetCode())
+	mockTagModel.AssertExpectations(t)
+}
+
+// TestPatchTagLogic_PatchTag_Success 测试部分更新只写入出现的字段
+func TestPatchTagLogic_PatchTag_Success(t *testing.T) {
+	mockTagModel := new(mocks.MockTagModel)
+	mockResourceTagModel := new(mocks.MockResourceTagModel)
+
+	ctx := operator.WithOperator(tenant.WithTenant(context.Background(), "t1"), 7)
+
+	existingTag := &tag.Tag{Id: 1, TenantId: "t1", Name: "标签", Description: "描述", Color: "#52c41a", Status: 1, Version: 2}
+	mockTagModel.On("FindOne", ctx, int64(1)).Return(existingTag, nil)
+	mockTagModel.On("Patch", ctx, int64(1), int64(2), map[string]interface{}{
+		"description": "",
+		"color":       tag.DefaultColor,
+		"status":      0,
+		"updated_by":  int64(7),
+	}).Return(nil)
+
+	svcCtx := &svc.ServiceContext{
+		TagModel:         mockTagModel,
+		ResourceTagModel: mockResourceTagModel,
+	}
+
+	// 未出现 name，不触发必填校验和唯一性检查
+	patch := map[string]json.RawMessage{
+		"description": json.RawMessage(`""`),
+		"color":       json.RawMessage(`null`),
+		"status":      json.RawMessage(`0`),
+	}
+	resp, err := NewPatchTagLogic(ctx, svcCtx).PatchTag(1, 2, patch)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, int64(3), resp.Version)
 
 	mockTagModel.AssertExpectations(t)
+	mockTagModel.AssertNotCalled(t, "FindByName", mock.Anything, mock.Anything)
+}
+
+// TestPatchTagLogic_PatchTag_Invalid 测试部分更新的参数校验
+func TestPatchTagLogic_PatchTag_Invalid(t *testing.T) {
+	mockTagModel := new(mocks.MockTagModel)
+	mockResourceTagModel := new(mocks.MockResourceTagModel)
+
+	ctx := operator.WithOperator(tenant.WithTenant(context.Background(), "t1"), 7)
+
+	mockTagModel.On("FindOne", ctx, int64(1)).Return(&tag.Tag{Id: 1, TenantId: "t1", Name: "标签", Version: 2}, nil)
+
+	svcCtx := &svc.ServiceContext{
+		TagModel:         mockTagModel,
+		ResourceTagModel: mockResourceTagModel,
+	}
+
+	tests := []struct {
+		name    string
+		ifMatch int64
+		patch   string
+		code    int
+	}{
+		{"名称不能清空", 2, `{"name":null}`, errorx.ErrCodeParamInvalid},
+		{"名称过短", 2, `{"name":"a"}`, errorx.ErrCodeParamInvalid},
+		{"未知字段", 2, `{"tenantId":"t2"}`, errorx.ErrCodeParamInvalid},
+		{"类型错误", 2, `{"status":"1"}`, errorx.ErrCodeParamFormat},
+		{"缺少版本号", 0, `{"status":1}`, errorx.ErrCodeParamMissing},
+		{"版本号不一致", 2, `{"status":1,"version":1}`, errorx.ErrCodeParamInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch map[string]json.RawMessage
+			assert.NoError(t, json.Unmarshal([]byte(tt.patch), &patch))
+
+			resp, err := NewPatchTagLogic(ctx, svcCtx).PatchTag(1, tt.ifMatch, patch)
+			assert.Error(t, err)
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.code, err.(*errorx.CodeError).GetCode())
+		})
+	}
+
+	// 认证主体未携带用户时不写入
+	noUser := tenant.WithTenant(context.Background(), "t1")
+	resp, err := NewPatchTagLogic(noUser, svcCtx).PatchTag(1, 2, map[string]json.RawMessage{"status": json.RawMessage(`1`)})
+	assert.Nil(t, resp)
+	assert.Equal(t, errorx.ErrCodeUnauthorized, err.(*errorx.CodeError).GetCode())
+	mockTagModel.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 // TestDeleteTagLogic_DeleteTag_Success 测试删除标签成功
@@ -274,10 +414,10 @@ func TestDeleteTagLogic_DeleteTag_Success(t *testing.T) {
 	mockTagModel := new(mocks.MockTagModel)
 	mockResourceTagModel := new(mocks.MockResourceTagModel)
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 
 	// Mock FindOne 返回存在
-	existingTag := &tag.Tag{Id: 1, Name: "待删除"}
+	existingTag := &tag.Tag{Id: 1, TenantId: "t1", Name: "待删除"}
 	mockTagModel.On("FindOne", ctx, int64(1)).Return(existingTag, nil)
 
 	// Mock CountByTag 返回未使用
@@ -307,9 +447,9 @@ func TestDeleteTagLogic_DeleteTag_InUse(t *testing.T) {
 	mockTagModel := new(mocks.MockTagModel)
 	mockResourceTagModel := new(mocks.MockResourceTagModel)
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 
-	existingTag := &tag.Tag{Id: 1, Name: "使用中"}
+	existingTag := &tag.Tag{Id: 1, TenantId: "t1", Name: "使用中"}
 	mockTagModel.On("FindOne", ctx, int64(1)).Return(existingTag, nil)
 
 	// Mock CountByTag 返回正在使用
@@ -331,6 +471,37 @@ func TestDeleteTagLogic_DeleteTag_InUse(t *testing.T) {
 	mockResourceTagModel.AssertExpectations(t)
 }
 
+// TestUpdateTagLogic_UpdateTag_SharedReadOnly 测试租户不能修改共享标签
+func TestUpdateTagLogic_UpdateTag_SharedReadOnly(t *testing.T) {
+	mockTagModel := new(mocks.MockTagModel)
+	mockResourceTagModel := new(mocks.MockResourceTagModel)
+
+	ctx := operator.WithOperator(tenant.WithTenant(context.Background(), "t1"), 7)
+
+	sharedTag := &tag.Tag{Id: 1, TenantId: tenant.Shared, Name: "共享标签"}
+	mockTagModel.On("FindOne", ctx, int64(1)).Return(sharedTag, nil)
+
+	svcCtx := &svc.ServiceContext{
+		TagModel:         mockTagModel,
+		ResourceTagModel: mockResourceTagModel,
+	}
+
+	resp, err := NewUpdateTagLogic(ctx, svcCtx).UpdateTag(&types.UpdateTagReq{Id: 1, Name: "改名", Status: 1, Version: 1})
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, errorx.ErrCodeTagReadOnly, err.(*errorx.CodeError).GetCode())
+
+	resp2, err := NewDeleteTagLogic(ctx, svcCtx).DeleteTag(1)
+	assert.Error(t, err)
+	assert.Nil(t, resp2)
+	assert.Equal(t, errorx.ErrCodeTagReadOnly, err.(*errorx.CodeError).GetCode())
+
+	// 不应执行任何写操作
+	mockTagModel.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	mockTagModel.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	mockResourceTagModel.AssertNotCalled(t, "CountByTag", mock.Anything, mock.Anything)
+}
+
 // TestAssignTagsLogic_AssignTags_Success 测试关联标签成功
 func TestAssignTagsLogic_AssignTags_Success(t *testing.T) {
 	mockTagModel := new(mocks.MockTagModel)
@@ -410,7 +581,7 @@ func TestUpdateTagLogic_UpdateTag_NotFound(t *testing.T) {
 	mockTagModel := new(mocks.MockTagModel)
 	mockResourceTagModel := new(mocks.MockResourceTagModel)
 
-	ctx := context.Background()
+	ctx := operator.WithOperator(context.Background(), 7)
 
 	mockTagModel.On("FindOne", ctx, int64(999)).Return((*tag.Tag)(nil), tag.ErrNotFound)
 
@@ -421,8 +592,9 @@ func TestUpdateTagLogic_UpdateTag_NotFound(t *testing.T) {
 	logic := NewUpdateTagLogic(ctx, svcCtx)
 
 	req := &types.UpdateTagReq{
-		Id:   999,
-		Name: "新名称",
+		Id:      999,
+		Name:    "新名称",
+		Version: 1,
 	}
 	resp, err := logic.UpdateTag(req)
 
@@ -468,7 +640,7 @@ func TestCreateTagLogic_CreateTag_DBError(t *testing.T) {
 	mockTagModel := new(mocks.MockTagModel)
 	mockResourceTagModel := new(mocks.MockResourceTagModel)
 
-	ctx := context.Background()
+	ctx := operator.WithOperator(context.Background(), 7)
 
 	// Mock FindByName 返回错误
 	mockTagModel.On("FindByName", ctx, "测试标签").Return((*tag.Tag)(nil), errors.New("db error"))
@@ -489,3 +661,107 @@ func TestCreateTagLogic_CreateTag_DBError(t *testing.T) {
 
 	mockTagModel.AssertExpectations(t)
 }
+
+// TestGetResourcesTagsLogic_GetResourcesTags_Success 测试批量获取资源标签
+func TestGetResourcesTagsLogic_GetResourcesTags_Success(t *testing.T) {
+	mockTagModel := new(mocks.MockTagModel)
+	mockResourceTagModel := new(mocks.MockResourceTagModel)
+
+	ctx := context.Background()
+
+	// Mock GetTagsForResources 返回资源100的标签，重复ID已去重
+	mockResourceTagModel.On("GetTagsForResources", ctx, "catalog_category", []int64{100, 200}).Return(
+		map[int64][]resource_tag.TagInfo{
+			100: {
+				{Id: 1, Name: "标签1", Color: "#1890ff", Status: tag.StatusEnabled},
+				{Id: 2, Name: "禁用标签", Color: "#1890ff", Status: tag.StatusDisabled},
+			},
+		}, nil)
+
+	svcCtx := &svc.ServiceContext{
+		TagModel:         mockTagModel,
+		ResourceTagModel: mockResourceTagModel,
+	}
+	logic := NewGetResourcesTagsLogic(ctx, svcCtx)
+
+	req := &types.GetResourcesTagsReq{
+		ResourceType: "catalog_category",
+		ResourceIds:  []int64{100, 200, 100},
+	}
+	resp, err := logic.GetResourcesTags(req)
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.List, 2)
+	assert.Equal(t, int64(100), resp.List[0].ResourceId)
+	assert.Len(t, resp.List[0].Tags, 1)
+	assert.Equal(t, "标签1", resp.List[0].Tags[0].Name)
+	assert.Empty(t, resp.List[1].Tags)
+
+	mockResourceTagModel.AssertExpectations(t)
+}
+
+// TestGetResourcesTagsLogic_GetResourcesTags_TooMany 测试超过批量上限
+func TestGetResourcesTagsLogic_GetResourcesTags_TooMany(t *testing.T) {
+	ctx := context.Background()
+	svcCtx := &svc.ServiceContext{
+		TagModel:         new(mocks.MockTagModel),
+		ResourceTagModel: new(mocks.MockResourceTagModel),
+	}
+	logic := NewGetResourcesTagsLogic(ctx, svcCtx)
+
+	req := &types.GetResourcesTagsReq{
+		ResourceType: "catalog_category",
+		ResourceIds:  make([]int64, resource_tag.MaxBatchResources+1),
+	}
+	resp, err := logic.GetResourcesTags(req)
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, errorx.ErrCodeParamInvalid, err.(*errorx.CodeError).GetCode())
+}
+
+// TestGetResourceTagsAsOfLogic_GetResourceTagsAsOf 测试按时间点查询资源标签
+func TestGetResourceTagsAsOfLogic_GetResourceTagsAsOf(t *testing.T) {
+	mockHistoryModel := new(mocks.MockHistoryModel)
+
+	ctx := tenant.WithTenant(context.Background(), "t1")
+	at := time.Date(2026, 1, 6, 12, 0, 0, 0, time.UTC)
+
+	mockHistoryModel.On("TagsAsOf", ctx, int64(42), "catalog_dataset", mock.MatchedBy(at.Equal)).Return([]*history.TagHistory{
+		{TagId: 1, Name: "财务部", Color: "#1890ff"},
+	}, nil)
+
+	svcCtx := &svc.ServiceContext{HistoryModel: mockHistoryModel}
+	logic := NewGetResourceTagsAsOfLogic(ctx, svcCtx)
+
+	resp, err := logic.GetResourceTagsAsOf(&types.ResourceTagsAsOfReq{
+		ResourceId:   42,
+		ResourceType: "catalog_dataset",
+		At:           "2026-01-06T12:00:00Z",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Tags, 1)
+	assert.Equal(t, "财务部", resp.Tags[0].Name)
+
+	// 时间格式错误
+	resp, err = logic.GetResourceTagsAsOf(&types.ResourceTagsAsOfReq{ResourceId: 42, ResourceType: "catalog_dataset", At: "上周二"})
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, errorx.ErrCodeParamFormat, err.(*errorx.CodeError).GetCode())
+
+	mockHistoryModel.AssertExpectations(t)
+}
+
+// TestGetTagHistoryLogic_GetTagHistory_PageSize 测试变更历史每页条数超过上限时取上限
+func TestGetTagHistoryLogic_GetTagHistory_PageSize(t *testing.T) {
+	mockHistoryModel := new(mocks.MockHistoryModel)
+
+	ctx := tenant.WithTenant(context.Background(), "t1")
+	mockHistoryModel.On("ListTagHistory", ctx, int64(1), 1, maxHistoryPageSize).Return([]*history.TagHistory{}, int64(0), nil)
+
+	svcCtx := &svc.ServiceContext{HistoryModel: mockHistoryModel}
+	_, err := NewGetTagHistoryLogic(ctx, svcCtx).GetTagHistory(&types.TagHistoryReq{Id: 1, PageSize: 100000})
+	assert.NoError(t, err)
+
+	mockHistoryModel.AssertExpectations(t)
+}
@@ -5,6 +5,7 @@ package tag_management
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"api/internal/svc"
@@ -12,6 +13,8 @@ import (
 
 	"idrm/model/tag_management/tag"
 	"idrm/pkg/errorx"
+	"idrm/pkg/operator"
+	"idrm/pkg/tenant"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -32,14 +35,27 @@ func NewUpdateTagLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateT
 }
 
 func (l *UpdateTagLogic) UpdateTag(req *types.UpdateTagReq) (resp *types.UpdateTagResp, err error) {
+	// 版本号用于乐观锁，必须由客户端从详情或列表中带回
+	if req.Version <= 0 {
+		return nil, errorx.NewWithMsg(errorx.ErrCodeParamMissing, "缺少标签版本号")
+	}
+	userID, ok := operator.FromContext(l.ctx)
+	if !ok {
+		return nil, errorx.NewWithMsg(errorx.ErrCodeUnauthorized, "无法识别当前用户")
+	}
+
 	// 1. 验证标签存在
 	existing, err := l.svcCtx.TagModel.FindOne(l.ctx, req.Id)
 	if err != nil {
 		if err == tag.ErrNotFound {
-			return nil, errorx.New(errorx.ErrCodeTagNotFound)
+			return nil, errorx.NewWithCode(errorx.ErrCodeTagNotFound)
 		}
 		return nil, fmt.Errorf("查询标签失败: %w", err)
 	}
+	// 共享标签对租户只读
+	if !tenant.Owns(l.ctx, existing.TenantId) {
+		return nil, errorx.NewWithCode(errorx.ErrCodeTagReadOnly)
+	}
 
 	// 2. 检查名称唯一性（排除自己）
 	if req.Name != existing.Name {
@@ -48,7 +64,7 @@ func (l *UpdateTagLogic) UpdateTag(req *types.UpdateTagReq) (resp *types.UpdateT
 			return nil, fmt.Errorf("检查名称唯一性失败: %w", err)
 		}
 		if duplicate != nil {
-			return nil, errorx.New(errorx.ErrCodeTagAlreadyExists)
+			return nil, errorx.NewWithCode(errorx.ErrCodeTagAlreadyExists)
 		}
 	}
 
@@ -57,15 +73,25 @@ func (l *UpdateTagLogic) UpdateTag(req *types.UpdateTagReq) (resp *types.UpdateT
 	existing.Description = req.Description
 	existing.Color = req.Color
 	existing.Status = req.Status
-	updatedBy := int64(1) // TODO: 从上下文获取用户ID
-	existing.UpdatedBy = &updatedBy
+	existing.UpdatedBy = &userID
+	// 查询结果可能来自缓存或从库，版本号以客户端带回的为准，由带版本条件的 UPDATE 判断冲突
+	existing.Version = req.Version
 
 	if err := l.svcCtx.TagModel.Update(l.ctx, existing); err != nil {
+		// 读取之后被他人修改
+		if errors.Is(err, tag.ErrVersionConflict) {
+			return nil, errorx.NewWithCode(errorx.ErrCodeTagVersionConflict)
+		}
+		// 读取之后被他人删除
+		if errors.Is(err, tag.ErrNotFound) {
+			return nil, errorx.NewWithCode(errorx.ErrCodeTagNotFound)
+		}
 		l.Errorf("更新标签失败: %v", err)
 		return nil, fmt.Errorf("更新标签失败: %w", err)
 	}
 
 	return &types.UpdateTagResp{
 		Success: true,
+		Version: existing.Version,
 	}, nil
 }
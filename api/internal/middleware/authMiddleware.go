@@ -3,20 +3,113 @@
 
 package middleware
 
-import "net/http"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	pkgconfig "idrm/pkg/config"
+	"idrm/pkg/errorx"
+	"idrm/pkg/operator"
+	"idrm/pkg/telemetry/trace"
+	"idrm/pkg/tenant"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// userClaim 认证主体中用户ID对应的 claim
+const userClaim = "userId"
 
 type AuthMiddleware struct {
+	claim         string
+	defaultTenant string
+	roleClaim     string
+	proxies       operator.TrustedProxies
 }
 
-func NewAuthMiddleware() *AuthMiddleware {
-	return &AuthMiddleware{}
+func NewAuthMiddleware(c pkgconfig.TenantConfig, auth pkgconfig.AuthConfig, proxies operator.TrustedProxies) *AuthMiddleware {
+	return &AuthMiddleware{
+		claim:         c.Claim,
+		defaultTenant: c.Default,
+		roleClaim:     auth.RoleClaim,
+		proxies:       proxies,
+	}
 }
 
+// Handle 从认证主体解析租户并写入上下文，后续数据访问自动按租户隔离
+// 同时记录操作人、角色、变更原因、客户端IP和请求路径，供变更历史和审计日志使用
 func (m *AuthMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO generate middleware implement function, delete after code implementation
+		// JWT 校验通过后 go-zero 会将 claims 按名称写入上下文
+		tenantID := claimString(r.Context(), m.claim)
+		if tenantID == "" {
+			tenantID = m.defaultTenant
+		}
+		if err := tenant.Validate(tenantID); err != nil {
+			httpx.ErrorCtx(r.Context(), w, errorx.NewWithMsg(errorx.ErrCodeUnauthorized, "无法识别所属租户"))
+			return
+		}
 
-		// Passthrough to next handler if need
-		next(w, r)
+		ctx := tenant.WithTenant(r.Context(), tenantID)
+		if userID, err := strconv.ParseInt(claimString(ctx, userClaim), 10, 64); err == nil {
+			ctx = operator.WithOperator(ctx, userID)
+		}
+		ctx = operator.WithRoles(ctx, claimRoles(ctx, m.roleClaim))
+		clientIP := m.proxies.ClientIP(r)
+		ctx = operator.WithClientIP(ctx, clientIP)
+		// 链路中的客户端IP以经可信代理解析的结果为准
+		trace.SetAttributes(trace.GetSpan(ctx), attribute.String("http.client_ip", clientIP))
+		ctx = operator.WithRequest(ctx, r.Method, r.URL.Path)
+		if reason := r.Header.Get(operator.ReasonHeader); reason != "" {
+			ctx = operator.WithReason(ctx, operator.DecodeReason(reason))
+		}
+
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// claimString 读取字符串或数字类型的 claim
+func claimString(ctx context.Context, claim string) string {
+	if claim == "" {
+		return ""
+	}
+	switch v := ctx.Value(claim).(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// claimRoles 读取角色 claim，支持字符串数组或逗号分隔的字符串
+func claimRoles(ctx context.Context, claim string) []string {
+	if claim == "" {
+		return nil
+	}
+	var roles []string
+	switch v := ctx.Value(claim).(type) {
+	case []interface{}:
+		for _, item := range v {
+			if role, ok := item.(string); ok && role != "" {
+				roles = append(roles, role)
+			}
+		}
+	case []string:
+		roles = v
+	case string:
+		for _, role := range strings.Split(v, ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				roles = append(roles, role)
+			}
+		}
 	}
+	return roles
 }
@@ -0,0 +1,29 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package middleware
+
+import (
+	"net/http"
+
+	"idrm/pkg/idempotency"
+)
+
+type IdempotencyMiddleware struct {
+	guard *idempotency.Guard
+}
+
+func NewIdempotencyMiddleware(guard *idempotency.Guard) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		guard: guard,
+	}
+}
+
+// Handle POST/PUT/DELETE 请求携带 Idempotency-Key 时保存首次响应并在重试时重放，需在 Auth 之后执行以识别请求主体
+// 未启用时直接执行
+func (m *IdempotencyMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	if m.guard == nil {
+		return next
+	}
+	return m.guard.Handle(next)
+}
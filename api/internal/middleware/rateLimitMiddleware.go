@@ -0,0 +1,25 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package middleware
+
+import (
+	"net/http"
+
+	"idrm/pkg/ratelimit"
+)
+
+type RateLimitMiddleware struct {
+	limiter *ratelimit.Limiter
+}
+
+func NewRateLimitMiddleware(limiter *ratelimit.Limiter) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		limiter: limiter,
+	}
+}
+
+// Handle 按路由类别和用户、租户或客户端IP限流，需在 Auth 之后执行以识别请求主体
+func (m *RateLimitMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return m.limiter.Handle(next)
+}
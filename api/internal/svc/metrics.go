@@ -0,0 +1,44 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"idrm/model/tag_management/resource_tag"
+	"idrm/model/tag_management/tag"
+	"idrm/pkg/telemetry/metrics"
+	"idrm/pkg/tenant"
+)
+
+// collectTagStats 统计所有租户的标签数和各资源类型的关联数，供领域指标定期采集
+func collectTagStats(gormDB *gorm.DB) func(ctx context.Context) (metrics.TagStats, error) {
+	return func(ctx context.Context) (metrics.TagStats, error) {
+		var stats metrics.TagStats
+		db := tenant.AllTenants(gormDB.WithContext(ctx))
+
+		if err := db.Model(&tag.Tag{}).Count(&stats.Total).Error; err != nil {
+			return stats, fmt.Errorf("统计标签总数失败: %w", err)
+		}
+		if err := db.Model(&tag.Tag{}).Where("status = ?", tag.StatusEnabled).Count(&stats.Enabled).Error; err != nil {
+			return stats, fmt.Errorf("统计启用标签数失败: %w", err)
+		}
+
+		var rows []struct {
+			ResourceType string
+			Count        int64
+		}
+		if err := db.Model(&resource_tag.ResourceTag{}).
+			Select("resource_type, COUNT(*) AS count").
+			Group("resource_type").
+			Scan(&rows).Error; err != nil {
+			return stats, fmt.Errorf("统计标签关联数失败: %w", err)
+		}
+		stats.Associations = make(map[string]int64, len(rows))
+		for _, row := range rows {
+			stats.Associations[row.ResourceType] = row.Count
+		}
+
+		return stats, nil
+	}
+}
@@ -6,11 +6,30 @@ package svc
 import (
 	"api/internal/config"
 	"api/internal/middleware"
+	"context"
+	"errors"
 	"fmt"
 	"gorm.io/gorm"
+	"idrm/model/audit/audit_log"
+	"idrm/model/tag_management/history"
 	"idrm/model/tag_management/resource_tag"
 	"idrm/model/tag_management/tag"
+	"idrm/pkg/cache"
+	pkgconfig "idrm/pkg/config"
 	"idrm/pkg/db"
+	"idrm/pkg/health"
+	"idrm/pkg/idempotency"
+	"idrm/pkg/lifecycle"
+	"idrm/pkg/migrate"
+	"idrm/pkg/operator"
+	"idrm/pkg/ratelimit"
+	"idrm/pkg/telemetry"
+	"idrm/pkg/telemetry/audit"
+	_ "idrm/pkg/telemetry/audit/kafka" // 注册审计日志 kafka 存储目标使用的生产者
+	"idrm/pkg/telemetry/metrics"
+	"idrm/pkg/tenant"
+	"os"
+	"time"
 
 	"github.com/zeromicro/go-zero/rest"
 )
@@ -18,49 +37,275 @@ import (
 type ServiceContext struct {
 	Config           config.Config
 	Auth             rest.Middleware
+	RateLimit        rest.Middleware
+	Idempotency      rest.Middleware
+	Limiter          *ratelimit.Limiter
 	DB               *gorm.DB
+	DataSources      *db.Registry
+	Cache            cache.Cache
 	TagModel         tag.TagModel
 	ResourceTagModel resource_tag.ResourceTagModel
+	HistoryModel     history.HistoryModel
+	AuditLogModel    audit_log.AuditLogModel
+	Health           *health.Checker
+
+	// 停止标签领域指标采集
+	stopMetrics func()
+
+	// 幂等键数据库存储，未启用或使用 redis 后端时为 nil
+	idempotencyStore *idempotency.DBStore
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
-	// 初始化数据库连接
-	gormDB, err := initDB(c.Database)
+	// 注册数据源，默认数据源立即连接，其余数据源首次使用时连接
+	dataSources, err := initDataSources(c)
+	if err != nil {
+		panic(fmt.Sprintf("初始化数据源失败: %v", err))
+	}
+	gormDB, err := dataSources.Get(context.Background(), db.DataSourceDefault)
 	if err != nil {
 		panic(fmt.Sprintf("初始化数据库失败: %v", err))
 	}
 
+	// 初始化日志、链路追踪和审计日志，审计日志 db 存储目标写入默认数据源
+	if err := telemetry.Init(c.Observability, audit.WithDB(gormDB)); err != nil {
+		panic(fmt.Sprintf("初始化可观测性组件失败: %v", err))
+	}
+
+	// 按需执行数据库迁移
+	if err := runMigrations(gormDB, c.Migration); err != nil {
+		panic(fmt.Sprintf("执行数据库迁移失败: %v", err))
+	}
+
+	// 按租户隔离的表由插件统一追加租户条件
+	if err := gormDB.Use(tenant.NewPlugin()); err != nil {
+		panic(fmt.Sprintf("注册租户隔离插件失败: %v", err))
+	}
+
+	// 初始化缓存（未配置Redis时不启用）
+	tagCache, err := initCache(c.Redis)
+	if err != nil {
+		panic(fmt.Sprintf("初始化缓存失败: %v", err))
+	}
+
+	// 初始化限流器，未启用时不限流，规则热加载后生效
+	limiter, err := initLimiter(c.RateLimit, tagCache)
+	if err != nil {
+		panic(fmt.Sprintf("初始化限流失败: %v", err))
+	}
+
+	// 初始化幂等键存储，未启用时不做幂等处理
+	guard, idempotencyStore, err := initIdempotency(c.Idempotency, gormDB, tagCache)
+	if err != nil {
+		panic(fmt.Sprintf("初始化幂等键失败: %v", err))
+	}
+
+	// 解析可信反向代理，客户端IP用于审计日志和按IP限流
+	proxies, err := operator.ParseTrustedProxies(c.TrustedProxies)
+	if err != nil {
+		panic(fmt.Sprintf("解析可信代理失败: %v", err))
+	}
+
+	// 资源标签缓存只保存标签ID，标签信息经标签缓存解析；写操作由最外层的审计装饰器记录审计日志
+	tagModel := tag.NewCachedTagModel(gormDB, tagCache)
+
+	// 定期采集标签领域指标（未启用指标时不采集）
+	interval := time.Duration(c.Observability.Metrics.CollectInterval) * time.Second
+	stopMetrics := metrics.StartCollector(interval, collectTagStats(gormDB))
+
 	return &ServiceContext{
 		Config:           c,
-		Auth:             middleware.NewAuthMiddleware().Handle,
+		Auth:             middleware.NewAuthMiddleware(c.Tenant, c.Auth, proxies).Handle,
+		RateLimit:        middleware.NewRateLimitMiddleware(limiter).Handle,
+		Limiter:          limiter,
+		Idempotency:      middleware.NewIdempotencyMiddleware(guard).Handle,
 		DB:               gormDB,
-		TagModel:         tag.NewTagModel(gormDB),
-		ResourceTagModel: resource_tag.NewResourceTagModel(gormDB),
+		DataSources:      dataSources,
+		Cache:            tagCache,
+		TagModel:         tag.NewAuditedTagModel(tag.NewInstrumentedTagModel(tagModel), gormDB),
+		ResourceTagModel: resource_tag.NewAuditedResourceTagModel(resource_tag.NewInstrumentedResourceTagModel(resource_tag.NewCachedResourceTagModel(gormDB, tagCache, resolveTags(tagModel)))),
+		HistoryModel:     history.NewHistoryModel(gormDB),
+		AuditLogModel:    audit_log.NewAuditLogModel(gormDB),
+		Health:           initHealth(c, dataSources, tagCache),
+		stopMetrics:      stopMetrics,
+		idempotencyStore: idempotencyStore,
+	}
+}
+
+// Hooks 返回服务资源的生命周期钩子，资源已在 NewServiceContext 中初始化，只需停止
+// 按依赖顺序排列，停止时逆序执行：先停止指标采集和后台清理，再写完审计日志、关闭数据库，可观测性组件最后关闭以便记录关闭过程
+func (s *ServiceContext) Hooks() []lifecycle.Hook {
+	hooks := []lifecycle.Hook{
+		{
+			Name: "telemetry",
+			Stop: func(ctx context.Context) error {
+				telemetry.Close(ctx)
+				return nil
+			},
+		},
+		{
+			Name: "datasources",
+			Stop: func(ctx context.Context) error {
+				return s.DataSources.Close()
+			},
+		},
+		{
+			// 审计日志的 db 存储目标写入默认数据源，需先于数据库关闭
+			Name: "audit",
+			Stop: func(ctx context.Context) error {
+				audit.Close()
+				return nil
+			},
+		},
+		{
+			Name: "metrics-collector",
+			Stop: func(ctx context.Context) error {
+				s.stopMetrics()
+				return nil
+			},
+		},
+	}
+	if s.idempotencyStore != nil {
+		interval := time.Duration(s.Config.Idempotency.CleanInterval) * time.Second
+		hooks = append(hooks, s.idempotencyStore.Cleaner(interval))
+	}
+	return hooks
+}
+
+// initDataSources 注册所有已配置的数据源
+func initDataSources(c config.Config) (*db.Registry, error) {
+	registry := db.NewRegistry()
+
+	// 默认数据源必须配置
+	if c.Database.Source == "" {
+		return nil, errors.New("未配置数据库连接串 Database.Source")
+	}
+	if err := registry.Register(db.DataSourceDefault, toDBConfig(c.Database)); err != nil {
+		return nil, err
 	}
+
+	// 业务数据源按需配置
+	optional := map[string]pkgconfig.DatabaseConfig{
+		db.DataSourceDataView:          c.DataSources.DataView,
+		db.DataSourceDataUnderstanding: c.DataSources.DataUnderstanding,
+		db.DataSourceResourceCatalog:   c.DataSources.ResourceCatalog,
+	}
+	for name, cfg := range optional {
+		if cfg.Source == "" {
+			continue
+		}
+		if err := registry.Register(name, toDBConfig(cfg)); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
 }
 
-// initDB 初始化数据库连接
-func initDB(cfg config.DatabaseConfig) (*gorm.DB, error) {
-	// 将 DatabaseConfig 转换为 db.Config
-	dbConfig := db.Config{
-		// 默认配置
-		Database:        "idrm",
-		Username:        "root",
-		Password:        "123456",
-		Host:           "127.0.0.1",
-		Port:           3306,
-		Charset:        "utf8mb4",
-		MaxOpenConns:   cfg.MaxOpenConns,
-		MaxIdleConns:   cfg.MaxIdleConns,
+// toDBConfig 将 DatabaseConfig 转换为 db.Config
+func toDBConfig(cfg pkgconfig.DatabaseConfig) db.Config {
+	return db.Config{
+		Driver:          cfg.Driver,
+		Source:          cfg.Source,
+		MaxOpenConns:    cfg.MaxOpenConns,
+		MaxIdleConns:    cfg.MaxIdleConns,
 		ConnMaxLifetime: cfg.ConnMaxLifetime,
 		ConnMaxIdleTime: 600,
-		LogLevel:       "warn",
+		LogLevel:        "warn",
+		SlowThreshold:   cfg.SlowThreshold,
+		Replicas:        cfg.Replicas,
+		ReplicaMaxLag:   cfg.ReplicaMaxLag,
 	}
+}
+
+// runMigrations 启动时执行未执行的迁移（需显式开启 AutoMigrate）
+func runMigrations(gormDB *gorm.DB, cfg pkgconfig.MigrationConfig) error {
+	if !cfg.AutoMigrate {
+		return nil
+	}
+
+	m := migrate.New(gormDB, os.DirFS(migrate.ResolveDir(cfg.Dir)))
+	if cfg.LockTimeout > 0 {
+		m.WithLockTimeout(time.Duration(cfg.LockTimeout) * time.Second)
+	}
+	_, err := m.Up(context.Background(), 0)
+	return err
+}
+
+// initHealth 注册依赖检查：默认数据源为必需检查，业务数据源、Redis、链路导出和审计积压为可选检查
+func initHealth(c config.Config, dataSources *db.Registry, tagCache cache.Cache) *health.Checker {
+	checker := health.New(time.Duration(c.Health.Timeout) * time.Second)
+	checker.Register(health.DataSources(dataSources, db.DataSourceDefault)...)
+	if rc, ok := tagCache.(*cache.RedisCache); ok {
+		checker.Register(health.Redis(rc.Client()))
+	}
+	checker.Register(
+		health.TraceExporter(time.Duration(c.Health.TraceErrorWindow)*time.Second),
+		health.AuditBacklog(c.Health.MaxAuditBacklog),
+	)
+	return checker
+}
+
+// initLimiter 初始化限流器，redis 后端复用缓存的 Redis 连接
+func initLimiter(cfg ratelimit.Config, c cache.Cache) (*ratelimit.Limiter, error) {
+	var store ratelimit.Store = ratelimit.NewMemoryStore(cfg.MaxBuckets)
+	if cfg.Backend == ratelimit.BackendRedis {
+		rc, ok := c.(*cache.RedisCache)
+		if !ok {
+			return nil, errors.New("限流使用 redis 后端时需配置 Redis")
+		}
+		store = ratelimit.NewRedisStore(rc.Client(), cfg.Prefix)
+	}
+	return ratelimit.New(cfg, store)
+}
+
+// initIdempotency 初始化幂等键存储，db 后端写入默认数据源，redis 后端复用缓存的 Redis 连接
+func initIdempotency(cfg idempotency.Config, gormDB *gorm.DB, c cache.Cache) (*idempotency.Guard, *idempotency.DBStore, error) {
+	if !cfg.Enabled {
+		return nil, nil, nil
+	}
+
+	if cfg.Backend == idempotency.BackendRedis {
+		rc, ok := c.(*cache.RedisCache)
+		if !ok {
+			return nil, nil, errors.New("幂等键使用 redis 后端时需配置 Redis")
+		}
+		return idempotency.NewGuard(cfg, idempotency.NewRedisStore(rc.Client(), cfg.Prefix)), nil, nil
+	}
+
+	store := idempotency.NewDBStore(gormDB)
+	return idempotency.NewGuard(cfg, store), store, nil
+}
+
+// resolveTags 按ID批量解析资源上的标签信息
+func resolveTags(model tag.TagModel) resource_tag.TagResolver {
+	return func(ctx context.Context, tagIDs []int64) (map[int64]resource_tag.TagInfo, error) {
+		tags, err := model.FindByIds(ctx, tagIDs)
+		if err != nil {
+			return nil, err
+		}
+		infos := make(map[int64]resource_tag.TagInfo, len(tags))
+		for _, t := range tags {
+			infos[t.Id] = resource_tag.TagInfo{
+				Id:     t.Id,
+				Name:   t.Name,
+				Color:  t.Color,
+				Status: t.Status,
+			}
+		}
+		return infos, nil
+	}
+}
 
-	// 如果 Source 不为空，解析连接字符串
-	if cfg.Source != "" {
-		dbConfig.Source = cfg.Source
+// initCache 初始化Redis缓存
+func initCache(cfg pkgconfig.RedisConfig) (cache.Cache, error) {
+	if cfg.Host == "" {
+		return nil, nil
 	}
 
-	return db.InitGorm(dbConfig)
+	return cache.NewRedisCache(cache.Config{
+		Host: cfg.Host,
+		Type: cfg.Type,
+		Pass: cfg.Pass,
+	})
 }
@@ -14,6 +14,44 @@ type AssignTagsResp struct {
 	AssignedCount int  `json:"assignedCount"`
 }
 
+type AuditChange struct {
+	Path   string      `json:"path"`
+	Op     string      `json:"op"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+type AuditLogInfo struct {
+	Id          int64  `json:"id"`
+	EventId     string `json:"eventId"`
+	OccurredAt  string `json:"occurredAt"`
+	ServiceName string `json:"serviceName"`
+	TenantId    string `json:"tenantId"`
+	Action      string `json:"action"`
+	Resource    string `json:"resource"`
+	UserId      string `json:"userId"`
+	Username    string `json:"username"`
+	Ip          string `json:"ip"`
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	TraceId     string `json:"traceId"`
+	Success     bool   `json:"success"`
+	ErrorMsg    string `json:"errorMsg"`
+	Duration    int64  `json:"duration"`
+}
+
+type AuditLogReq struct {
+	Id int64 `path:"id" validate:"required"`
+}
+
+type AuditLogResp struct {
+	AuditLogInfo
+	Before  interface{}            `json:"before"`
+	After   interface{}            `json:"after"`
+	Extra   map[string]interface{} `json:"extra"`
+	Changes []AuditChange          `json:"changes"`
+}
+
 type CreateTagReq struct {
 	Name        string `json:"name" validate:"required,min=2,max=50"`
 	Description string `json:"description" validate:"max=200"`
@@ -32,10 +70,48 @@ type DeleteTagResp struct {
 	Success bool `json:"success"`
 }
 
+type ExportAuditLogsReq struct {
+	Action   string `form:"action,optional"`
+	Resource string `form:"resource,optional"`
+	UserId   string `form:"userId,optional"`
+	TraceId  string `form:"traceId,optional"`
+	Success  *bool  `form:"success,optional"`
+	From     string `form:"from,optional"`
+	To       string `form:"to,optional"`
+	Format   string `form:"format,default=csv,options=csv|json"`
+}
+
+type GetResourcesTagsReq struct {
+	ResourceType string  `form:"resourceType" validate:"required"`
+	ResourceIds  []int64 `form:"resourceIds" validate:"required,min=1,max=100"`
+}
+
+type GetResourcesTagsResp struct {
+	List []ResourceTags `json:"list"`
+}
+
 type GetTagResp struct {
 	TagInfo
 }
 
+type ListAuditLogsReq struct {
+	Action   string `form:"action,optional"`
+	Resource string `form:"resource,optional"`
+	UserId   string `form:"userId,optional"`
+	TraceId  string `form:"traceId,optional"`
+	Success  *bool  `form:"success,optional"`
+	From     string `form:"from,optional"`
+	To       string `form:"to,optional"`
+	Cursor   string `form:"cursor,optional"`
+	Limit    int    `form:"limit,default=20" validate:"min=1,max=100"`
+}
+
+type ListAuditLogsResp struct {
+	List       []AuditLogInfo `json:"list"`
+	NextCursor string         `json:"nextCursor"`
+	HasMore    bool           `json:"hasMore"`
+}
+
 type ListTagsReq struct {
 	Page     int    `form:"page,default=1" validate:"min=1"`
 	PageSize int    `form:"pageSize,default=20" validate:"min=1,max=100"`
@@ -48,12 +124,58 @@ type ListTagsResp struct {
 	List  []TagInfo `json:"list"`
 }
 
+type PatchTagReq struct {
+	Id int64 `path:"id" validate:"required"`
+}
+
+type PatchTagResp struct {
+	Success bool  `json:"success"`
+	Version int64 `json:"version"`
+}
+
 type ResourceInfo struct {
 	Id   int64  `json:"id"`
 	Name string `json:"name"`
 	Type string `json:"type"`
 }
 
+type ResourceTagChange struct {
+	Id        int64  `json:"id"`
+	TagId     int64  `json:"tagId"`
+	Action    string `json:"action"`
+	Actor     int64  `json:"actor"`
+	Reason    string `json:"reason"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type ResourceTagHistoryReq struct {
+	ResourceId   int64  `form:"resourceId" validate:"required"`
+	ResourceType string `form:"resourceType" validate:"required"`
+	Page         int    `form:"page,default=1" validate:"min=1"`
+	PageSize     int    `form:"pageSize,default=20" validate:"min=1,max=100"`
+}
+
+type ResourceTagHistoryResp struct {
+	Total int64               `json:"total"`
+	List  []ResourceTagChange `json:"list"`
+}
+
+type ResourceTags struct {
+	ResourceId int64      `json:"resourceId"`
+	Tags       []TagBrief `json:"tags"`
+}
+
+type ResourceTagsAsOfReq struct {
+	ResourceId   int64  `form:"resourceId" validate:"required"`
+	ResourceType string `form:"resourceType" validate:"required"`
+	At           string `form:"at" validate:"required"`
+}
+
+type ResourceTagsAsOfResp struct {
+	At   string     `json:"at"`
+	Tags []TagBrief `json:"tags"`
+}
+
 type SearchByTagsReq struct {
 	TagIds       []int64 `form:"tagIds" validate:"required,min=1"`
 	ResourceType string  `form:"resourceType" validate:"required"`
@@ -66,6 +188,36 @@ type SearchByTagsResp struct {
 	Resources []ResourceInfo `json:"resources"`
 }
 
+type TagBrief struct {
+	Id    int64  `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type TagChange struct {
+	Id          int64  `json:"id"`
+	Action      string `json:"action"`
+	Version     int64  `json:"version"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Color       string `json:"color"`
+	Status      int    `json:"status"`
+	Actor       int64  `json:"actor"`
+	Reason      string `json:"reason"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+type TagHistoryReq struct {
+	Id       int64 `path:"id" validate:"required"`
+	Page     int   `form:"page,default=1" validate:"min=1"`
+	PageSize int   `form:"pageSize,default=20" validate:"min=1,max=100"`
+}
+
+type TagHistoryResp struct {
+	Total int64       `json:"total"`
+	List  []TagChange `json:"list"`
+}
+
 type TagInfo struct {
 	Id          int64  `json:"id"`
 	Name        string `json:"name"`
@@ -74,6 +226,7 @@ type TagInfo struct {
 	Status      int    `json:"status"`
 	UsageCount  int64  `json:"usageCount"`
 	CreatedAt   string `json:"createdAt"`
+	Version     int64  `json:"version"`
 }
 
 type UnassignTagsReq struct {
@@ -92,8 +245,10 @@ type UpdateTagReq struct {
 	Description string `json:"description" validate:"max=200"`
 	Color       string `json:"color" validate:"omitempty,hexcolor,len=7"`
 	Status      int    `json:"status" validate:"oneof=0 1"`
+	Version     int64  `json:"version,optional"` // 也可通过 If-Match 头传递
 }
 
 type UpdateTagResp struct {
-	Success bool `json:"success"`
+	Success bool  `json:"success"`
+	Version int64 `json:"version"`
 }
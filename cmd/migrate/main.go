@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/conf"
+
+	"idrm/pkg/config"
+	"idrm/pkg/db"
+	"idrm/pkg/migrate"
+)
+
+// Config 迁移命令配置，可直接复用 API 服务的配置文件
+type Config struct {
+	Database  config.DatabaseConfig
+	Migration config.MigrationConfig `json:",optional"`
+}
+
+var (
+	configFile = flag.String("f", "api/etc/api.yaml", "the config file")
+	dir        = flag.String("dir", "", "the migrations directory (overrides Migration.Dir)")
+	strict     = flag.Bool("strict", false, "drift: also fail on indexes not declared in models")
+)
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage: migrate [flags] <command> [arg]
+
+Commands:
+  up [n]     apply all (or the next n) pending migrations
+  down [n]   revert the last n applied migrations (default 1)
+  status     show the status of all migrations
+  baseline v mark migrations up to version v as applied without running them,
+             for databases created before the migration runner was adopted
+  drift      compare the live schema with model struct tags and migration files
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), flag.Arg(1)); err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(command, arg string) error {
+	var c Config
+	if err := conf.Load(*configFile, &c); err != nil {
+		return err
+	}
+	if *dir != "" {
+		c.Migration.Dir = *dir
+	}
+
+	steps := 0
+	if arg != "" && command != "baseline" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid step count: %s", arg)
+		}
+		steps = n
+	}
+	var version int64
+	if command == "baseline" {
+		v, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || v <= 0 {
+			return fmt.Errorf("invalid baseline version: %q", arg)
+		}
+		version = v
+	}
+
+	gormDB, err := db.InitGorm(db.Config{
+		Driver:          c.Database.Driver,
+		Source:          c.Database.Source,
+		MaxOpenConns:    2,
+		MaxIdleConns:    1,
+		ConnMaxLifetime: c.Database.ConnMaxLifetime,
+		LogLevel:        "warn",
+	})
+	if err != nil {
+		return err
+	}
+	defer db.Close(gormDB)
+
+	m := migrate.New(gormDB, os.DirFS(migrate.ResolveDir(c.Migration.Dir)))
+	if c.Migration.LockTimeout > 0 {
+		m.WithLockTimeout(time.Duration(c.Migration.LockTimeout) * time.Second)
+	}
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		applied, err := m.Up(ctx, steps)
+		for _, mg := range applied {
+			fmt.Printf("applied  %d_%s\n", mg.Version, mg.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+	case "down":
+		reverted, err := m.Down(ctx, steps)
+		for _, mg := range reverted {
+			fmt.Printf("reverted %d_%s\n", mg.Version, mg.Name)
+		}
+		return err
+	case "baseline":
+		marked, err := m.Baseline(ctx, version)
+		for _, mg := range marked {
+			fmt.Printf("baselined %d_%s\n", mg.Version, mg.Name)
+		}
+		if err == nil && len(marked) == 0 {
+			fmt.Println("nothing to baseline")
+		}
+		return err
+	case "status":
+		return printStatus(ctx, m)
+	case "drift":
+		return printDrift(ctx, m)
+	default:
+		usage()
+		return fmt.Errorf("unknown command: %s", command)
+	}
+}
+
+func printStatus(ctx context.Context, m *migrate.Migrator) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, appliedAt := "pending", ""
+		if s.Applied {
+			state = "applied"
+			appliedAt = s.AppliedAt.Format(time.DateTime)
+		}
+		switch {
+		case s.Missing:
+			state += " (file missing)"
+		case s.Modified:
+			state += " (modified)"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
+
+func printDrift(ctx context.Context, m *migrate.Migrator) error {
+	drifts, err := m.CheckDrift(ctx, models...)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, d := range drifts {
+		fmt.Println(d)
+		if *strict || d.Kind != migrate.DriftUndeclaredIndex {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d schema drift(s) found", failed)
+	}
+	fmt.Println("no schema drift")
+	return nil
+}
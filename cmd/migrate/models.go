@@ -0,0 +1,19 @@
+package main
+
+import (
+	"idrm/model/tag_management/history"
+	"idrm/model/tag_management/resource_tag"
+	"idrm/model/tag_management/tag"
+	"idrm/pkg/idempotency"
+	"idrm/pkg/telemetry/audit"
+)
+
+// models 参与结构漂移检测的 GORM 模型，新增模型时需在此登记
+var models = []interface{}{
+	&tag.Tag{},
+	&resource_tag.ResourceTag{},
+	&history.TagHistory{},
+	&history.ResourceTagHistory{},
+	&audit.AuditRecord{},
+	&idempotency.Record{},
+}
@@ -0,0 +1,7 @@
+-- ============================================
+-- Feature: Audit Logging
+-- Module: audit
+-- Description: 回滚审计日志表
+-- ============================================
+
+DROP TABLE audit_logs;
@@ -0,0 +1,34 @@
+-- ============================================
+-- Feature: Audit Logging
+-- Module: audit
+-- Description: 审计日志表，审计日志 db 存储目标写入此表，按事件ID去重
+-- Created: 2026-01-10
+-- ============================================
+
+CREATE TABLE `audit_logs` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+    `event_id` VARCHAR(36) NOT NULL COMMENT '事件ID，重复投递时去重',
+    `occurred_at` DATETIME(3) NOT NULL COMMENT '操作时间',
+    `service_name` VARCHAR(64) NOT NULL COMMENT '服务名称',
+    `tenant_id` VARCHAR(64) DEFAULT NULL COMMENT '租户ID',
+    `action` VARCHAR(20) NOT NULL COMMENT '操作类型',
+    `resource` VARCHAR(64) NOT NULL COMMENT '资源类型',
+    `user_id` VARCHAR(64) DEFAULT NULL COMMENT '操作人ID',
+    `username` VARCHAR(128) DEFAULT NULL COMMENT '操作人名称',
+    `ip` VARCHAR(64) DEFAULT NULL COMMENT '客户端IP',
+    `method` VARCHAR(16) DEFAULT NULL COMMENT 'HTTP 方法',
+    `path` VARCHAR(255) DEFAULT NULL COMMENT '请求路径',
+    `trace_id` VARCHAR(64) DEFAULT NULL COMMENT '链路ID',
+    `before_data` TEXT DEFAULT NULL COMMENT '操作前快照(JSON)',
+    `after_data` TEXT DEFAULT NULL COMMENT '操作后快照(JSON)',
+    `success` BOOLEAN NOT NULL COMMENT '是否成功',
+    `error_msg` TEXT DEFAULT NULL COMMENT '错误信息',
+    `duration` BIGINT NOT NULL DEFAULT 0 COMMENT '执行时长(ms)',
+    `extra` TEXT DEFAULT NULL COMMENT '扩展字段(JSON)',
+    `created_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) COMMENT '入库时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_audit_logs_event_id` (`event_id`),
+    KEY `idx_audit_logs_occurred_at` (`occurred_at`),
+    KEY `idx_audit_logs_resource` (`tenant_id`, `resource`),
+    KEY `idx_audit_logs_user` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='审计日志表';
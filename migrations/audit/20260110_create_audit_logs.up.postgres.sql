@@ -0,0 +1,34 @@
+-- ============================================
+-- Feature: Audit Logging
+-- Module: audit
+-- Description: 审计日志表 (PostgreSQL)
+-- Created: 2026-01-10
+-- ============================================
+
+CREATE TABLE audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(36) NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    service_name VARCHAR(64) NOT NULL,
+    tenant_id VARCHAR(64) DEFAULT NULL,
+    action VARCHAR(20) NOT NULL,
+    resource VARCHAR(64) NOT NULL,
+    user_id VARCHAR(64) DEFAULT NULL,
+    username VARCHAR(128) DEFAULT NULL,
+    ip VARCHAR(64) DEFAULT NULL,
+    method VARCHAR(16) DEFAULT NULL,
+    path VARCHAR(255) DEFAULT NULL,
+    trace_id VARCHAR(64) DEFAULT NULL,
+    before_data TEXT DEFAULT NULL,
+    after_data TEXT DEFAULT NULL,
+    success BOOLEAN NOT NULL,
+    error_msg TEXT DEFAULT NULL,
+    duration BIGINT NOT NULL DEFAULT 0,
+    extra TEXT DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX uk_audit_logs_event_id ON audit_logs (event_id);
+CREATE INDEX idx_audit_logs_occurred_at ON audit_logs (occurred_at);
+CREATE INDEX idx_audit_logs_resource ON audit_logs (tenant_id, resource);
+CREATE INDEX idx_audit_logs_user ON audit_logs (user_id);
+COMMENT ON TABLE audit_logs IS '审计日志表';
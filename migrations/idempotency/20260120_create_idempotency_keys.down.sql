@@ -0,0 +1,7 @@
+-- ============================================
+-- Feature: Idempotency Keys
+-- Module: idempotency
+-- Description: 回滚幂等键表
+-- ============================================
+
+DROP TABLE idempotency_keys;
@@ -0,0 +1,18 @@
+-- ============================================
+-- Feature: Idempotency Keys
+-- Module: idempotency
+-- Description: 幂等键表，保存写接口首次请求的响应，重试时重放
+-- Created: 2026-01-20
+-- ============================================
+
+CREATE TABLE `idempotency_keys` (
+    `key_hash` VARCHAR(64) NOT NULL COMMENT '租户、主体和幂等键的摘要',
+    `request_hash` VARCHAR(64) NOT NULL COMMENT '请求方法、路径和请求体的摘要',
+    `status` INT NOT NULL DEFAULT 0 COMMENT '响应状态码，0表示处理中',
+    `header` TEXT DEFAULT NULL COMMENT '响应头(JSON)',
+    `body` MEDIUMTEXT DEFAULT NULL COMMENT '响应体',
+    `expires_at` DATETIME(3) NOT NULL COMMENT '过期时间',
+    `created_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) COMMENT '创建时间',
+    PRIMARY KEY (`key_hash`),
+    KEY `idx_idempotency_keys_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='幂等键表';
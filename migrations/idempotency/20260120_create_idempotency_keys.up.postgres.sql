@@ -0,0 +1,18 @@
+-- ============================================
+-- Feature: Idempotency Keys
+-- Module: idempotency
+-- Description: 幂等键表 (PostgreSQL)
+-- Created: 2026-01-20
+-- ============================================
+
+CREATE TABLE idempotency_keys (
+    key_hash VARCHAR(64) PRIMARY KEY,
+    request_hash VARCHAR(64) NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    header TEXT DEFAULT NULL,
+    body TEXT DEFAULT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+COMMENT ON TABLE idempotency_keys IS '幂等键表';
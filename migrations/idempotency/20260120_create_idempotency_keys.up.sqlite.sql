@@ -0,0 +1,17 @@
+-- ============================================
+-- Feature: Idempotency Keys
+-- Module: idempotency
+-- Description: 幂等键表 (SQLite)
+-- Created: 2026-01-20
+-- ============================================
+
+CREATE TABLE idempotency_keys (
+    key_hash VARCHAR(64) NOT NULL PRIMARY KEY,
+    request_hash VARCHAR(64) NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    header TEXT DEFAULT NULL,
+    body TEXT DEFAULT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
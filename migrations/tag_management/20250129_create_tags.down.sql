@@ -0,0 +1,8 @@
+-- ============================================
+-- Feature: Data Tag Management
+-- Module: tag_management
+-- Description: 回滚数据标签管理表
+-- ============================================
+
+DROP TABLE IF EXISTS resource_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,36 @@
+-- ============================================
+-- Feature: Data Tag Management
+-- Module: tag_management
+-- Description: 数据标签管理功能 (PostgreSQL)
+-- Created: 2025-12-29
+-- ============================================
+
+-- 标签表
+CREATE TABLE tags (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    description VARCHAR(200) DEFAULT NULL,
+    color VARCHAR(7) DEFAULT '#1890ff',
+    status SMALLINT NOT NULL DEFAULT 1,
+    created_by BIGINT NOT NULL,
+    updated_by BIGINT DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uk_name UNIQUE (name)
+);
+CREATE INDEX idx_status ON tags (status);
+CREATE INDEX idx_created_at ON tags (created_at);
+COMMENT ON TABLE tags IS '数据标签表';
+
+-- 资源标签关联表
+CREATE TABLE resource_tags (
+    id BIGSERIAL PRIMARY KEY,
+    resource_id BIGINT NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    tag_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uk_resource_tag UNIQUE (resource_id, resource_type, tag_id)
+);
+CREATE INDEX idx_tag_id ON resource_tags (tag_id);
+CREATE INDEX idx_resource ON resource_tags (resource_id, resource_type);
+COMMENT ON TABLE resource_tags IS '资源标签关联表';
@@ -0,0 +1,34 @@
+-- ============================================
+-- Feature: Data Tag Management
+-- Module: tag_management
+-- Description: 数据标签管理功能 (SQLite，用于本地开发和测试)
+-- Created: 2025-12-29
+-- ============================================
+
+-- 标签表
+CREATE TABLE tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(50) NOT NULL,
+    description VARCHAR(200) DEFAULT NULL,
+    color VARCHAR(7) DEFAULT '#1890ff',
+    status TINYINT NOT NULL DEFAULT 1,
+    created_by INTEGER NOT NULL,
+    updated_by INTEGER DEFAULT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX uk_name ON tags (name);
+CREATE INDEX idx_status ON tags (status);
+CREATE INDEX idx_created_at ON tags (created_at);
+
+-- 资源标签关联表
+CREATE TABLE resource_tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    resource_id INTEGER NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    tag_id INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX uk_resource_tag ON resource_tags (resource_id, resource_type, tag_id);
+CREATE INDEX idx_tag_id ON resource_tags (tag_id);
+CREATE INDEX idx_resource ON resource_tags (resource_id, resource_type);
@@ -0,0 +1,17 @@
+-- ============================================
+-- Feature: Data Tag Management
+-- Module: tag_management
+-- Description: 回滚租户维度（不同租户存在同名标签时会失败）
+-- ============================================
+
+ALTER TABLE `resource_tags`
+    DROP INDEX `idx_resource`,
+    ADD KEY `idx_resource` (`resource_id`, `resource_type`),
+    DROP INDEX `uk_resource_tag`,
+    ADD UNIQUE KEY `uk_resource_tag` (`resource_id`, `resource_type`, `tag_id`),
+    DROP COLUMN `tenant_id`;
+
+ALTER TABLE `tags`
+    DROP INDEX `uk_tenant_name`,
+    ADD UNIQUE KEY `uk_name` (`name`),
+    DROP COLUMN `tenant_id`;
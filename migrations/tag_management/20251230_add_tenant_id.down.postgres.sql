@@ -0,0 +1,15 @@
+-- ============================================
+-- Feature: Data Tag Management
+-- Module: tag_management
+-- Description: 回滚租户维度 (PostgreSQL，不同租户存在同名标签时会失败)
+-- ============================================
+
+DROP INDEX idx_resource;
+ALTER TABLE resource_tags DROP CONSTRAINT uk_resource_tag;
+ALTER TABLE resource_tags DROP COLUMN tenant_id;
+ALTER TABLE resource_tags ADD CONSTRAINT uk_resource_tag UNIQUE (resource_id, resource_type, tag_id);
+CREATE INDEX idx_resource ON resource_tags (resource_id, resource_type);
+
+ALTER TABLE tags DROP CONSTRAINT uk_tenant_name;
+ALTER TABLE tags DROP COLUMN tenant_id;
+ALTER TABLE tags ADD CONSTRAINT uk_name UNIQUE (name);
@@ -0,0 +1,15 @@
+-- ============================================
+-- Feature: Data Tag Management
+-- Module: tag_management
+-- Description: 回滚租户维度 (SQLite，不同租户存在同名标签时会失败)
+-- ============================================
+
+DROP INDEX idx_resource;
+DROP INDEX uk_resource_tag;
+ALTER TABLE resource_tags DROP COLUMN tenant_id;
+CREATE UNIQUE INDEX uk_resource_tag ON resource_tags (resource_id, resource_type, tag_id);
+CREATE INDEX idx_resource ON resource_tags (resource_id, resource_type);
+
+DROP INDEX uk_tenant_name;
+ALTER TABLE tags DROP COLUMN tenant_id;
+CREATE UNIQUE INDEX uk_name ON tags (name);
@@ -0,0 +1,21 @@
+-- ============================================
+-- Feature: Data Tag Management
+-- Module: tag_management
+-- Description: 标签及关联增加租户维度，唯一约束按租户隔离
+--              已有数据归属 default 租户，tenant_id = '*' 表示共享标签
+-- Created: 2025-12-30
+-- ============================================
+
+ALTER TABLE `tags`
+    ADD COLUMN `tenant_id` VARCHAR(64) NOT NULL DEFAULT 'default' COMMENT '租户ID，*表示共享' AFTER `id`,
+    DROP INDEX `uk_name`,
+    ADD UNIQUE KEY `uk_tenant_name` (`tenant_id`, `name`);
+ALTER TABLE `tags` ALTER COLUMN `tenant_id` DROP DEFAULT;
+
+ALTER TABLE `resource_tags`
+    ADD COLUMN `tenant_id` VARCHAR(64) NOT NULL DEFAULT 'default' COMMENT '租户ID' AFTER `id`,
+    DROP INDEX `uk_resource_tag`,
+    ADD UNIQUE KEY `uk_resource_tag` (`tenant_id`, `resource_id`, `resource_type`, `tag_id`),
+    DROP INDEX `idx_resource`,
+    ADD KEY `idx_resource` (`tenant_id`, `resource_id`, `resource_type`);
+ALTER TABLE `resource_tags` ALTER COLUMN `tenant_id` DROP DEFAULT;
@@ -0,0 +1,20 @@
+-- ============================================
+-- Feature: Data Tag Management
+-- Module: tag_management
+-- Description: 标签及关联增加租户维度 (PostgreSQL)
+--              已有数据归属 default 租户，tenant_id = '*' 表示共享标签
+-- Created: 2025-12-30
+-- ============================================
+
+ALTER TABLE tags ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE tags ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE tags DROP CONSTRAINT uk_name;
+ALTER TABLE tags ADD CONSTRAINT uk_tenant_name UNIQUE (tenant_id, name);
+COMMENT ON COLUMN tags.tenant_id IS '租户ID，*表示共享';
+
+ALTER TABLE resource_tags ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE resource_tags ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE resource_tags DROP CONSTRAINT uk_resource_tag;
+ALTER TABLE resource_tags ADD CONSTRAINT uk_resource_tag UNIQUE (tenant_id, resource_id, resource_type, tag_id);
+DROP INDEX idx_resource;
+CREATE INDEX idx_resource ON resource_tags (tenant_id, resource_id, resource_type);
@@ -0,0 +1,17 @@
+-- ============================================
+-- Feature: Data Tag Management
+-- Module: tag_management
+-- Description: 标签及关联增加租户维度 (SQLite)
+--              已有数据归属 default 租户，tenant_id = '*' 表示共享标签
+-- Created: 2025-12-30
+-- ============================================
+
+ALTER TABLE tags ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+DROP INDEX uk_name;
+CREATE UNIQUE INDEX uk_tenant_name ON tags (tenant_id, name);
+
+ALTER TABLE resource_tags ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+DROP INDEX uk_resource_tag;
+CREATE UNIQUE INDEX uk_resource_tag ON resource_tags (tenant_id, resource_id, resource_type, tag_id);
+DROP INDEX idx_resource;
+CREATE INDEX idx_resource ON resource_tags (tenant_id, resource_id, resource_type);
@@ -0,0 +1,7 @@
+-- ============================================
+-- Feature: Data Tag Management
+-- Module: tag_management
+-- Description: 回滚标签版本号
+-- ============================================
+
+ALTER TABLE tags DROP COLUMN version;
@@ -0,0 +1,8 @@
+-- ============================================
+-- Feature: Data Tag Management
+-- Module: tag_management
+-- Description: 标签增加版本号，用于更新时的乐观锁校验 (PostgreSQL/SQLite)
+-- Created: 2025-12-31
+-- ============================================
+
+ALTER TABLE tags ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,8 @@
+-- ============================================
+-- Feature: Data Tag Management
+-- Module: tag_management
+-- Description: 回滚标签及关联变更历史
+-- ============================================
+
+DROP TABLE resource_tag_history;
+DROP TABLE tag_history;
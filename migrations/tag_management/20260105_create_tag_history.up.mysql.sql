@@ -0,0 +1,50 @@
+-- ============================================
+-- Feature: Data Tag Management
+-- Module: tag_management
+-- Description: 标签及关联变更历史，支持按时间点查询资源标签
+--              已有数据按创建时间补录一条 create/assign 记录作为历史起点
+-- Created: 2026-01-05
+-- ============================================
+
+-- 标签变更历史表
+CREATE TABLE `tag_history` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '历史ID',
+    `tenant_id` VARCHAR(64) NOT NULL COMMENT '租户ID，*表示共享',
+    `tag_id` BIGINT UNSIGNED NOT NULL COMMENT '标签ID',
+    `action` VARCHAR(20) NOT NULL COMMENT '变更类型：create/update/status/delete',
+    `version` BIGINT NOT NULL COMMENT '变更后的版本号',
+    `name` VARCHAR(50) NOT NULL COMMENT '标签名称快照',
+    `description` VARCHAR(200) DEFAULT NULL COMMENT '标签描述快照',
+    `color` VARCHAR(7) DEFAULT NULL COMMENT '标签颜色快照',
+    `status` TINYINT NOT NULL COMMENT '状态快照',
+    `actor` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '操作人ID，0表示未知',
+    `reason` VARCHAR(255) DEFAULT NULL COMMENT '变更原因',
+    `created_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) COMMENT '变更时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_tag_history_tag` (`tenant_id`, `tag_id`),
+    KEY `idx_tag_history_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='标签变更历史表';
+
+-- 资源标签关联变更历史表
+CREATE TABLE `resource_tag_history` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '历史ID',
+    `tenant_id` VARCHAR(64) NOT NULL COMMENT '租户ID',
+    `resource_id` BIGINT UNSIGNED NOT NULL COMMENT '资源ID',
+    `resource_type` VARCHAR(50) NOT NULL COMMENT '资源类型',
+    `tag_id` BIGINT UNSIGNED NOT NULL COMMENT '标签ID',
+    `action` VARCHAR(20) NOT NULL COMMENT '变更类型：assign/unassign',
+    `actor` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '操作人ID，0表示未知',
+    `reason` VARCHAR(255) DEFAULT NULL COMMENT '变更原因',
+    `created_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) COMMENT '变更时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_resource_tag_history_resource` (`tenant_id`, `resource_id`, `resource_type`),
+    KEY `idx_resource_tag_history_tag` (`tag_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='资源标签关联变更历史表';
+
+INSERT INTO `tag_history` (`tenant_id`, `tag_id`, `action`, `version`, `name`, `description`, `color`, `status`, `actor`, `reason`, `created_at`)
+SELECT `tenant_id`, `id`, 'create', `version`, `name`, `description`, `color`, `status`, `created_by`, '历史数据补录', `created_at`
+FROM `tags` ORDER BY `id`;
+
+INSERT INTO `resource_tag_history` (`tenant_id`, `resource_id`, `resource_type`, `tag_id`, `action`, `actor`, `reason`, `created_at`)
+SELECT `tenant_id`, `resource_id`, `resource_type`, `tag_id`, 'assign', 0, '历史数据补录', `created_at`
+FROM `resource_tags` ORDER BY `id`;
@@ -0,0 +1,50 @@
+-- ============================================
+-- Feature: Data Tag Management
+-- Module: tag_management
+-- Description: 标签及关联变更历史 (PostgreSQL)
+--              已有数据按创建时间补录一条 create/assign 记录作为历史起点
+-- Created: 2026-01-05
+-- ============================================
+
+-- 标签变更历史表
+CREATE TABLE tag_history (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    tag_id BIGINT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    version BIGINT NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    description VARCHAR(200) DEFAULT NULL,
+    color VARCHAR(7) DEFAULT NULL,
+    status SMALLINT NOT NULL,
+    actor BIGINT NOT NULL DEFAULT 0,
+    reason VARCHAR(255) DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_tag_history_tag ON tag_history (tenant_id, tag_id);
+CREATE INDEX idx_tag_history_created_at ON tag_history (created_at);
+COMMENT ON TABLE tag_history IS '标签变更历史表';
+
+-- 资源标签关联变更历史表
+CREATE TABLE resource_tag_history (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    resource_id BIGINT NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    tag_id BIGINT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    actor BIGINT NOT NULL DEFAULT 0,
+    reason VARCHAR(255) DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_resource_tag_history_resource ON resource_tag_history (tenant_id, resource_id, resource_type);
+CREATE INDEX idx_resource_tag_history_tag ON resource_tag_history (tag_id);
+COMMENT ON TABLE resource_tag_history IS '资源标签关联变更历史表';
+
+INSERT INTO tag_history (tenant_id, tag_id, action, version, name, description, color, status, actor, reason, created_at)
+SELECT tenant_id, id, 'create', version, name, description, color, status, created_by, '历史数据补录', created_at
+FROM tags ORDER BY id;
+
+INSERT INTO resource_tag_history (tenant_id, resource_id, resource_type, tag_id, action, actor, reason, created_at)
+SELECT tenant_id, resource_id, resource_type, tag_id, 'assign', 0, '历史数据补录', created_at
+FROM resource_tags ORDER BY id;
@@ -0,0 +1,48 @@
+-- ============================================
+-- Feature: Data Tag Management
+-- Module: tag_management
+-- Description: 标签及关联变更历史 (SQLite)
+--              已有数据按创建时间补录一条 create/assign 记录作为历史起点
+-- Created: 2026-01-05
+-- ============================================
+
+-- 标签变更历史表
+CREATE TABLE tag_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id VARCHAR(64) NOT NULL,
+    tag_id INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    version INTEGER NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    description VARCHAR(200) DEFAULT NULL,
+    color VARCHAR(7) DEFAULT NULL,
+    status TINYINT NOT NULL,
+    actor INTEGER NOT NULL DEFAULT 0,
+    reason VARCHAR(255) DEFAULT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_tag_history_tag ON tag_history (tenant_id, tag_id);
+CREATE INDEX idx_tag_history_created_at ON tag_history (created_at);
+
+-- 资源标签关联变更历史表
+CREATE TABLE resource_tag_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id VARCHAR(64) NOT NULL,
+    resource_id INTEGER NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    tag_id INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    actor INTEGER NOT NULL DEFAULT 0,
+    reason VARCHAR(255) DEFAULT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_resource_tag_history_resource ON resource_tag_history (tenant_id, resource_id, resource_type);
+CREATE INDEX idx_resource_tag_history_tag ON resource_tag_history (tag_id);
+
+INSERT INTO tag_history (tenant_id, tag_id, action, version, name, description, color, status, actor, reason, created_at)
+SELECT tenant_id, id, 'create', version, name, description, color, status, created_by, '历史数据补录', created_at
+FROM tags ORDER BY id;
+
+INSERT INTO resource_tag_history (tenant_id, resource_id, resource_type, tag_id, action, actor, reason, created_at)
+SELECT tenant_id, resource_id, resource_type, tag_id, 'assign', 0, '历史数据补录', created_at
+FROM resource_tags ORDER BY id;
@@ -0,0 +1,18 @@
+package audit_log
+
+import "gorm.io/gorm"
+
+var gormFactory func(db *gorm.DB) AuditLogModel
+
+// RegisterGormFactory 注册GORM工厂函数
+func RegisterGormFactory(fn func(db *gorm.DB) AuditLogModel) {
+	gormFactory = fn
+}
+
+// NewAuditLogModel 创建AuditLogModel实例
+func NewAuditLogModel(db *gorm.DB) AuditLogModel {
+	if gormFactory != nil {
+		return gormFactory(db)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package audit_log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"idrm/pkg/tenant"
+
+	"gorm.io/gorm"
+)
+
+type auditLogDao struct {
+	db *gorm.DB
+}
+
+func init() {
+	RegisterGormFactory(newAuditLogDao)
+}
+
+// newAuditLogDao 创建auditLogDao实例
+func newAuditLogDao(db *gorm.DB) AuditLogModel {
+	return &auditLogDao{db: db}
+}
+
+// FindOne 根据ID查询审计日志，只能查询当前租户的记录
+func (d *auditLogDao) FindOne(ctx context.Context, id int64) (*AuditLog, error) {
+	scope, err := d.tenantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result AuditLog
+	err = d.db.WithContext(ctx).Scopes(scope).Where("id = ?", id).First(&result).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("查询审计日志失败: %w", err)
+	}
+	return &result, nil
+}
+
+// List 按条件游标分页查询
+func (d *auditLogDao) List(ctx context.Context, filter *Filter, cursor int64, limit int) ([]*AuditLog, error) {
+	scope, err := d.tenantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := d.db.WithContext(ctx).Scopes(scope, filterScope(filter))
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+
+	var results []*AuditLog
+	if err := query.Order("id DESC").Limit(limit).Find(&results).Error; err != nil {
+		return nil, fmt.Errorf("查询审计日志失败: %w", err)
+	}
+	return results, nil
+}
+
+// Each 按条件分批遍历，用于导出
+func (d *auditLogDao) Each(ctx context.Context, filter *Filter, batchSize int, fn func(batch []*AuditLog) error) error {
+	var cursor int64
+	for {
+		batch, err := d.List(ctx, filter, cursor, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		cursor = batch[len(batch)-1].Id
+	}
+}
+
+// tenantScope 查询范围：普通租户只能看到自己的审计日志，共享租户（平台管理员）可以看到全部
+// 管理员角色由调用方校验，这里只按租户收敛范围
+func (d *auditLogDao) tenantScope(ctx context.Context) (func(*gorm.DB) *gorm.DB, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tenantID == tenant.Shared {
+		return func(tx *gorm.DB) *gorm.DB { return tx }, nil
+	}
+	return tenant.WriteScope(ctx), nil
+}
+
+// filterScope 按查询条件过滤
+func filterScope(filter *Filter) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if filter == nil {
+			return tx
+		}
+		if filter.Action != "" {
+			tx = tx.Where("action = ?", filter.Action)
+		}
+		if filter.Resource != "" {
+			tx = tx.Where("resource = ?", filter.Resource)
+		}
+		if filter.UserId != "" {
+			tx = tx.Where("user_id = ?", filter.UserId)
+		}
+		if filter.TraceId != "" {
+			tx = tx.Where("trace_id = ?", filter.TraceId)
+		}
+		if filter.Success != nil {
+			tx = tx.Where("success = ?", *filter.Success)
+		}
+		if !filter.From.IsZero() {
+			tx = tx.Where("occurred_at >= ?", filter.From)
+		}
+		if !filter.To.IsZero() {
+			tx = tx.Where("occurred_at < ?", filter.To)
+		}
+		return tx
+	}
+}
@@ -0,0 +1,120 @@
+package audit_log
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"idrm/pkg/tenant"
+)
+
+// setupTestDB 创建测试数据库并写入审计日志
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法创建测试数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&AuditLog{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	base := time.Date(2026, 1, 10, 9, 0, 0, 0, time.Local)
+	logs := []*AuditLog{
+		{EventId: "e1", OccurredAt: base, TenantId: "t1", Action: "create", Resource: "tag", UserId: "1", Success: true},
+		{EventId: "e2", OccurredAt: base.Add(time.Hour), TenantId: "t1", Action: "update", Resource: "tag", UserId: "1", TraceId: "abc", Success: true},
+		{EventId: "e3", OccurredAt: base.Add(2 * time.Hour), TenantId: "t1", Action: "update", Resource: "tag", UserId: "2", ErrorMsg: "版本冲突"},
+		{EventId: "e4", OccurredAt: base.Add(3 * time.Hour), TenantId: "t1", Action: "assign", Resource: "tag_association", UserId: "2", Success: true},
+		{EventId: "e5", OccurredAt: base.Add(4 * time.Hour), TenantId: "t2", Action: "create", Resource: "tag", UserId: "3", Success: true},
+	}
+	if err := db.Create(logs).Error; err != nil {
+		t.Fatalf("写入测试数据失败: %v", err)
+	}
+	return db
+}
+
+// TestAuditLogDao_List 测试按条件过滤和游标分页
+func TestAuditLogDao_List(t *testing.T) {
+	dao := &auditLogDao{db: setupTestDB(t)}
+	ctx := tenant.WithTenant(context.Background(), "t1")
+	failed := false
+	base := time.Date(2026, 1, 10, 9, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   []string
+	}{
+		{"无条件", nil, []string{"e4", "e3", "e2", "e1"}},
+		{"按操作", &Filter{Action: "update"}, []string{"e3", "e2"}},
+		{"按资源", &Filter{Resource: "tag_association"}, []string{"e4"}},
+		{"按用户", &Filter{UserId: "1"}, []string{"e2", "e1"}},
+		{"按链路", &Filter{TraceId: "abc"}, []string{"e2"}},
+		{"按结果", &Filter{Success: &failed}, []string{"e3"}},
+		{"按时间", &Filter{From: base.Add(time.Hour), To: base.Add(3 * time.Hour)}, []string{"e3", "e2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, err := dao.List(ctx, tt.filter, 0, 10)
+			if err != nil {
+				t.Fatalf("查询失败: %v", err)
+			}
+			if got := eventIds(logs); !equal(got, tt.want) {
+				t.Errorf("期望 %v, 实际 %v", tt.want, got)
+			}
+		})
+	}
+
+	// 游标分页
+	page1, _ := dao.List(ctx, nil, 0, 3)
+	page2, _ := dao.List(ctx, nil, page1[len(page1)-1].Id, 3)
+	if got := eventIds(page2); !equal(got, []string{"e1"}) {
+		t.Errorf("第二页期望 [e1], 实际 %v", got)
+	}
+}
+
+// TestAuditLogDao_TenantScope 测试租户隔离，共享租户可以查看全部
+func TestAuditLogDao_TenantScope(t *testing.T) {
+	dao := &auditLogDao{db: setupTestDB(t)}
+
+	ctx2 := tenant.WithTenant(context.Background(), "t2")
+	if _, err := dao.FindOne(ctx2, 1); err != ErrNotFound {
+		t.Errorf("其他租户不应看到审计日志, err=%v", err)
+	}
+
+	admin := tenant.WithTenant(context.Background(), tenant.Shared)
+	var count int
+	err := dao.Each(admin, nil, 2, func(batch []*AuditLog) error {
+		count += len(batch)
+		return nil
+	})
+	if err != nil || count != 5 {
+		t.Errorf("共享租户期望遍历5条, 实际=%d, err=%v", count, err)
+	}
+
+	if _, err := dao.List(context.Background(), nil, 0, 10); err == nil {
+		t.Error("缺少租户时应返回错误")
+	}
+}
+
+func eventIds(logs []*AuditLog) []string {
+	ids := make([]string, 0, len(logs))
+	for _, l := range logs {
+		ids = append(ids, l.EventId)
+	}
+	return ids
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,16 @@
+package audit_log
+
+import "context"
+
+// AuditLogModel 审计日志查询接口
+// 写入由 audit 包的 db 存储目标完成，这里只提供查询
+type AuditLogModel interface {
+	// FindOne 根据ID查询审计日志
+	FindOne(ctx context.Context, id int64) (*AuditLog, error)
+
+	// List 按条件游标分页查询，按ID倒序，cursor 为上一页最后一条的ID，0 表示第一页
+	List(ctx context.Context, filter *Filter, cursor int64, limit int) ([]*AuditLog, error)
+
+	// Each 按条件分批遍历审计日志，按ID倒序，fn 返回错误时停止遍历
+	Each(ctx context.Context, filter *Filter, batchSize int, fn func(batch []*AuditLog) error) error
+}
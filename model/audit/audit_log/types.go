@@ -0,0 +1,21 @@
+package audit_log
+
+import (
+	"time"
+
+	"idrm/pkg/telemetry/audit"
+)
+
+// AuditLog 审计日志表记录，表结构由 audit 包定义
+type AuditLog = audit.AuditRecord
+
+// Filter 审计日志查询条件，零值字段不参与过滤
+type Filter struct {
+	Action   string
+	Resource string
+	UserId   string
+	TraceId  string
+	Success  *bool
+	From     time.Time // 发生时间下限（含）
+	To       time.Time // 发生时间上限（不含）
+}
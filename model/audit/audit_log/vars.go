@@ -0,0 +1,8 @@
+package audit_log
+
+import "errors"
+
+// 错误定义
+var (
+	ErrNotFound = errors.New("审计日志不存在")
+)
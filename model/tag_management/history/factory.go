@@ -0,0 +1,18 @@
+package history
+
+import "gorm.io/gorm"
+
+var gormFactory func(db *gorm.DB) HistoryModel
+
+// RegisterGormFactory 注册GORM工厂函数
+func RegisterGormFactory(fn func(db *gorm.DB) HistoryModel) {
+	gormFactory = fn
+}
+
+// NewHistoryModel 创建HistoryModel实例
+func NewHistoryModel(db *gorm.DB) HistoryModel {
+	if gormFactory != nil {
+		return gormFactory(db)
+	}
+	return nil
+}
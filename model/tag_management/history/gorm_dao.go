@@ -0,0 +1,193 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"idrm/pkg/operator"
+	"idrm/pkg/tenant"
+
+	"gorm.io/gorm"
+)
+
+type historyDao struct {
+	db *gorm.DB
+}
+
+func init() {
+	tenant.RegisterTable(TagHistory{}.TableName(), tenant.Shareable)
+	tenant.RegisterTable(ResourceTagHistory{}.TableName(), tenant.Private)
+	RegisterGormFactory(newHistoryDao)
+}
+
+// newHistoryDao 创建historyDao实例
+func newHistoryDao(db *gorm.DB) HistoryModel {
+	return &historyDao{db: db}
+}
+
+// RecordTag 记录一次标签变更
+// 租户取标签自身的归属（共享标签为 *），未指定时取当前租户
+func (d *historyDao) RecordTag(ctx context.Context, entry *TagHistory) error {
+	if entry.TenantId == "" {
+		tenantID, err := tenant.Require(ctx)
+		if err != nil {
+			return err
+		}
+		entry.TenantId = tenantID
+	}
+	fillOperator(ctx, &entry.Actor, &entry.Reason)
+
+	if err := d.db.WithContext(ctx).Create(entry).Error; err != nil {
+		return fmt.Errorf("记录标签变更历史失败: %w", err)
+	}
+	return nil
+}
+
+// RecordResourceTags 批量记录关联变更，租户统一取当前租户
+func (d *historyDao) RecordResourceTags(ctx context.Context, entries []*ResourceTagHistory) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		entry.TenantId = tenantID
+		fillOperator(ctx, &entry.Actor, &entry.Reason)
+	}
+
+	if err := d.db.WithContext(ctx).Create(&entries).Error; err != nil {
+		return fmt.Errorf("记录标签关联变更历史失败: %w", err)
+	}
+	return nil
+}
+
+// ListTagHistory 分页查询标签的变更历史，共享标签的历史对所有租户可见
+func (d *historyDao) ListTagHistory(ctx context.Context, tagID int64, page, pageSize int) ([]*TagHistory, int64, error) {
+	var results []*TagHistory
+	var total int64
+
+	scope := func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("tag_id = ?", tagID)
+	}
+	if err := d.db.WithContext(ctx).Model(&TagHistory{}).Scopes(scope).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询标签变更历史总数失败: %w", err)
+	}
+
+	err := d.db.WithContext(ctx).
+		Scopes(scope).
+		Order("id DESC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&results).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询标签变更历史失败: %w", err)
+	}
+	return results, total, nil
+}
+
+// ListResourceHistory 分页查询资源的关联变更历史
+func (d *historyDao) ListResourceHistory(ctx context.Context, resourceID int64, resourceType string, page, pageSize int) ([]*ResourceTagHistory, int64, error) {
+	var results []*ResourceTagHistory
+	var total int64
+
+	scope := func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("resource_id = ? AND resource_type = ?", resourceID, resourceType)
+	}
+	if err := d.db.WithContext(ctx).Model(&ResourceTagHistory{}).Scopes(scope).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询资源标签变更历史总数失败: %w", err)
+	}
+
+	err := d.db.WithContext(ctx).
+		Scopes(scope).
+		Order("id DESC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&results).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询资源标签变更历史失败: %w", err)
+	}
+	return results, total, nil
+}
+
+// TagsAsOf 查询资源在指定时间点关联的标签
+// 按时间顺序回放该时间点之前的关联事件得到标签集合，再取各标签在该时间点的最新快照；
+// 缺少快照的标签只返回标签ID
+func (d *historyDao) TagsAsOf(ctx context.Context, resourceID int64, resourceType string, at time.Time) ([]*TagHistory, error) {
+	if at.IsZero() {
+		return nil, ErrInvalidTime
+	}
+
+	var events []*ResourceTagHistory
+	err := d.db.WithContext(ctx).
+		Where("resource_id = ? AND resource_type = ? AND created_at <= ?", resourceID, resourceType, at).
+		Order("id").
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询资源标签变更历史失败: %w", err)
+	}
+
+	assigned := make(map[int64]bool)
+	for _, e := range events {
+		switch e.Action {
+		case ActionAssign:
+			assigned[e.TagId] = true
+		case ActionUnassign:
+			delete(assigned, e.TagId)
+		}
+	}
+	if len(assigned) == 0 {
+		return []*TagHistory{}, nil
+	}
+
+	tagIDs := make([]int64, 0, len(assigned))
+	for id := range assigned {
+		tagIDs = append(tagIDs, id)
+	}
+	sort.Slice(tagIDs, func(i, j int) bool { return tagIDs[i] < tagIDs[j] })
+
+	var snapshots []*TagHistory
+	err = d.db.WithContext(ctx).
+		Where("tag_id IN ? AND created_at <= ?", tagIDs, at).
+		Order("id").
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询标签变更历史失败: %w", err)
+	}
+	latest := make(map[int64]*TagHistory, len(tagIDs))
+	for _, s := range snapshots {
+		latest[s.TagId] = s
+	}
+
+	results := make([]*TagHistory, 0, len(tagIDs))
+	for _, id := range tagIDs {
+		if s, ok := latest[id]; ok {
+			results = append(results, s)
+			continue
+		}
+		results = append(results, &TagHistory{TagId: id})
+	}
+	return results, nil
+}
+
+// WithTx 设置事务
+func (d *historyDao) WithTx(tx interface{}) HistoryModel {
+	db, ok := tx.(*gorm.DB)
+	if !ok {
+		return d
+	}
+	return &historyDao{db: db}
+}
+
+// fillOperator 未显式指定时从上下文补充操作人和变更原因
+func fillOperator(ctx context.Context, actor *int64, reason *string) {
+	if *actor == 0 {
+		*actor, _ = operator.FromContext(ctx)
+	}
+	if *reason == "" {
+		*reason = operator.Reason(ctx)
+	}
+}
@@ -0,0 +1,126 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"idrm/pkg/operator"
+	"idrm/pkg/tenant"
+)
+
+// setupTestDB 创建测试数据库
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法创建测试数据库: %v", err)
+	}
+
+	err = db.AutoMigrate(&TagHistory{}, &ResourceTagHistory{})
+	if err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+	if err := db.Use(tenant.NewPlugin()); err != nil {
+		t.Fatalf("注册租户插件失败: %v", err)
+	}
+
+	return db
+}
+
+// TestHistoryDao_RecordOperator 测试从上下文补充操作人和变更原因
+func TestHistoryDao_RecordOperator(t *testing.T) {
+	dao := &historyDao{db: setupTestDB(t)}
+	ctx := tenant.WithTenant(context.Background(), "t1")
+	ctx = operator.WithReason(operator.WithOperator(ctx, 42), "整理标签")
+
+	err := dao.RecordTag(ctx, &TagHistory{TagId: 1, Action: ActionCreate, Version: 1, Name: "财务"})
+	if err != nil {
+		t.Fatalf("记录标签历史失败: %v", err)
+	}
+	err = dao.RecordResourceTags(ctx, []*ResourceTagHistory{
+		{ResourceId: 10, ResourceType: "dataset", TagId: 1, Action: ActionAssign},
+	})
+	if err != nil {
+		t.Fatalf("记录关联历史失败: %v", err)
+	}
+
+	tags, total, _ := dao.ListTagHistory(ctx, 1, 1, 10)
+	if total != 1 || tags[0].Actor != 42 || tags[0].Reason != "整理标签" || tags[0].TenantId != "t1" {
+		t.Errorf("标签历史不符合预期: total=%d, %+v", total, tags)
+	}
+	resources, total, _ := dao.ListResourceHistory(ctx, 10, "dataset", 1, 10)
+	if total != 1 || resources[0].Actor != 42 || resources[0].Reason != "整理标签" {
+		t.Errorf("关联历史不符合预期: total=%d, %+v", total, resources)
+	}
+
+	// 其他租户不可见
+	ctx2 := tenant.WithTenant(context.Background(), "t2")
+	if _, total, _ := dao.ListResourceHistory(ctx2, 10, "dataset", 1, 10); total != 0 {
+		t.Errorf("其他租户不应看到关联历史, 实际=%d", total)
+	}
+}
+
+// TestHistoryDao_TagsAsOf 测试按时间点回放资源标签
+func TestHistoryDao_TagsAsOf(t *testing.T) {
+	db := setupTestDB(t)
+	dao := &historyDao{db: db}
+	ctx := tenant.WithTenant(context.Background(), "t1")
+
+	base := time.Date(2026, 1, 5, 9, 0, 0, 0, time.Local)
+	at := func(hours int) time.Time { return base.Add(time.Duration(hours) * time.Hour) }
+
+	// 标签1 在第1小时改名，标签2 为共享标签；测试数据跨租户写入
+	fixtures := tenant.AllTenants(db)
+	fixtures.Create([]*TagHistory{
+		{TenantId: "t1", TagId: 1, Action: ActionCreate, Version: 1, Name: "财务", CreatedAt: at(0)},
+		{TenantId: tenant.Shared, TagId: 2, Action: ActionCreate, Version: 1, Name: "公开", CreatedAt: at(0)},
+		{TenantId: "t1", TagId: 1, Action: ActionUpdate, Version: 2, Name: "财务部", CreatedAt: at(1)},
+	})
+	fixtures.Create([]*ResourceTagHistory{
+		{TenantId: "t1", ResourceId: 42, ResourceType: "dataset", TagId: 1, Action: ActionAssign, CreatedAt: at(0)},
+		{TenantId: "t1", ResourceId: 42, ResourceType: "dataset", TagId: 2, Action: ActionAssign, CreatedAt: at(0)},
+		{TenantId: "t1", ResourceId: 42, ResourceType: "dataset", TagId: 2, Action: ActionUnassign, CreatedAt: at(2)},
+		{TenantId: "t1", ResourceId: 42, ResourceType: "dataset", TagId: 2, Action: ActionAssign, CreatedAt: at(4)},
+		// 其他租户同ID资源
+		{TenantId: "t2", ResourceId: 42, ResourceType: "dataset", TagId: 3, Action: ActionAssign, CreatedAt: at(0)},
+	})
+
+	tests := []struct {
+		name  string
+		at    time.Time
+		names []string
+	}{
+		{"关联之前", at(-1), []string{}},
+		{"初始状态", at(0), []string{"财务", "公开"}},
+		{"改名之后", at(1), []string{"财务部", "公开"}},
+		{"移除之后", at(3), []string{"财务部"}},
+		{"重新关联", at(5), []string{"财务部", "公开"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := dao.TagsAsOf(ctx, 42, "dataset", tt.at)
+			if err != nil {
+				t.Fatalf("查询失败: %v", err)
+			}
+			var names []string
+			for _, r := range results {
+				names = append(names, r.Name)
+			}
+			if len(names) != len(tt.names) {
+				t.Fatalf("期望=%v, 实际=%v", tt.names, names)
+			}
+			for i := range names {
+				if names[i] != tt.names[i] {
+					t.Errorf("期望=%v, 实际=%v", tt.names, names)
+				}
+			}
+		})
+	}
+
+	if _, err := dao.TagsAsOf(ctx, 42, "dataset", time.Time{}); err != ErrInvalidTime {
+		t.Errorf("期望 ErrInvalidTime, 实际=%v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package history
+
+import (
+	"context"
+	"time"
+)
+
+// HistoryModel 标签及关联变更历史数据访问接口
+// 写入由标签和关联的 DAO 在同一事务内完成，操作人和变更原因从上下文读取
+type HistoryModel interface {
+	// RecordTag 记录一次标签变更
+	RecordTag(ctx context.Context, entry *TagHistory) error
+
+	// RecordResourceTags 批量记录关联变更
+	RecordResourceTags(ctx context.Context, entries []*ResourceTagHistory) error
+
+	// ListTagHistory 分页查询标签的变更历史，按时间倒序
+	ListTagHistory(ctx context.Context, tagID int64, page, pageSize int) ([]*TagHistory, int64, error)
+
+	// ListResourceHistory 分页查询资源的关联变更历史，按时间倒序
+	ListResourceHistory(ctx context.Context, resourceID int64, resourceType string, page, pageSize int) ([]*ResourceTagHistory, int64, error)
+
+	// TagsAsOf 查询资源在指定时间点关联的标签，返回各标签在该时间点的快照
+	TagsAsOf(ctx context.Context, resourceID int64, resourceType string, at time.Time) ([]*TagHistory, error)
+
+	// WithTx 设置事务
+	WithTx(tx interface{}) HistoryModel
+}
@@ -0,0 +1,42 @@
+package history
+
+import "time"
+
+// TagHistory 标签变更历史，记录每次变更后的标签快照（删除时为删除前的快照）
+type TagHistory struct {
+	Id          int64     `json:"id" gorm:"column:id;primaryKey"`
+	TenantId    string    `json:"tenantId" gorm:"column:tenant_id;type:varchar(64);not null;index:idx_tag_history_tag,priority:1"`
+	TagId       int64     `json:"tagId" gorm:"column:tag_id;not null;index:idx_tag_history_tag,priority:2"`
+	Action      string    `json:"action" gorm:"column:action;type:varchar(20);not null"`
+	Version     int64     `json:"version" gorm:"column:version;not null"`
+	Name        string    `json:"name" gorm:"column:name;type:varchar(50);not null"`
+	Description string    `json:"description" gorm:"column:description;type:varchar(200)"`
+	Color       string    `json:"color" gorm:"column:color;type:varchar(7)"`
+	Status      int       `json:"status" gorm:"column:status;type:tinyint;not null"`
+	Actor       int64     `json:"actor" gorm:"column:actor;not null;default:0"`
+	Reason      string    `json:"reason" gorm:"column:reason;type:varchar(255)"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:created_at;autoCreateTime;index:idx_tag_history_created_at"`
+}
+
+// TableName 指定表名
+func (TagHistory) TableName() string {
+	return "tag_history"
+}
+
+// ResourceTagHistory 资源标签关联变更历史，每条记录对应一次关联或取消关联
+type ResourceTagHistory struct {
+	Id           int64     `json:"id" gorm:"column:id;primaryKey"`
+	TenantId     string    `json:"tenantId" gorm:"column:tenant_id;type:varchar(64);not null;index:idx_resource_tag_history_resource,priority:1"`
+	ResourceId   int64     `json:"resourceId" gorm:"column:resource_id;not null;index:idx_resource_tag_history_resource,priority:2"`
+	ResourceType string    `json:"resourceType" gorm:"column:resource_type;type:varchar(50);not null;index:idx_resource_tag_history_resource,priority:3"`
+	TagId        int64     `json:"tagId" gorm:"column:tag_id;not null;index:idx_resource_tag_history_tag"`
+	Action       string    `json:"action" gorm:"column:action;type:varchar(20);not null"`
+	Actor        int64     `json:"actor" gorm:"column:actor;not null;default:0"`
+	Reason       string    `json:"reason" gorm:"column:reason;type:varchar(255)"`
+	CreatedAt    time.Time `json:"createdAt" gorm:"column:created_at;autoCreateTime"`
+}
+
+// TableName 指定表名
+func (ResourceTagHistory) TableName() string {
+	return "resource_tag_history"
+}
@@ -0,0 +1,22 @@
+package history
+
+import "errors"
+
+// 标签变更类型
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionStatus = "status"
+	ActionDelete = "delete"
+)
+
+// 关联变更类型
+const (
+	ActionAssign   = "assign"
+	ActionUnassign = "unassign"
+)
+
+// 错误定义
+var (
+	ErrInvalidTime = errors.New("查询时间无效")
+)
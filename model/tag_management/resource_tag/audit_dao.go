@@ -0,0 +1,138 @@
+package resource_tag
+
+import (
+	"context"
+
+	"idrm/pkg/telemetry/audit"
+)
+
+// auditResourceTagDao 记录审计日志的ResourceTagModel装饰器
+// 关联变更记录资源和请求的标签ID；事务内的操作在提交后写入审计日志
+type auditResourceTagDao struct {
+	model ResourceTagModel
+
+	// pending 事务内待写入的审计日志，提交后统一写入
+	pending *[]pendingLog
+}
+
+// pendingLog 事务内已执行、待提交后写入的审计日志
+type pendingLog struct {
+	ctx context.Context
+	log audit.AuditLog
+}
+
+func init() {
+	RegisterAuditFactory(newAuditResourceTagDao)
+}
+
+// newAuditResourceTagDao 创建auditResourceTagDao实例
+func newAuditResourceTagDao(model ResourceTagModel) ResourceTagModel {
+	return &auditResourceTagDao{model: model}
+}
+
+// Assign 为资源关联单个标签
+func (d *auditResourceTagDao) Assign(ctx context.Context, resourceID int64, resourceType string, tagID int64) error {
+	return d.intercept(ctx, audit.ActionAssign, resourceID, resourceType, []int64{tagID}, func(ctx context.Context) error {
+		return d.model.Assign(ctx, resourceID, resourceType, tagID)
+	})
+}
+
+// Unassign 移除资源的单个标签关联
+func (d *auditResourceTagDao) Unassign(ctx context.Context, resourceID int64, resourceType string, tagID int64) error {
+	return d.intercept(ctx, audit.ActionUnassign, resourceID, resourceType, []int64{tagID}, func(ctx context.Context) error {
+		return d.model.Unassign(ctx, resourceID, resourceType, tagID)
+	})
+}
+
+// GetResourceTags 获取资源的所有标签ID
+func (d *auditResourceTagDao) GetResourceTags(ctx context.Context, resourceID int64, resourceType string) ([]int64, error) {
+	return d.model.GetResourceTags(ctx, resourceID, resourceType)
+}
+
+// GetTagsForResources 批量获取多个资源的标签信息
+func (d *auditResourceTagDao) GetTagsForResources(ctx context.Context, resourceType string, resourceIDs []int64) (map[int64][]TagInfo, error) {
+	return d.model.GetTagsForResources(ctx, resourceType, resourceIDs)
+}
+
+// BatchAssign 批量为资源关联标签
+func (d *auditResourceTagDao) BatchAssign(ctx context.Context, resourceID int64, resourceType string, tagIDs []int64) error {
+	return d.intercept(ctx, audit.ActionAssign, resourceID, resourceType, tagIDs, func(ctx context.Context) error {
+		return d.model.BatchAssign(ctx, resourceID, resourceType, tagIDs)
+	})
+}
+
+// BatchUnassign 批量移除资源的标签关联
+func (d *auditResourceTagDao) BatchUnassign(ctx context.Context, resourceID int64, resourceType string, tagIDs []int64) error {
+	return d.intercept(ctx, audit.ActionUnassign, resourceID, resourceType, tagIDs, func(ctx context.Context) error {
+		return d.model.BatchUnassign(ctx, resourceID, resourceType, tagIDs)
+	})
+}
+
+// ReplaceTags 替换资源的所有标签
+func (d *auditResourceTagDao) ReplaceTags(ctx context.Context, resourceID int64, resourceType string, tagIDs []int64) error {
+	return d.intercept(ctx, audit.ActionUpdate, resourceID, resourceType, tagIDs, func(ctx context.Context) error {
+		return d.model.ReplaceTags(ctx, resourceID, resourceType, tagIDs)
+	})
+}
+
+// FindByResource 查询资源的所有标签关联
+func (d *auditResourceTagDao) FindByResource(ctx context.Context, resourceID int64, resourceType string) ([]*ResourceTag, error) {
+	return d.model.FindByResource(ctx, resourceID, resourceType)
+}
+
+// FindByTag 查询标签关联的所有资源
+func (d *auditResourceTagDao) FindByTag(ctx context.Context, tagID int64) ([]*ResourceTag, error) {
+	return d.model.FindByTag(ctx, tagID)
+}
+
+// FindByTags 查询包含所有指定标签的资源ID列表（AND关系）
+func (d *auditResourceTagDao) FindByTags(ctx context.Context, tagIDs []int64, resourceType string) ([]int64, error) {
+	return d.model.FindByTags(ctx, tagIDs, resourceType)
+}
+
+// CountByTag 统计标签被使用的次数
+func (d *auditResourceTagDao) CountByTag(ctx context.Context, tagID int64) (int64, error) {
+	return d.model.CountByTag(ctx, tagID)
+}
+
+// WithTx 设置事务
+// 无法感知外部事务的提交时机，写操作返回后立即写入审计日志
+func (d *auditResourceTagDao) WithTx(tx interface{}) ResourceTagModel {
+	return &auditResourceTagDao{model: d.model.WithTx(tx)}
+}
+
+// Trans 事务处理
+// 审计日志在提交后写入，回滚时记为失败
+func (d *auditResourceTagDao) Trans(ctx context.Context, fn func(ctx context.Context, model ResourceTagModel) error) error {
+	var logs []pendingLog
+	err := d.model.Trans(ctx, func(ctx context.Context, model ResourceTagModel) error {
+		return fn(ctx, &auditResourceTagDao{model: model, pending: &logs})
+	})
+	for _, p := range logs {
+		if err != nil && p.log.Success {
+			p.log.Success = false
+			p.log.Error = err.Error()
+		}
+		audit.Log(p.ctx, p.log)
+	}
+	return err
+}
+
+// intercept 执行关联变更并记录审计日志，事务内则延迟到提交后写入
+func (d *auditResourceTagDao) intercept(ctx context.Context, action string, resourceID int64, resourceType string, tagIDs []int64, fn func(ctx context.Context) error) error {
+	op := audit.Operation{
+		Action:   action,
+		Resource: audit.ResourceTagAssociation,
+		Extra: map[string]interface{}{
+			"resource_id":   resourceID,
+			"resource_type": resourceType,
+			"tag_ids":       tagIDs,
+		},
+	}
+	if d.pending == nil || !audit.IsEnabled() {
+		return audit.Intercept(ctx, op, fn)
+	}
+	log, err := audit.Capture(ctx, op, fn)
+	*d.pending = append(*d.pending, pendingLog{ctx: ctx, log: log})
+	return err
+}
@@ -0,0 +1,266 @@
+package resource_tag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"idrm/pkg/cache"
+	"idrm/pkg/tenant"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// cachedResourceTagDao 带缓存的ResourceTagModel装饰器
+// 以租户和资源为粒度缓存其标签ID列表，关联变更时按资源失效；
+// 标签名称、颜色和状态读取时经 resolve 解析，标签改名、禁用或删除后立即生效
+type cachedResourceTagDao struct {
+	model   ResourceTagModel
+	cache   cache.Cache
+	resolve TagResolver
+
+	// tx 事务内的实例，读操作直接回源，不读写共享缓存，避免未提交的数据进入缓存
+	tx bool
+	// pending 事务内待失效的key，提交成功后统一删除
+	pending *[]string
+}
+
+func init() {
+	RegisterCacheFactory(newCachedResourceTagDao)
+}
+
+// newCachedResourceTagDao 创建cachedResourceTagDao实例
+func newCachedResourceTagDao(model ResourceTagModel, c cache.Cache, resolve TagResolver) ResourceTagModel {
+	return &cachedResourceTagDao{
+		model:   model,
+		cache:   c,
+		resolve: resolve,
+	}
+}
+
+// Assign 为资源关联单个标签
+func (d *cachedResourceTagDao) Assign(ctx context.Context, resourceID int64, resourceType string, tagID int64) error {
+	if err := d.model.Assign(ctx, resourceID, resourceType, tagID); err != nil {
+		return err
+	}
+	d.invalidate(ctx, cacheResourceKey(ctx, resourceType, resourceID))
+	return nil
+}
+
+// Unassign 移除资源的单个标签关联
+func (d *cachedResourceTagDao) Unassign(ctx context.Context, resourceID int64, resourceType string, tagID int64) error {
+	if err := d.model.Unassign(ctx, resourceID, resourceType, tagID); err != nil {
+		return err
+	}
+	d.invalidate(ctx, cacheResourceKey(ctx, resourceType, resourceID))
+	return nil
+}
+
+// GetResourceTags 获取资源的所有标签ID
+func (d *cachedResourceTagDao) GetResourceTags(ctx context.Context, resourceID int64, resourceType string) ([]int64, error) {
+	return d.model.GetResourceTags(ctx, resourceID, resourceType)
+}
+
+// GetTagsForResources 批量获取多个资源的标签信息
+func (d *cachedResourceTagDao) GetTagsForResources(ctx context.Context, resourceType string, resourceIDs []int64) (map[int64][]TagInfo, error) {
+	result := make(map[int64][]TagInfo, len(resourceIDs))
+	if len(resourceIDs) == 0 {
+		return result, nil
+	}
+	if len(resourceIDs) > MaxBatchResources {
+		return nil, ErrTooManyResources
+	}
+	if d.tx {
+		return d.model.GetTagsForResources(ctx, resourceType, resourceIDs)
+	}
+	if _, err := tenant.Require(ctx); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(resourceIDs))
+	for _, resourceID := range resourceIDs {
+		keys = append(keys, cacheResourceKey(ctx, resourceType, resourceID))
+	}
+
+	hits, err := d.cache.MGet(ctx, keys...)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("批量读取资源标签缓存失败: %v", err)
+		hits = nil
+	}
+
+	// 命中的收集标签ID后一次解析，未命中的收集后一次回源
+	cachedIDs := make(map[int64][]int64, len(resourceIDs))
+	var missed, tagIDs []int64
+	for i, resourceID := range resourceIDs {
+		val, ok := hits[keys[i]]
+		if !ok {
+			missed = append(missed, resourceID)
+			continue
+		}
+		var ids []int64
+		if err := json.Unmarshal([]byte(val), &ids); err != nil {
+			missed = append(missed, resourceID)
+			continue
+		}
+		cachedIDs[resourceID] = ids
+		tagIDs = append(tagIDs, ids...)
+	}
+
+	if len(tagIDs) > 0 {
+		infos, err := d.resolve(ctx, tagIDs)
+		if err != nil {
+			return nil, err
+		}
+		for resourceID, ids := range cachedIDs {
+			for _, id := range ids {
+				// 已删除或不可见的标签跳过
+				if info, ok := infos[id]; ok {
+					result[resourceID] = append(result[resourceID], info)
+				}
+			}
+		}
+	}
+	if len(missed) == 0 {
+		return result, nil
+	}
+
+	loaded, err := d.model.GetTagsForResources(ctx, resourceType, missed)
+	if err != nil {
+		return nil, err
+	}
+	for _, resourceID := range missed {
+		tags := loaded[resourceID]
+		// 无标签的资源同样缓存空列表，避免反复回源
+		ids := make([]int64, 0, len(tags))
+		for _, t := range tags {
+			ids = append(ids, t.Id)
+		}
+		if len(tags) > 0 {
+			result[resourceID] = tags
+		}
+		d.set(ctx, cacheResourceKey(ctx, resourceType, resourceID), ids)
+	}
+
+	return result, nil
+}
+
+// BatchAssign 批量为资源关联标签
+func (d *cachedResourceTagDao) BatchAssign(ctx context.Context, resourceID int64, resourceType string, tagIDs []int64) error {
+	if err := d.model.BatchAssign(ctx, resourceID, resourceType, tagIDs); err != nil {
+		return err
+	}
+	d.invalidate(ctx, cacheResourceKey(ctx, resourceType, resourceID))
+	return nil
+}
+
+// BatchUnassign 批量移除资源的标签关联
+func (d *cachedResourceTagDao) BatchUnassign(ctx context.Context, resourceID int64, resourceType string, tagIDs []int64) error {
+	if err := d.model.BatchUnassign(ctx, resourceID, resourceType, tagIDs); err != nil {
+		return err
+	}
+	d.invalidate(ctx, cacheResourceKey(ctx, resourceType, resourceID))
+	return nil
+}
+
+// ReplaceTags 替换资源的所有标签
+func (d *cachedResourceTagDao) ReplaceTags(ctx context.Context, resourceID int64, resourceType string, tagIDs []int64) error {
+	if err := d.model.ReplaceTags(ctx, resourceID, resourceType, tagIDs); err != nil {
+		return err
+	}
+	d.invalidate(ctx, cacheResourceKey(ctx, resourceType, resourceID))
+	return nil
+}
+
+// FindByResource 查询资源的所有标签关联
+func (d *cachedResourceTagDao) FindByResource(ctx context.Context, resourceID int64, resourceType string) ([]*ResourceTag, error) {
+	return d.model.FindByResource(ctx, resourceID, resourceType)
+}
+
+// FindByTag 查询标签关联的所有资源
+func (d *cachedResourceTagDao) FindByTag(ctx context.Context, tagID int64) ([]*ResourceTag, error) {
+	return d.model.FindByTag(ctx, tagID)
+}
+
+// FindByTags 查询包含所有指定标签的资源ID列表（AND关系）
+func (d *cachedResourceTagDao) FindByTags(ctx context.Context, tagIDs []int64, resourceType string) ([]int64, error) {
+	return d.model.FindByTags(ctx, tagIDs, resourceType)
+}
+
+// CountByTag 统计标签被使用的次数
+func (d *cachedResourceTagDao) CountByTag(ctx context.Context, tagID int64) (int64, error) {
+	return d.model.CountByTag(ctx, tagID)
+}
+
+// WithTx 设置事务
+// 事务内读操作不使用缓存；无法感知外部事务的提交时机，写操作立即失效缓存，
+// 并在 cacheTxInvalidateDelay 后再次失效，清除提交前被并发读回填的旧数据
+func (d *cachedResourceTagDao) WithTx(tx interface{}) ResourceTagModel {
+	return &cachedResourceTagDao{
+		model:   d.model.WithTx(tx),
+		cache:   d.cache,
+		resolve: d.resolve,
+		tx:      true,
+	}
+}
+
+// Trans 事务处理
+// 事务内的写操作延迟到提交成功后再失效缓存
+func (d *cachedResourceTagDao) Trans(ctx context.Context, fn func(ctx context.Context, model ResourceTagModel) error) error {
+	var keys []string
+	err := d.model.Trans(ctx, func(ctx context.Context, model ResourceTagModel) error {
+		txModel := &cachedResourceTagDao{
+			model:   model,
+			cache:   d.cache,
+			resolve: d.resolve,
+			tx:      true,
+			pending: &keys,
+		}
+		return fn(ctx, txModel)
+	})
+	if err != nil {
+		return err
+	}
+	d.del(ctx, keys...)
+	return nil
+}
+
+// invalidate 失效缓存，事务内则延迟到提交后
+func (d *cachedResourceTagDao) invalidate(ctx context.Context, keys ...string) {
+	if d.pending != nil {
+		*d.pending = append(*d.pending, keys...)
+		return
+	}
+	d.del(ctx, keys...)
+	if d.tx {
+		ctx = context.WithoutCancel(ctx)
+		time.AfterFunc(cacheTxInvalidateDelay, func() { d.del(ctx, keys...) })
+	}
+}
+
+// set 写入缓存，失败只记录日志
+func (d *cachedResourceTagDao) set(ctx context.Context, key string, tagIDs []int64) {
+	data, err := json.Marshal(tagIDs)
+	if err != nil {
+		return
+	}
+	if err := d.cache.Set(ctx, key, string(data), CacheExpiry); err != nil {
+		logx.WithContext(ctx).Errorf("写入资源标签缓存失败: key=%s, err=%v", key, err)
+	}
+}
+
+// del 删除缓存，失败只记录日志
+func (d *cachedResourceTagDao) del(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	if err := d.cache.Del(ctx, keys...); err != nil {
+		logx.WithContext(ctx).Errorf("删除资源标签缓存失败: keys=%v, err=%v", keys, err)
+	}
+}
+
+// cacheResourceKey 按当前租户和资源缓存的key
+func cacheResourceKey(ctx context.Context, resourceType string, resourceID int64) string {
+	tenantID, _ := tenant.FromContext(ctx)
+	return fmt.Sprintf("%s%s:%s:%d", cacheResourceTagsPrefix, tenantID, resourceType, resourceID)
+}
@@ -0,0 +1,151 @@
+package resource_tag
+
+import (
+	"context"
+	"testing"
+
+	"idrm/pkg/cache"
+	"idrm/pkg/tenant"
+
+	"gorm.io/gorm"
+)
+
+// countingResourceTagModel 统计回源次数的ResourceTagModel
+type countingResourceTagModel struct {
+	ResourceTagModel
+	loaded [][]int64
+}
+
+func (m *countingResourceTagModel) GetTagsForResources(ctx context.Context, resourceType string, resourceIDs []int64) (map[int64][]TagInfo, error) {
+	m.loaded = append(m.loaded, resourceIDs)
+	return m.ResourceTagModel.GetTagsForResources(ctx, resourceType, resourceIDs)
+}
+
+// dbResolver 直接查询标签表解析标签信息
+func dbResolver(db *gorm.DB) TagResolver {
+	return func(ctx context.Context, tagIDs []int64) (map[int64]TagInfo, error) {
+		var infos []TagInfo
+		if err := db.Table("tags").Where("id IN ?", tagIDs).Find(&infos).Error; err != nil {
+			return nil, err
+		}
+		result := make(map[int64]TagInfo, len(infos))
+		for _, info := range infos {
+			result[info.Id] = info
+		}
+		return result, nil
+	}
+}
+
+// TestCachedResourceTagDao_GetTagsForResources 测试批量读取只回源未命中的资源
+func TestCachedResourceTagDao_GetTagsForResources(t *testing.T) {
+	db := setupTestDB(t)
+	setupTagsTable(t, db, "标签1", "标签2")
+	counting := &countingResourceTagModel{ResourceTagModel: &resourceTagDao{db: db}}
+	cached := newCachedResourceTagDao(counting, cache.NewMemoryCache(), dbResolver(db))
+
+	ctx := tenant.WithTenant(context.Background(), "t1")
+	cached.BatchAssign(ctx, 100, ResourceTypeCatalogCategory, []int64{1})
+
+	results, err := cached.GetTagsForResources(ctx, ResourceTypeCatalogCategory, []int64{100, 200})
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(results[100]) != 1 {
+		t.Errorf("期望资源100有1个标签, 实际=%d", len(results[100]))
+	}
+
+	// 再次查询全部命中缓存（包括无标签的资源）
+	cached.GetTagsForResources(ctx, ResourceTypeCatalogCategory, []int64{100, 200})
+	if len(counting.loaded) != 1 {
+		t.Fatalf("期望回源1次, 实际=%d", len(counting.loaded))
+	}
+
+	// 关联变更只失效对应资源
+	cached.BatchAssign(ctx, 200, ResourceTypeCatalogCategory, []int64{2})
+	results, _ = cached.GetTagsForResources(ctx, ResourceTypeCatalogCategory, []int64{100, 200})
+	if len(counting.loaded) != 2 || len(counting.loaded[1]) != 1 || counting.loaded[1][0] != 200 {
+		t.Errorf("期望只回源资源200, 实际=%v", counting.loaded)
+	}
+	if len(results[200]) != 1 || results[200][0].Name != "标签2" {
+		t.Errorf("期望资源200有标签2, 实际=%v", results[200])
+	}
+}
+
+// TestCachedResourceTagDao_Invalidate 测试写操作失效缓存
+func TestCachedResourceTagDao_Invalidate(t *testing.T) {
+	db := setupTestDB(t)
+	setupTagsTable(t, db, "标签1", "标签2", "标签3")
+	cached := newCachedResourceTagDao(&resourceTagDao{db: db}, cache.NewMemoryCache(), dbResolver(db))
+
+	ctx := tenant.WithTenant(context.Background(), "t1")
+	cached.BatchAssign(ctx, 100, ResourceTypeCatalogCategory, []int64{1, 2})
+	cached.GetTagsForResources(ctx, ResourceTypeCatalogCategory, []int64{100})
+
+	cached.BatchUnassign(ctx, 100, ResourceTypeCatalogCategory, []int64{1})
+	results, _ := cached.GetTagsForResources(ctx, ResourceTypeCatalogCategory, []int64{100})
+	if len(results[100]) != 1 {
+		t.Errorf("移除后期望1个标签, 实际=%d", len(results[100]))
+	}
+
+	cached.ReplaceTags(ctx, 100, ResourceTypeCatalogCategory, []int64{2, 3})
+	results, _ = cached.GetTagsForResources(ctx, ResourceTypeCatalogCategory, []int64{100})
+	if len(results[100]) != 2 {
+		t.Errorf("替换后期望2个标签, 实际=%d", len(results[100]))
+	}
+
+	cached.Unassign(ctx, 100, ResourceTypeCatalogCategory, 2)
+	results, _ = cached.GetTagsForResources(ctx, ResourceTypeCatalogCategory, []int64{100})
+	if len(results[100]) != 1 || results[100][0].Name != "标签3" {
+		t.Errorf("期望只剩标签3, 实际=%v", results[100])
+	}
+}
+
+// TestCachedResourceTagDao_WithTx 测试外部事务内读取的未提交数据不进入缓存
+func TestCachedResourceTagDao_WithTx(t *testing.T) {
+	db := setupTestDB(t)
+	setupTagsTable(t, db, "标签1")
+	cached := newCachedResourceTagDao(&resourceTagDao{db: db}, cache.NewMemoryCache(), dbResolver(db))
+
+	ctx := tenant.WithTenant(context.Background(), "t1")
+	tx := db.WithContext(ctx).Begin()
+	txModel := cached.WithTx(tx)
+	if err := txModel.BatchAssign(ctx, 100, ResourceTypeCatalogCategory, []int64{1}); err != nil {
+		t.Fatalf("关联失败: %v", err)
+	}
+	results, err := txModel.GetTagsForResources(ctx, ResourceTypeCatalogCategory, []int64{100})
+	if err != nil || len(results[100]) != 1 {
+		t.Fatalf("事务内期望1个标签, 实际=%v, err=%v", results[100], err)
+	}
+	tx.Rollback()
+
+	results, _ = cached.GetTagsForResources(ctx, ResourceTypeCatalogCategory, []int64{100})
+	if len(results[100]) != 0 {
+		t.Errorf("回滚后期望无标签, 实际=%v", results[100])
+	}
+}
+
+// TestCachedResourceTagDao_TagChanged 测试标签改名、禁用或删除后缓存的资源标签立即生效
+func TestCachedResourceTagDao_TagChanged(t *testing.T) {
+	db := setupTestDB(t)
+	setupTagsTable(t, db, "标签1", "标签2")
+	counting := &countingResourceTagModel{ResourceTagModel: &resourceTagDao{db: db}}
+	cached := newCachedResourceTagDao(counting, cache.NewMemoryCache(), dbResolver(db))
+
+	ctx := tenant.WithTenant(context.Background(), "t1")
+	cached.BatchAssign(ctx, 100, ResourceTypeCatalogCategory, []int64{1, 2})
+	cached.GetTagsForResources(ctx, ResourceTypeCatalogCategory, []int64{100})
+
+	db.Exec("UPDATE tags SET name = ?, status = 0 WHERE id = 1", "新名称")
+	db.Exec("DELETE FROM tags WHERE id = 2")
+
+	results, err := cached.GetTagsForResources(ctx, ResourceTypeCatalogCategory, []int64{100})
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(counting.loaded) != 1 {
+		t.Errorf("期望命中缓存, 回源=%v", counting.loaded)
+	}
+	if len(results[100]) != 1 || results[100][0].Name != "新名称" || results[100][0].Status != 0 {
+		t.Errorf("期望只剩改名并禁用的标签1, 实际=%v", results[100])
+	}
+}
@@ -1,11 +1,16 @@
 package resource_tag
 
 import (
+	"idrm/pkg/cache"
+
 	"gorm.io/gorm"
 )
 
 var (
-	gormFactory func(db *gorm.DB) ResourceTagModel
+	gormFactory    func(db *gorm.DB) ResourceTagModel
+	cacheFactory   func(model ResourceTagModel, c cache.Cache, resolve TagResolver) ResourceTagModel
+	metricsFactory func(model ResourceTagModel) ResourceTagModel
+	auditFactory   func(model ResourceTagModel) ResourceTagModel
 )
 
 // RegisterGormFactory 注册GORM工厂函数
@@ -13,6 +18,21 @@ func RegisterGormFactory(fn func(db *gorm.DB) ResourceTagModel) {
 	gormFactory = fn
 }
 
+// RegisterCacheFactory 注册缓存装饰器工厂函数
+func RegisterCacheFactory(fn func(model ResourceTagModel, c cache.Cache, resolve TagResolver) ResourceTagModel) {
+	cacheFactory = fn
+}
+
+// RegisterMetricsFactory 注册指标装饰器工厂函数
+func RegisterMetricsFactory(fn func(model ResourceTagModel) ResourceTagModel) {
+	metricsFactory = fn
+}
+
+// RegisterAuditFactory 注册审计装饰器工厂函数
+func RegisterAuditFactory(fn func(model ResourceTagModel) ResourceTagModel) {
+	auditFactory = fn
+}
+
 // NewResourceTagModel 创建ResourceTagModel实例
 func NewResourceTagModel(db *gorm.DB) ResourceTagModel {
 	if gormFactory != nil {
@@ -20,3 +40,29 @@ func NewResourceTagModel(db *gorm.DB) ResourceTagModel {
 	}
 	return nil
 }
+
+// NewCachedResourceTagModel 创建带缓存的ResourceTagModel实例，resolve 用于解析缓存中标签ID对应的标签信息
+// 未提供缓存或 resolve 时退化为 NewResourceTagModel
+func NewCachedResourceTagModel(db *gorm.DB, c cache.Cache, resolve TagResolver) ResourceTagModel {
+	model := NewResourceTagModel(db)
+	if model == nil || c == nil || resolve == nil || cacheFactory == nil {
+		return model
+	}
+	return cacheFactory(model, c, resolve)
+}
+
+// NewInstrumentedResourceTagModel 为ResourceTagModel添加指标装饰器，记录各方法的调用耗时
+func NewInstrumentedResourceTagModel(model ResourceTagModel) ResourceTagModel {
+	if model == nil || metricsFactory == nil {
+		return model
+	}
+	return metricsFactory(model)
+}
+
+// NewAuditedResourceTagModel 为ResourceTagModel添加审计装饰器，关联变更记录审计日志
+func NewAuditedResourceTagModel(model ResourceTagModel) ResourceTagModel {
+	if model == nil || auditFactory == nil {
+		return model
+	}
+	return auditFactory(model)
+}
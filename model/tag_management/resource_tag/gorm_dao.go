@@ -4,6 +4,10 @@ import (
 	"context"
 	"fmt"
 
+	"idrm/model/tag_management/history"
+	"idrm/pkg/db"
+	"idrm/pkg/tenant"
+
 	"gorm.io/gorm"
 )
 
@@ -12,6 +16,7 @@ type resourceTagDao struct {
 }
 
 func init() {
+	tenant.RegisterTable(ResourceTag{}.TableName(), tenant.Private)
 	RegisterGormFactory(newResourceTagDao)
 }
 
@@ -22,16 +27,7 @@ func newResourceTagDao(db *gorm.DB) ResourceTagModel {
 
 // Assign 为资源关联单个标签
 func (d *resourceTagDao) Assign(ctx context.Context, resourceID int64, resourceType string, tagID int64) error {
-	// 使用FirstOrCreate避免重复插入
-	assignment := &ResourceTag{
-		ResourceId:   resourceID,
-		ResourceType: resourceType,
-		TagId:        tagID,
-	}
-	err := d.db.WithContext(ctx).
-		Where("resource_id = ? AND resource_type = ? AND tag_id = ?", resourceID, resourceType, tagID).
-		FirstOrCreate(assignment).Error
-	if err != nil {
+	if err := d.assign(ctx, resourceID, resourceType, []int64{tagID}); err != nil {
 		return fmt.Errorf("关联标签失败: %w", err)
 	}
 	return nil
@@ -39,10 +35,7 @@ func (d *resourceTagDao) Assign(ctx context.Context, resourceID int64, resourceT
 
 // Unassign 移除资源的单个标签关联
 func (d *resourceTagDao) Unassign(ctx context.Context, resourceID int64, resourceType string, tagID int64) error {
-	err := d.db.WithContext(ctx).
-		Where("resource_id = ? AND resource_type = ? AND tag_id = ?", resourceID, resourceType, tagID).
-		Delete(&ResourceTag{}).Error
-	if err != nil {
+	if err := d.unassign(ctx, resourceID, resourceType, []int64{tagID}); err != nil {
 		return fmt.Errorf("移除标签关联失败: %w", err)
 	}
 	return nil
@@ -61,26 +54,46 @@ func (d *resourceTagDao) GetResourceTags(ctx context.Context, resourceID int64,
 	return tagIDs, nil
 }
 
-// BatchAssign 批量为资源关联标签
-func (d *resourceTagDao) BatchAssign(ctx context.Context, resourceID int64, resourceType string, tagIDs []int64) error {
-	if len(tagIDs) == 0 {
-		return nil
+// GetTagsForResources 批量获取多个资源的标签信息
+func (d *resourceTagDao) GetTagsForResources(ctx context.Context, resourceType string, resourceIDs []int64) (map[int64][]TagInfo, error) {
+	result := make(map[int64][]TagInfo, len(resourceIDs))
+	if len(resourceIDs) == 0 {
+		return result, nil
+	}
+	if len(resourceIDs) > MaxBatchResources {
+		return nil, ErrTooManyResources
 	}
 
-	// 构建批量插入数据
-	var assignments []*ResourceTag
-	for _, tagID := range tagIDs {
-		assignments = append(assignments, &ResourceTag{
-			ResourceId:   resourceID,
-			ResourceType: resourceType,
-			TagId:        tagID,
-		})
+	type resourceTagRow struct {
+		ResourceId int64
+		TagInfo
 	}
 
-	// 批量插入（忽略重复）
+	var rows []resourceTagRow
 	err := d.db.WithContext(ctx).
-		Create(&assignments).Error
+		Table("resource_tags AS rt").
+		Select("rt.resource_id, t.id, t.name, t.color, t.status").
+		Joins("JOIN tags t ON t.id = rt.tag_id").
+		Scopes(tenant.ReadScopeOn(ctx, "t."+tenant.Column)).
+		Where("rt.resource_type = ? AND rt.resource_id IN ?", resourceType, resourceIDs).
+		Order("rt.resource_id, rt.id").
+		Scan(&rows).Error
 	if err != nil {
+		return nil, fmt.Errorf("批量获取资源标签失败: %w", err)
+	}
+
+	for _, row := range rows {
+		result[row.ResourceId] = append(result[row.ResourceId], row.TagInfo)
+	}
+	return result, nil
+}
+
+// BatchAssign 批量为资源关联标签
+func (d *resourceTagDao) BatchAssign(ctx context.Context, resourceID int64, resourceType string, tagIDs []int64) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	if err := d.assign(ctx, resourceID, resourceType, tagIDs); err != nil {
 		return fmt.Errorf("批量关联标签失败: %w", err)
 	}
 	return nil
@@ -91,49 +104,139 @@ func (d *resourceTagDao) BatchUnassign(ctx context.Context, resourceID int64, re
 	if len(tagIDs) == 0 {
 		return nil
 	}
-
-	err := d.db.WithContext(ctx).
-		Where("resource_id = ? AND resource_type = ? AND tag_id IN ?", resourceID, resourceType, tagIDs).
-		Delete(&ResourceTag{}).Error
-	if err != nil {
+	if err := d.unassign(ctx, resourceID, resourceType, tagIDs); err != nil {
 		return fmt.Errorf("批量移除标签关联失败: %w", err)
 	}
 	return nil
 }
 
-// ReplaceTags 替换资源的所有标签
+// ReplaceTags 替换资源的所有标签，保留前后都存在的关联
 func (d *resourceTagDao) ReplaceTags(ctx context.Context, resourceID int64, resourceType string, tagIDs []int64) error {
 	return d.Trans(ctx, func(ctx context.Context, model ResourceTagModel) error {
 		txModel := model.(*resourceTagDao)
 
-		// 先删除所有现有关联
-		err := txModel.db.WithContext(ctx).
-			Where("resource_id = ? AND resource_type = ?", resourceID, resourceType).
-			Delete(&ResourceTag{}).Error
+		existing, err := txModel.GetResourceTags(ctx, resourceID, resourceType)
 		if err != nil {
+			return err
+		}
+		wanted := make(map[int64]bool, len(tagIDs))
+		for _, id := range tagIDs {
+			wanted[id] = true
+		}
+		var removed []int64
+		for _, id := range existing {
+			if !wanted[id] {
+				removed = append(removed, id)
+			}
+		}
+
+		if err := txModel.unassign(ctx, resourceID, resourceType, removed); err != nil {
 			return fmt.Errorf("清除现有标签失败: %w", err)
 		}
+		if err := txModel.assign(ctx, resourceID, resourceType, tagIDs); err != nil {
+			return fmt.Errorf("添加新标签失败: %w", err)
+		}
+		return nil
+	})
+}
 
-		// 添加新关联
-		if len(tagIDs) > 0 {
-			var assignments []*ResourceTag
-			for _, tagID := range tagIDs {
-				assignments = append(assignments, &ResourceTag{
-					ResourceId:   resourceID,
-					ResourceType: resourceType,
-					TagId:        tagID,
-				})
+// assign 关联尚未关联的标签，并在同一事务内记录变更历史
+func (d *resourceTagDao) assign(ctx context.Context, resourceID int64, resourceType string, tagIDs []int64) error {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		existing, err := (&resourceTagDao{db: tx}).GetResourceTags(ctx, resourceID, resourceType)
+		if err != nil {
+			return err
+		}
+		added := diffTagIDs(tagIDs, existing)
+		if len(added) == 0 {
+			return nil
+		}
+
+		// 并发关联同一标签时依赖唯一键忽略重复插入，逐行插入以便只为实际插入的关联记录历史
+		inserted := make([]int64, 0, len(added))
+		for _, tagID := range added {
+			result := tx.Clauses(db.OnConflictIgnore()).Create(&ResourceTag{
+				TenantId:     tenantID,
+				ResourceId:   resourceID,
+				ResourceType: resourceType,
+				TagId:        tagID,
+			})
+			if result.Error != nil {
+				return result.Error
 			}
-			err = txModel.db.WithContext(ctx).Create(&assignments).Error
-			if err != nil {
-				return fmt.Errorf("添加新标签失败: %w", err)
+			if result.RowsAffected > 0 {
+				inserted = append(inserted, tagID)
 			}
 		}
+		if len(inserted) == 0 {
+			return nil
+		}
+		return recordHistory(ctx, tx, history.ActionAssign, resourceID, resourceType, inserted)
+	})
+}
 
+// unassign 移除已关联的标签，并在同一事务内记录变更历史
+func (d *resourceTagDao) unassign(ctx context.Context, resourceID int64, resourceType string, tagIDs []int64) error {
+	if len(tagIDs) == 0 {
 		return nil
+	}
+
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var removed []int64
+		err := tx.Model(&ResourceTag{}).
+			Where("resource_id = ? AND resource_type = ? AND tag_id IN ?", resourceID, resourceType, tagIDs).
+			Pluck("tag_id", &removed).Error
+		if err != nil {
+			return err
+		}
+		if len(removed) == 0 {
+			return nil
+		}
+
+		err = tx.Where("resource_id = ? AND resource_type = ? AND tag_id IN ?", resourceID, resourceType, removed).
+			Delete(&ResourceTag{}).Error
+		if err != nil {
+			return err
+		}
+		return recordHistory(ctx, tx, history.ActionUnassign, resourceID, resourceType, removed)
 	})
 }
 
+// diffTagIDs 返回 tagIDs 中不在 existing 内的标签ID（去重并保持顺序）
+func diffTagIDs(tagIDs, existing []int64) []int64 {
+	seen := make(map[int64]bool, len(existing)+len(tagIDs))
+	for _, id := range existing {
+		seen[id] = true
+	}
+	var result []int64
+	for _, id := range tagIDs {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// recordHistory 记录关联变更历史
+func recordHistory(ctx context.Context, tx *gorm.DB, action string, resourceID int64, resourceType string, tagIDs []int64) error {
+	entries := make([]*history.ResourceTagHistory, 0, len(tagIDs))
+	for _, tagID := range tagIDs {
+		entries = append(entries, &history.ResourceTagHistory{
+			ResourceId:   resourceID,
+			ResourceType: resourceType,
+			TagId:        tagID,
+			Action:       action,
+		})
+	}
+	return history.NewHistoryModel(tx).RecordResourceTags(ctx, entries)
+}
+
 // FindByResource 查询资源的所有标签关联
 func (d *resourceTagDao) FindByResource(ctx context.Context, resourceID int64, resourceType string) ([]*ResourceTag, error) {
 	var results []*ResourceTag
@@ -193,8 +296,19 @@ func (d *resourceTagDao) FindByTags(ctx context.Context, tagIDs []int64, resourc
 
 // CountByTag 统计标签被使用的次数
 func (d *resourceTagDao) CountByTag(ctx context.Context, tagID int64) (int64, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// 共享标签被所有租户使用，平台租户统计全部租户的关联，避免删除仍在使用的共享标签
+	query := d.db.WithContext(ctx)
+	if tenantID == tenant.Shared {
+		query = tenant.AllTenants(query)
+	}
+
 	var count int64
-	err := d.db.WithContext(ctx).
+	err = query.
 		Model(&ResourceTag{}).
 		Where("tag_id = ?", tagID).
 		Count(&count).Error
@@ -3,10 +3,14 @@ package resource_tag
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+
+	"idrm/model/tag_management/history"
+	"idrm/pkg/tenant"
 )
 
 // setupTestDB 创建测试数据库
@@ -17,10 +21,13 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	}
 
 	// 自动迁移
-	err = db.AutoMigrate(&ResourceTag{})
+	err = db.AutoMigrate(&ResourceTag{}, &history.ResourceTagHistory{})
 	if err != nil {
 		t.Fatalf("数据库迁移失败: %v", err)
 	}
+	if err := db.Use(tenant.NewPlugin()); err != nil {
+		t.Fatalf("注册租户插件失败: %v", err)
+	}
 
 	return db
 }
@@ -30,7 +37,7 @@ func TestResourceTagDao_Assign(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &resourceTagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	err := dao.Assign(ctx, 100, ResourceTypeCatalogCategory, 1)
 	if err != nil {
 		t.Fatalf("关联失败: %v", err)
@@ -57,7 +64,7 @@ func TestResourceTagDao_Unassign(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &resourceTagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	dao.Assign(ctx, 100, ResourceTypeCatalogCategory, 1)
 
 	// 移除
@@ -78,7 +85,7 @@ func TestResourceTagDao_BatchAssign(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &resourceTagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	tagIDs := []int64{1, 2, 3}
 
 	err := dao.BatchAssign(ctx, 100, ResourceTypeCatalogCategory, tagIDs)
@@ -98,7 +105,7 @@ func TestResourceTagDao_BatchUnassign(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &resourceTagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	tagIDs := []int64{1, 2, 3}
 	dao.BatchAssign(ctx, 100, ResourceTypeCatalogCategory, tagIDs)
 
@@ -120,7 +127,7 @@ func TestResourceTagDao_ReplaceTags(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &resourceTagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	// 先关联3个
 	dao.BatchAssign(ctx, 100, ResourceTypeCatalogCategory, []int64{1, 2, 3})
 
@@ -143,7 +150,7 @@ func TestResourceTagDao_FindByResource(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &resourceTagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	dao.BatchAssign(ctx, 100, ResourceTypeCatalogCategory, []int64{1, 2, 3})
 
 	results, err := dao.FindByResource(ctx, 100, ResourceTypeCatalogCategory)
@@ -160,7 +167,7 @@ func TestResourceTagDao_FindByTag(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &resourceTagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	// 资源100和200都关联标签1
 	dao.Assign(ctx, 100, ResourceTypeCatalogCategory, 1)
 	dao.Assign(ctx, 200, ResourceTypeCatalogCategory, 1)
@@ -179,7 +186,7 @@ func TestResourceTagDao_FindByTags(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &resourceTagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	// 资源100关联标签1,2,3
 	dao.BatchAssign(ctx, 100, ResourceTypeCatalogCategory, []int64{1, 2, 3})
 	// 资源200关联标签1,2
@@ -203,7 +210,7 @@ func TestResourceTagDao_CountByTag(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &resourceTagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	// 3个资源都关联标签1
 	dao.Assign(ctx, 100, ResourceTypeCatalogCategory, 1)
 	dao.Assign(ctx, 200, ResourceTypeCatalogCategory, 1)
@@ -223,7 +230,7 @@ func TestResourceTagDao_Trans(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &resourceTagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 
 	// 测试事务成功
 	err := dao.Trans(ctx, func(ctx context.Context, model ResourceTagModel) error {
@@ -262,3 +269,167 @@ func TestResourceTagDao_Trans(t *testing.T) {
 		t.Error("事务回滚后不应该有记录")
 	}
 }
+
+// setupTagsTable 创建标签表并插入租户 t1 的测试标签
+func setupTagsTable(t *testing.T, db *gorm.DB, names ...string) {
+	err := db.Exec(`CREATE TABLE tags (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		tenant_id VARCHAR(64) NOT NULL DEFAULT 't1',
+		name VARCHAR(50) NOT NULL,
+		color VARCHAR(7) DEFAULT '#1890ff',
+		status TINYINT NOT NULL DEFAULT 1
+	)`).Error
+	if err != nil {
+		t.Fatalf("创建标签表失败: %v", err)
+	}
+	for _, name := range names {
+		if err := db.Exec("INSERT INTO tags (name) VALUES (?)", name).Error; err != nil {
+			t.Fatalf("插入标签失败: %v", err)
+		}
+	}
+}
+
+// TestResourceTagDao_GetTagsForResources 测试批量获取资源标签
+func TestResourceTagDao_GetTagsForResources(t *testing.T) {
+	db := setupTestDB(t)
+	setupTagsTable(t, db, "标签1", "标签2", "标签3")
+	dao := &resourceTagDao{db: db}
+
+	ctx := tenant.WithTenant(context.Background(), "t1")
+	dao.BatchAssign(ctx, 100, ResourceTypeCatalogCategory, []int64{1, 2})
+	dao.BatchAssign(ctx, 200, ResourceTypeCatalogCategory, []int64{3})
+	dao.BatchAssign(ctx, 100, ResourceTypeDataView, []int64{3})
+
+	results, err := dao.GetTagsForResources(ctx, ResourceTypeCatalogCategory, []int64{100, 200, 300})
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(results[100]) != 2 {
+		t.Errorf("期望资源100有2个标签, 实际=%d", len(results[100]))
+	}
+	if len(results[200]) != 1 || results[200][0].Name != "标签3" {
+		t.Errorf("期望资源200有标签3, 实际=%v", results[200])
+	}
+	if _, ok := results[300]; ok {
+		t.Error("资源300不应有标签")
+	}
+
+	// 超过上限
+	ids := make([]int64, MaxBatchResources+1)
+	if _, err := dao.GetTagsForResources(ctx, ResourceTypeCatalogCategory, ids); err != ErrTooManyResources {
+		t.Errorf("期望ErrTooManyResources, 实际=%v", err)
+	}
+}
+
+// TestResourceTagDao_TenantIsolation 测试关联按租户隔离
+func TestResourceTagDao_TenantIsolation(t *testing.T) {
+	db := setupTestDB(t)
+	setupTagsTable(t, db, "标签1", "标签2")
+	db.Exec("UPDATE tags SET tenant_id = ? WHERE id = 2", tenant.Shared)
+	dao := &resourceTagDao{db: db}
+
+	ctx1 := tenant.WithTenant(context.Background(), "t1")
+	ctx2 := tenant.WithTenant(context.Background(), "t2")
+	sharedCtx := tenant.WithTenant(context.Background(), tenant.Shared)
+
+	// 两个租户对同一资源打相同的共享标签
+	if err := dao.BatchAssign(ctx1, 100, ResourceTypeCatalogCategory, []int64{1, 2}); err != nil {
+		t.Fatalf("关联失败: %v", err)
+	}
+	if err := dao.Assign(ctx2, 100, ResourceTypeCatalogCategory, 2); err != nil {
+		t.Fatalf("其他租户关联失败: %v", err)
+	}
+
+	tagIDs, _ := dao.GetResourceTags(ctx2, 100, ResourceTypeCatalogCategory)
+	if len(tagIDs) != 1 || tagIDs[0] != 2 {
+		t.Errorf("t2 只应看到自己的关联, 实际=%v", tagIDs)
+	}
+
+	// 批量查询只返回本租户关联且标签对本租户可见
+	results, err := dao.GetTagsForResources(ctx2, ResourceTypeCatalogCategory, []int64{100})
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(results[100]) != 1 || results[100][0].Id != 2 {
+		t.Errorf("t2 期望仅有共享标签, 实际=%v", results[100])
+	}
+
+	// 替换只影响本租户
+	if err := dao.ReplaceTags(ctx2, 100, ResourceTypeCatalogCategory, nil); err != nil {
+		t.Fatalf("替换失败: %v", err)
+	}
+	if tagIDs, _ := dao.GetResourceTags(ctx1, 100, ResourceTypeCatalogCategory); len(tagIDs) != 2 {
+		t.Errorf("t1 的关联不应受影响, 实际=%v", tagIDs)
+	}
+
+	// 平台租户统计共享标签在所有租户中的使用次数
+	dao.Assign(ctx2, 200, ResourceTypeCatalogCategory, 2)
+	if count, _ := dao.CountByTag(ctx1, 2); count != 1 {
+		t.Errorf("t1 期望使用次数=1, 实际=%d", count)
+	}
+	if count, _ := dao.CountByTag(sharedCtx, 2); count != 2 {
+		t.Errorf("平台租户期望使用次数=2, 实际=%d", count)
+	}
+}
+
+// TestResourceTagDao_History 测试只记录实际发生的关联变更
+func TestResourceTagDao_History(t *testing.T) {
+	db := setupTestDB(t)
+	dao := &resourceTagDao{db: db}
+	ctx := tenant.WithTenant(context.Background(), "t1")
+
+	dao.BatchAssign(ctx, 100, ResourceTypeCatalogDataset, []int64{1, 2})
+	dao.BatchAssign(ctx, 100, ResourceTypeCatalogDataset, []int64{2, 3, 3})
+	dao.Unassign(ctx, 100, ResourceTypeCatalogDataset, 9)
+	dao.ReplaceTags(ctx, 100, ResourceTypeCatalogDataset, []int64{3, 4})
+
+	var events []*history.ResourceTagHistory
+	db.WithContext(ctx).Order("id").Find(&events)
+
+	var got []string
+	for _, e := range events {
+		got = append(got, fmt.Sprintf("%s:%d", e.Action, e.TagId))
+	}
+	expected := []string{"assign:1", "assign:2", "assign:3", "unassign:1", "unassign:2", "assign:4"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("期望=%v, 实际=%v", expected, got)
+	}
+
+	// 保留前后都存在的关联
+	tagIDs, _ := dao.GetResourceTags(ctx, 100, ResourceTypeCatalogDataset)
+	if len(tagIDs) != 2 {
+		t.Errorf("期望2个标签, 实际=%v", tagIDs)
+	}
+}
+
+// TestResourceTagDao_AssignConcurrent 测试并发关联时被唯一键忽略的行不记录历史
+func TestResourceTagDao_AssignConcurrent(t *testing.T) {
+	db := setupTestDB(t)
+	dao := &resourceTagDao{db: db}
+	ctx := tenant.WithTenant(context.Background(), "t1")
+
+	// 读取已有关联之后、插入之前，另一请求抢先关联了标签2
+	raced := false
+	db.Callback().Create().Before("gorm:create").Register("test:race", func(tx *gorm.DB) {
+		if _, ok := tx.Statement.Dest.(*ResourceTag); !ok || raced {
+			return
+		}
+		raced = true
+		tx.Session(&gorm.Session{NewDB: true}).Exec(
+			"INSERT INTO resource_tags (tenant_id, resource_id, resource_type, tag_id) VALUES (?, ?, ?, ?)",
+			"t1", 100, ResourceTypeCatalogDataset, 2)
+	})
+
+	if err := dao.BatchAssign(ctx, 100, ResourceTypeCatalogDataset, []int64{1, 2}); err != nil {
+		t.Fatalf("批量关联失败: %v", err)
+	}
+
+	var events []*history.ResourceTagHistory
+	db.WithContext(ctx).Order("id").Find(&events)
+	if len(events) != 1 || events[0].TagId != 1 {
+		t.Errorf("期望只记录标签1的关联历史, 实际=%v", events)
+	}
+	if tagIDs, _ := dao.GetResourceTags(ctx, 100, ResourceTypeCatalogDataset); len(tagIDs) != 2 {
+		t.Errorf("期望2个标签, 实际=%v", tagIDs)
+	}
+}
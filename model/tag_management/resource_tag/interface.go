@@ -2,6 +2,9 @@ package resource_tag
 
 import "context"
 
+// TagResolver 按ID批量查询当前租户可见的标签信息，不存在或不可见的ID不返回
+type TagResolver func(ctx context.Context, tagIDs []int64) (map[int64]TagInfo, error)
+
 // ResourceTagModel 资源标签关联数据访问接口
 type ResourceTagModel interface {
 	// Assign 为资源关联单个标签
@@ -13,6 +16,9 @@ type ResourceTagModel interface {
 	// GetResourceTags 获取资源的所有标签ID
 	GetResourceTags(ctx context.Context, resourceID int64, resourceType string) ([]int64, error)
 
+	// GetTagsForResources 批量获取多个资源的标签信息
+	GetTagsForResources(ctx context.Context, resourceType string, resourceIDs []int64) (map[int64][]TagInfo, error)
+
 	// BatchAssign 批量为资源关联标签
 	BatchAssign(ctx context.Context, resourceID int64, resourceType string, tagIDs []int64) error
 
@@ -0,0 +1,127 @@
+package resource_tag
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"idrm/pkg/telemetry/metrics"
+)
+
+// metricsModelName 指标中的模型名称
+const metricsModelName = "resource_tag"
+
+// metricsResourceTagDao 记录调用耗时和关联变更数的ResourceTagModel装饰器
+// 关联变更数按请求的标签数累计，ReplaceTags 无法区分新增与移除，不计入
+type metricsResourceTagDao struct {
+	model ResourceTagModel
+}
+
+func init() {
+	RegisterMetricsFactory(newMetricsResourceTagDao)
+}
+
+// newMetricsResourceTagDao 创建metricsResourceTagDao实例
+func newMetricsResourceTagDao(model ResourceTagModel) ResourceTagModel {
+	return &metricsResourceTagDao{model: model}
+}
+
+// Assign 为资源关联单个标签
+func (d *metricsResourceTagDao) Assign(ctx context.Context, resourceID int64, resourceType string, tagID int64) (err error) {
+	defer d.observe("Assign", time.Now(), &err)
+	if err = d.model.Assign(ctx, resourceID, resourceType, tagID); err == nil {
+		metrics.AddAssignments(metrics.OpAssign, 1)
+	}
+	return err
+}
+
+// Unassign 移除资源的单个标签关联
+func (d *metricsResourceTagDao) Unassign(ctx context.Context, resourceID int64, resourceType string, tagID int64) (err error) {
+	defer d.observe("Unassign", time.Now(), &err)
+	if err = d.model.Unassign(ctx, resourceID, resourceType, tagID); err == nil {
+		metrics.AddAssignments(metrics.OpUnassign, 1)
+	}
+	return err
+}
+
+// GetResourceTags 获取资源的所有标签ID
+func (d *metricsResourceTagDao) GetResourceTags(ctx context.Context, resourceID int64, resourceType string) (result []int64, err error) {
+	defer d.observe("GetResourceTags", time.Now(), &err)
+	return d.model.GetResourceTags(ctx, resourceID, resourceType)
+}
+
+// GetTagsForResources 批量获取多个资源的标签信息
+func (d *metricsResourceTagDao) GetTagsForResources(ctx context.Context, resourceType string, resourceIDs []int64) (result map[int64][]TagInfo, err error) {
+	defer d.observe("GetTagsForResources", time.Now(), &err)
+	return d.model.GetTagsForResources(ctx, resourceType, resourceIDs)
+}
+
+// BatchAssign 批量为资源关联标签
+func (d *metricsResourceTagDao) BatchAssign(ctx context.Context, resourceID int64, resourceType string, tagIDs []int64) (err error) {
+	defer d.observe("BatchAssign", time.Now(), &err)
+	if err = d.model.BatchAssign(ctx, resourceID, resourceType, tagIDs); err == nil {
+		metrics.AddAssignments(metrics.OpAssign, len(tagIDs))
+	}
+	return err
+}
+
+// BatchUnassign 批量移除资源的标签关联
+func (d *metricsResourceTagDao) BatchUnassign(ctx context.Context, resourceID int64, resourceType string, tagIDs []int64) (err error) {
+	defer d.observe("BatchUnassign", time.Now(), &err)
+	if err = d.model.BatchUnassign(ctx, resourceID, resourceType, tagIDs); err == nil {
+		metrics.AddAssignments(metrics.OpUnassign, len(tagIDs))
+	}
+	return err
+}
+
+// ReplaceTags 替换资源的所有标签
+func (d *metricsResourceTagDao) ReplaceTags(ctx context.Context, resourceID int64, resourceType string, tagIDs []int64) (err error) {
+	defer d.observe("ReplaceTags", time.Now(), &err)
+	return d.model.ReplaceTags(ctx, resourceID, resourceType, tagIDs)
+}
+
+// FindByResource 查询资源的所有标签关联
+func (d *metricsResourceTagDao) FindByResource(ctx context.Context, resourceID int64, resourceType string) (result []*ResourceTag, err error) {
+	defer d.observe("FindByResource", time.Now(), &err)
+	return d.model.FindByResource(ctx, resourceID, resourceType)
+}
+
+// FindByTag 查询标签关联的所有资源
+func (d *metricsResourceTagDao) FindByTag(ctx context.Context, tagID int64) (result []*ResourceTag, err error) {
+	defer d.observe("FindByTag", time.Now(), &err)
+	return d.model.FindByTag(ctx, tagID)
+}
+
+// FindByTags 查询包含所有指定标签的资源ID列表（AND关系）
+func (d *metricsResourceTagDao) FindByTags(ctx context.Context, tagIDs []int64, resourceType string) (result []int64, err error) {
+	defer d.observe("FindByTags", time.Now(), &err)
+	return d.model.FindByTags(ctx, tagIDs, resourceType)
+}
+
+// CountByTag 统计标签被使用的次数
+func (d *metricsResourceTagDao) CountByTag(ctx context.Context, tagID int64) (count int64, err error) {
+	defer d.observe("CountByTag", time.Now(), &err)
+	return d.model.CountByTag(ctx, tagID)
+}
+
+// WithTx 设置事务
+func (d *metricsResourceTagDao) WithTx(tx interface{}) ResourceTagModel {
+	return &metricsResourceTagDao{model: d.model.WithTx(tx)}
+}
+
+// Trans 事务处理，事务内的调用同样记录耗时
+func (d *metricsResourceTagDao) Trans(ctx context.Context, fn func(ctx context.Context, model ResourceTagModel) error) (err error) {
+	defer d.observe("Trans", time.Now(), &err)
+	return d.model.Trans(ctx, func(ctx context.Context, model ResourceTagModel) error {
+		return fn(ctx, &metricsResourceTagDao{model: model})
+	})
+}
+
+// observe 记录方法耗时，关联不存在不计为失败
+func (d *metricsResourceTagDao) observe(method string, start time.Time, err *error) {
+	e := *err
+	if errors.Is(e, ErrNotFound) {
+		e = nil
+	}
+	metrics.ObserveDAO(metricsModelName, method, start, e)
+}
@@ -0,0 +1,69 @@
+package resource_tag
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"idrm/pkg/telemetry/metrics"
+	"idrm/pkg/tenant"
+)
+
+// scrapeMetric 读取指标当前值，不存在时返回0
+func scrapeMetric(t *testing.T, series string) float64 {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	metrics.Handler()(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if value, ok := strings.CutPrefix(line, series+" "); ok {
+			v, _ := strconv.ParseFloat(value, 64)
+			return v
+		}
+	}
+	return 0
+}
+
+// TestMetricsResourceTagDao 测试指标装饰器记录调用耗时和关联变更数
+func TestMetricsResourceTagDao(t *testing.T) {
+	metrics.Init(metrics.MetricsConfig{Enabled: true, Path: "/metrics"})
+	model := NewInstrumentedResourceTagModel(&resourceTagDao{db: setupTestDB(t)})
+	ctx := tenant.WithTenant(context.Background(), "t1")
+
+	const (
+		assigned   = `idrm_tag_assignments_total{op="assign"}`
+		unassigned = `idrm_tag_assignments_total{op="unassign"}`
+		calls      = `idrm_dao_call_duration_ms_count{method="BatchAssign",model="resource_tag",result="ok"}`
+	)
+	assignedBefore, unassignedBefore, callsBefore := scrapeMetric(t, assigned), scrapeMetric(t, unassigned), scrapeMetric(t, calls)
+
+	if err := model.BatchAssign(ctx, 100, ResourceTypeDataView, []int64{1, 2, 3}); err != nil {
+		t.Fatalf("批量关联失败: %v", err)
+	}
+	if err := model.Unassign(ctx, 100, ResourceTypeDataView, 1); err != nil {
+		t.Fatalf("取消关联失败: %v", err)
+	}
+
+	if got := scrapeMetric(t, assigned) - assignedBefore; got != 3 {
+		t.Errorf("关联数增量 = %v, want 3", got)
+	}
+	if got := scrapeMetric(t, unassigned) - unassignedBefore; got != 1 {
+		t.Errorf("取消关联数增量 = %v, want 1", got)
+	}
+	if got := scrapeMetric(t, calls) - callsBefore; got != 1 {
+		t.Errorf("BatchAssign 调用数增量 = %v, want 1", got)
+	}
+
+	// 事务内的模型同样带指标装饰器
+	err := model.Trans(ctx, func(ctx context.Context, tx ResourceTagModel) error {
+		if _, ok := tx.(*metricsResourceTagDao); !ok {
+			t.Errorf("事务模型类型 = %T, want *metricsResourceTagDao", tx)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("事务执行失败: %v", err)
+	}
+}
@@ -5,9 +5,10 @@ import "time"
 // ResourceTag 资源标签关联实体
 type ResourceTag struct {
 	Id           int64     `json:"id" gorm:"column:id;primaryKey"`
-	ResourceId   int64     `json:"resourceId" gorm:"column:resource_id;not null"`
-	ResourceType string    `json:"resourceType" gorm:"column:resource_type;type:varchar(50);not null"`
-	TagId        int64     `json:"tagId" gorm:"column:tag_id;not null"`
+	TenantId     string    `json:"tenantId" gorm:"column:tenant_id;type:varchar(64);not null;uniqueIndex:uk_resource_tag,priority:1"`
+	ResourceId   int64     `json:"resourceId" gorm:"column:resource_id;not null;uniqueIndex:uk_resource_tag,priority:2"`
+	ResourceType string    `json:"resourceType" gorm:"column:resource_type;type:varchar(50);not null;uniqueIndex:uk_resource_tag,priority:3"`
+	TagId        int64     `json:"tagId" gorm:"column:tag_id;not null;uniqueIndex:uk_resource_tag,priority:4"`
 	CreatedAt    time.Time `json:"createdAt" gorm:"column:created_at;autoCreateTime"`
 }
 
@@ -15,3 +16,11 @@ type ResourceTag struct {
 func (ResourceTag) TableName() string {
 	return "resource_tags"
 }
+
+// TagInfo 资源上的标签信息
+type TagInfo struct {
+	Id     int64  `json:"id" gorm:"column:id"`
+	Name   string `json:"name" gorm:"column:name"`
+	Color  string `json:"color" gorm:"column:color"`
+	Status int    `json:"status" gorm:"column:status"`
+}
@@ -1,19 +1,36 @@
 package resource_tag
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 // 常量定义
 const (
 	// 资源类型
-	ResourceTypeCatalogCategory = "catalog_category"
-	ResourceTypeCatalogDataset  = "catalog_dataset"
-	ResourceTypeDataView        = "data_view"
+	ResourceTypeCatalogCategory   = "catalog_category"
+	ResourceTypeCatalogDataset    = "catalog_dataset"
+	ResourceTypeDataView          = "data_view"
 	ResourceTypeDataUnderstanding = "data_understanding"
+
+	// 批量查询资源标签时单次允许的最大资源数
+	MaxBatchResources = 100
+)
+
+// 缓存配置
+const (
+	// 资源标签缓存过期时间，缓存内容为标签ID列表，标签信息读取时经 TagResolver 解析
+	CacheExpiry = 5 * time.Minute
+
+	cacheTxInvalidateDelay = time.Second // WithTx 写操作二次失效缓存的延迟，需大于事务提交耗时
+
+	cacheResourceTagsPrefix = "cache:resource_tag:ids:"
 )
 
 // 错误定义
 var (
-	ErrNotFound      = errors.New("关联不存在")
-	ErrAlreadyExists = errors.New("关联已存在")
-	ErrInvalidParams = errors.New("参数无效")
+	ErrNotFound         = errors.New("关联不存在")
+	ErrAlreadyExists    = errors.New("关联已存在")
+	ErrInvalidParams    = errors.New("参数无效")
+	ErrTooManyResources = errors.New("批量查询的资源数量超过上限")
 )
@@ -0,0 +1,180 @@
+package tag
+
+import (
+	"context"
+
+	"idrm/pkg/db"
+	"idrm/pkg/telemetry/audit"
+)
+
+// auditTagDao 记录审计日志的TagModel装饰器
+// 写操作前后的快照经 source 从主库读取，不经过缓存和从库；事务内的操作在提交后写入审计日志
+type auditTagDao struct {
+	model  TagModel
+	source TagModel
+
+	// pending 事务内待写入的审计日志，提交后统一写入
+	pending *[]pendingLog
+}
+
+// pendingLog 事务内已执行、待提交后写入的审计日志
+type pendingLog struct {
+	ctx context.Context
+	log audit.AuditLog
+}
+
+func init() {
+	RegisterAuditFactory(newAuditTagDao)
+}
+
+// newAuditTagDao 创建auditTagDao实例，source 用于读取快照
+func newAuditTagDao(model, source TagModel) TagModel {
+	return &auditTagDao{model: model, source: source}
+}
+
+// Insert 插入新记录
+func (d *auditTagDao) Insert(ctx context.Context, data *Tag) (result *Tag, err error) {
+	err = d.intercept(ctx, audit.Operation{
+		Action:   audit.ActionCreate,
+		Resource: audit.ResourceTag,
+		After:    func(ctx context.Context) interface{} { return d.snapshot(ctx, result.Id) },
+		Extra:    map[string]interface{}{"name": data.Name},
+	}, func(ctx context.Context) error {
+		result, err = d.model.Insert(ctx, data)
+		return err
+	})
+	return result, err
+}
+
+// FindOne 根据ID查询
+func (d *auditTagDao) FindOne(ctx context.Context, id int64) (*Tag, error) {
+	return d.model.FindOne(ctx, id)
+}
+
+// FindByName 根据名称查询
+func (d *auditTagDao) FindByName(ctx context.Context, name string) (*Tag, error) {
+	return d.model.FindByName(ctx, name)
+}
+
+// FindByIds 根据ID批量查询
+func (d *auditTagDao) FindByIds(ctx context.Context, ids []int64) ([]*Tag, error) {
+	return d.model.FindByIds(ctx, ids)
+}
+
+// Update 更新记录
+func (d *auditTagDao) Update(ctx context.Context, data *Tag) error {
+	return d.intercept(ctx, d.changeOperation(audit.ActionUpdate, data.Id), func(ctx context.Context) error {
+		return d.model.Update(ctx, data)
+	})
+}
+
+// Patch 按列部分更新
+func (d *auditTagDao) Patch(ctx context.Context, id, version int64, fields map[string]interface{}) error {
+	return d.intercept(ctx, d.changeOperation(patchAction(fields), id), func(ctx context.Context) error {
+		return d.model.Patch(ctx, id, version, fields)
+	})
+}
+
+// Delete 删除记录，审计日志保留删除前的快照
+func (d *auditTagDao) Delete(ctx context.Context, id int64) error {
+	return d.intercept(ctx, audit.Operation{
+		Action:   audit.ActionDelete,
+		Resource: audit.ResourceTag,
+		Before:   func(ctx context.Context) interface{} { return d.snapshot(ctx, id) },
+		Extra:    map[string]interface{}{"id": id},
+	}, func(ctx context.Context) error {
+		return d.model.Delete(ctx, id)
+	})
+}
+
+// FindAll 查询所有记录
+func (d *auditTagDao) FindAll(ctx context.Context) ([]*Tag, error) {
+	return d.model.FindAll(ctx)
+}
+
+// List 分页查询
+func (d *auditTagDao) List(ctx context.Context, page, pageSize int) ([]*Tag, int64, error) {
+	return d.model.List(ctx, page, pageSize)
+}
+
+// Search 关键词搜索
+func (d *auditTagDao) Search(ctx context.Context, keyword string, page, pageSize int) ([]*Tag, int64, error) {
+	return d.model.Search(ctx, keyword, page, pageSize)
+}
+
+// UpdateStatus 更新状态
+func (d *auditTagDao) UpdateStatus(ctx context.Context, id int64, status int) error {
+	return d.intercept(ctx, d.changeOperation(audit.ActionStatus, id), func(ctx context.Context) error {
+		return d.model.UpdateStatus(ctx, id, status)
+	})
+}
+
+// WithTx 设置事务
+// 无法感知外部事务的提交时机，写操作返回后立即写入审计日志
+func (d *auditTagDao) WithTx(tx interface{}) TagModel {
+	return &auditTagDao{
+		model:  d.model.WithTx(tx),
+		source: d.source.WithTx(tx),
+	}
+}
+
+// Trans 事务处理
+// 事务内的快照在事务中读取，审计日志在提交后写入；回滚时记为失败
+func (d *auditTagDao) Trans(ctx context.Context, fn func(ctx context.Context, model TagModel) error) error {
+	var logs []pendingLog
+	err := d.model.Trans(ctx, func(ctx context.Context, model TagModel) error {
+		return fn(ctx, &auditTagDao{model: model, source: model, pending: &logs})
+	})
+	for _, p := range logs {
+		if err != nil && p.log.Success {
+			p.log.Success = false
+			p.log.Error = err.Error()
+			p.log.After = nil
+		}
+		audit.Log(p.ctx, p.log)
+	}
+	return err
+}
+
+// changeOperation 修改已有记录的审计操作，记录修改前后的快照
+func (d *auditTagDao) changeOperation(action string, id int64) audit.Operation {
+	return audit.Operation{
+		Action:   action,
+		Resource: audit.ResourceTag,
+		Before:   func(ctx context.Context) interface{} { return d.snapshot(ctx, id) },
+		After:    func(ctx context.Context) interface{} { return d.snapshot(ctx, id) },
+		Extra:    map[string]interface{}{"id": id},
+	}
+}
+
+// intercept 执行写操作并记录审计日志，事务内则延迟到提交后写入
+func (d *auditTagDao) intercept(ctx context.Context, op audit.Operation, fn func(ctx context.Context) error) error {
+	if d.pending == nil || !audit.IsEnabled() {
+		return audit.Intercept(ctx, op, fn)
+	}
+	log, err := audit.Capture(ctx, op, fn)
+	*d.pending = append(*d.pending, pendingLog{ctx: ctx, log: log})
+	return err
+}
+
+// snapshot 从主库读取记录快照，读取失败时不记录快照
+func (d *auditTagDao) snapshot(ctx context.Context, id int64) interface{} {
+	result, err := d.source.FindOne(db.WithPrimary(ctx), id)
+	if err != nil {
+		return nil
+	}
+	return result
+}
+
+// patchAction 判断部分更新的审计操作类型，除更新人外只修改状态时视为状态变更
+func patchAction(fields map[string]interface{}) string {
+	for column := range fields {
+		if column != "status" && column != "updated_by" {
+			return audit.ActionUpdate
+		}
+	}
+	if _, ok := fields["status"]; !ok {
+		return audit.ActionUpdate
+	}
+	return audit.ActionStatus
+}
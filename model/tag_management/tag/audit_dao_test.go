@@ -0,0 +1,148 @@
+package tag
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"idrm/pkg/operator"
+	"idrm/pkg/telemetry/audit"
+	"idrm/pkg/tenant"
+)
+
+// chanSink 将审计日志写入通道的存储目标
+type chanSink chan audit.AuditLog
+
+func (s chanSink) Name() string { return "chan" }
+
+func (s chanSink) Write(ctx context.Context, logs []audit.AuditLog) error {
+	for _, log := range logs {
+		s <- log
+	}
+	return nil
+}
+
+func (s chanSink) Close() error { return nil }
+
+// setupAuditDao 启用审计日志并创建带审计装饰器的TagModel
+func setupAuditDao(t *testing.T) (TagModel, chanSink) {
+	sink := make(chanSink, 16)
+	if err := audit.Init(audit.AuditConfig{Enabled: true, Buffer: 1, SpoolDir: t.TempDir()}, "test", audit.WithSink(sink)); err != nil {
+		t.Fatalf("初始化审计日志失败: %v", err)
+	}
+	t.Cleanup(audit.Close)
+
+	dao := &tagDao{db: setupTestDB(t)}
+	return newAuditTagDao(dao, dao), sink
+}
+
+// next 等待下一条审计日志
+func (s chanSink) next(t *testing.T) audit.AuditLog {
+	t.Helper()
+	select {
+	case log := <-s:
+		return log
+	case <-time.After(3 * time.Second):
+		t.Fatal("等待审计日志超时")
+		return audit.AuditLog{}
+	}
+}
+
+// snapshotField 读取快照中的字段
+func snapshotField(snapshot interface{}, field string) interface{} {
+	m, _ := snapshot.(map[string]interface{})
+	return m[field]
+}
+
+// TestAuditTagDao 测试写操作的审计日志，快照取自数据库而不是调用方的内存数据
+func TestAuditTagDao(t *testing.T) {
+	model, sink := setupAuditDao(t)
+
+	ctx := tenant.WithTenant(context.Background(), "t1")
+	ctx = operator.WithClientIP(operator.WithOperator(ctx, 42), "10.0.0.1")
+	ctx = operator.WithRequest(ctx, "PUT", "/api/v1/tags/1")
+
+	created, err := model.Insert(ctx, &Tag{Name: "审计", Description: "原描述", Status: StatusEnabled, CreatedBy: 42})
+	if err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+	log := sink.next(t)
+	if log.Action != audit.ActionCreate || snapshotField(log.After, "name") != "审计" {
+		t.Errorf("创建的审计日志不符合预期: %+v", log)
+	}
+
+	// 结构体更新跳过零值，清空的描述不会写入，变更后快照应与数据库一致
+	update := *created
+	update.Description = ""
+	if err := model.Update(ctx, &update); err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+	log = sink.next(t)
+	if log.Action != audit.ActionUpdate || !log.Success {
+		t.Errorf("更新的审计日志不符合预期: %+v", log)
+	}
+	if log.UserID != "42" || log.IP != "10.0.0.1" || log.TenantID != "t1" || log.Path != "/api/v1/tags/1" {
+		t.Errorf("审计日志应取自上下文: %+v", log)
+	}
+	if got := snapshotField(log.After, "description"); got != "原描述" {
+		t.Errorf("变更后快照应取自数据库, 实际描述=%v", got)
+	}
+	if snapshotField(log.Before, "version") != float64(1) || snapshotField(log.After, "version") != float64(2) {
+		t.Errorf("快照版本不符合预期: before=%v after=%v", log.Before, log.After)
+	}
+
+	// 只修改状态时按状态变更记录
+	if err := model.Patch(ctx, created.Id, 2, map[string]interface{}{"status": StatusDisabled, "updated_by": int64(42)}); err != nil {
+		t.Fatalf("部分更新失败: %v", err)
+	}
+	log = sink.next(t)
+	if log.Action != audit.ActionStatus || snapshotField(log.After, "status") != float64(StatusDisabled) {
+		t.Errorf("状态变更的审计日志不符合预期: %+v", log)
+	}
+
+	// 失败的操作同样记录，不采集变更后快照
+	if err := model.Patch(ctx, created.Id, 2, map[string]interface{}{"name": "过期"}); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("期望 ErrVersionConflict, 实际=%v", err)
+	}
+	log = sink.next(t)
+	if log.Success || log.Error == "" || log.After != nil {
+		t.Errorf("失败的审计日志不符合预期: %+v", log)
+	}
+
+	// 删除记录删除前的快照
+	if err := model.Delete(ctx, created.Id); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	log = sink.next(t)
+	if log.Action != audit.ActionDelete || snapshotField(log.Before, "name") != "审计" || log.After != nil {
+		t.Errorf("删除的审计日志不符合预期: %+v", log)
+	}
+}
+
+// TestAuditTagDao_Trans 测试事务内的操作在提交后写入审计日志，回滚时记为失败
+func TestAuditTagDao_Trans(t *testing.T) {
+	model, sink := setupAuditDao(t)
+	ctx := tenant.WithTenant(context.Background(), "t1")
+
+	rollback := errors.New("rollback")
+	err := model.Trans(ctx, func(ctx context.Context, model TagModel) error {
+		if _, err := model.Insert(ctx, &Tag{Name: "回滚", Status: StatusEnabled, CreatedBy: 1}); err != nil {
+			return err
+		}
+		select {
+		case log := <-sink:
+			t.Errorf("提交前不应写入审计日志: %+v", log)
+		case <-time.After(50 * time.Millisecond):
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatalf("期望事务回滚, 实际=%v", err)
+	}
+
+	log := sink.next(t)
+	if log.Action != audit.ActionCreate || log.Success || log.After != nil {
+		t.Errorf("回滚的操作应记为失败: %+v", log)
+	}
+}
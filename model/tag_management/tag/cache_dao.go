@@ -0,0 +1,360 @@
+package tag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"idrm/pkg/cache"
+	"idrm/pkg/tenant"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/syncx"
+)
+
+// cachedTagDao 带缓存的TagModel装饰器
+// 按ID读穿缓存完整记录（不区分租户，读取时校验可见性），按租户和名称缓存 name->id 映射；
+// 当前租户不可见的记录按租户写入占位符做负缓存。共享标签新建后，其他租户的负缓存在过期后收敛
+type cachedTagDao struct {
+	model   TagModel
+	cache   cache.Cache
+	barrier syncx.SingleFlight
+
+	// tx 事务内的实例，读操作直接回源，不读写共享缓存，避免未提交的数据进入缓存
+	tx bool
+	// pending 事务内待失效的key，提交成功后统一删除
+	pending *[]string
+}
+
+func init() {
+	RegisterCacheFactory(newCachedTagDao)
+}
+
+// newCachedTagDao 创建cachedTagDao实例
+func newCachedTagDao(model TagModel, c cache.Cache) TagModel {
+	return &cachedTagDao{
+		model:   model,
+		cache:   c,
+		barrier: syncx.NewSingleFlight(),
+	}
+}
+
+// Insert 插入新记录
+func (d *cachedTagDao) Insert(ctx context.Context, data *Tag) (*Tag, error) {
+	result, err := d.model.Insert(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	// 清除该名称可能存在的负缓存
+	d.invalidate(ctx,
+		cacheNameKey(result.TenantId, result.Name),
+		cacheMissKey(result.TenantId, result.Id),
+		cacheIdKey(result.Id),
+	)
+	return result, nil
+}
+
+// FindOne 根据ID查询
+func (d *cachedTagDao) FindOne(ctx context.Context, id int64) (*Tag, error) {
+	if d.tx {
+		return d.model.FindOne(ctx, id)
+	}
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := cacheIdKey(id)
+	if val, ok := d.get(ctx, key); ok {
+		var result Tag
+		if err := json.Unmarshal([]byte(val), &result); err == nil {
+			if !tenant.Visible(ctx, result.TenantId) {
+				return nil, ErrNotFound
+			}
+			return &result, nil
+		}
+		// 缓存内容损坏时回源
+		d.del(ctx, key)
+	}
+
+	missKey := cacheMissKey(tenantID, id)
+	if _, ok := d.get(ctx, missKey); ok {
+		return nil, ErrNotFound
+	}
+
+	val, err := d.barrier.Do(missKey, func() (any, error) {
+		result, err := d.model.FindOne(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			d.set(ctx, missKey, cacheNotFoundPlaceholder, CacheNotFoundExpiry)
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		if data, err := json.Marshal(result); err == nil {
+			d.set(ctx, key, string(data), CacheExpiry)
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 返回副本，避免共享结果被调用方修改
+	result := *val.(*Tag)
+	return &result, nil
+}
+
+// FindByName 根据名称查询
+func (d *cachedTagDao) FindByName(ctx context.Context, name string) (*Tag, error) {
+	if d.tx {
+		return d.model.FindByName(ctx, name)
+	}
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := cacheNameKey(tenantID, name)
+	if val, ok := d.get(ctx, key); ok {
+		if val == cacheNotFoundPlaceholder {
+			return nil, nil
+		}
+		if id, err := strconv.ParseInt(val, 10, 64); err == nil {
+			result, err := d.FindOne(ctx, id)
+			if err == nil && result.Name == name {
+				return result, nil
+			}
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return nil, err
+			}
+		}
+		// 映射已过期（标签被改名或删除），回源
+		d.del(ctx, key)
+	}
+
+	val, err := d.barrier.Do(key, func() (any, error) {
+		result, err := d.model.FindByName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			d.set(ctx, key, cacheNotFoundPlaceholder, CacheNotFoundExpiry)
+			return nil, nil
+		}
+		d.set(ctx, key, strconv.FormatInt(result.Id, 10), CacheExpiry)
+		return result, nil
+	})
+	if err != nil || val == nil {
+		return nil, err
+	}
+
+	result := *val.(*Tag)
+	return &result, nil
+}
+
+// FindByIds 根据ID批量查询
+// 批量读取按ID缓存的记录，未命中的ID一次回源并回填；不可见的ID不做负缓存
+func (d *cachedTagDao) FindByIds(ctx context.Context, ids []int64) ([]*Tag, error) {
+	if d.tx || len(ids) == 0 {
+		return d.model.FindByIds(ctx, ids)
+	}
+	if _, err := tenant.Require(ctx); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = cacheIdKey(id)
+	}
+	hits, err := d.cache.MGet(ctx, keys...)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("批量读取标签缓存失败: %v", err)
+		hits = nil
+	}
+
+	results := make([]*Tag, 0, len(ids))
+	var missed []int64
+	for i, id := range ids {
+		val, ok := hits[keys[i]]
+		if !ok {
+			missed = append(missed, id)
+			continue
+		}
+		var result Tag
+		if err := json.Unmarshal([]byte(val), &result); err != nil {
+			missed = append(missed, id)
+			continue
+		}
+		if tenant.Visible(ctx, result.TenantId) {
+			results = append(results, &result)
+		}
+	}
+	if len(missed) == 0 {
+		return results, nil
+	}
+
+	loaded, err := d.model.FindByIds(ctx, missed)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range loaded {
+		if data, err := json.Marshal(result); err == nil {
+			d.set(ctx, cacheIdKey(result.Id), string(data), CacheExpiry)
+		}
+	}
+	return append(results, loaded...), nil
+}
+
+// Update 更新记录
+func (d *cachedTagDao) Update(ctx context.Context, data *Tag) error {
+	if err := d.model.Update(ctx, data); err != nil {
+		return err
+	}
+	// 旧名称的映射在读取时校验，这里只需清除新名称的负缓存
+	tenantID, _ := tenant.FromContext(ctx)
+	d.invalidate(ctx, cacheIdKey(data.Id), cacheNameKey(tenantID, data.Name))
+	return nil
+}
+
+// Patch 按列部分更新
+func (d *cachedTagDao) Patch(ctx context.Context, id, version int64, fields map[string]interface{}) error {
+	if err := d.model.Patch(ctx, id, version, fields); err != nil {
+		return err
+	}
+	keys := []string{cacheIdKey(id)}
+	if name, ok := fields["name"].(string); ok {
+		tenantID, _ := tenant.FromContext(ctx)
+		keys = append(keys, cacheNameKey(tenantID, name))
+	}
+	d.invalidate(ctx, keys...)
+	return nil
+}
+
+// Delete 删除记录
+func (d *cachedTagDao) Delete(ctx context.Context, id int64) error {
+	if err := d.model.Delete(ctx, id); err != nil {
+		return err
+	}
+	d.invalidate(ctx, cacheIdKey(id))
+	return nil
+}
+
+// FindAll 查询所有记录
+func (d *cachedTagDao) FindAll(ctx context.Context) ([]*Tag, error) {
+	return d.model.FindAll(ctx)
+}
+
+// List 分页查询
+func (d *cachedTagDao) List(ctx context.Context, page, pageSize int) ([]*Tag, int64, error) {
+	return d.model.List(ctx, page, pageSize)
+}
+
+// Search 关键词搜索
+func (d *cachedTagDao) Search(ctx context.Context, keyword string, page, pageSize int) ([]*Tag, int64, error) {
+	return d.model.Search(ctx, keyword, page, pageSize)
+}
+
+// UpdateStatus 更新状态
+func (d *cachedTagDao) UpdateStatus(ctx context.Context, id int64, status int) error {
+	if err := d.model.UpdateStatus(ctx, id, status); err != nil {
+		return err
+	}
+	d.invalidate(ctx, cacheIdKey(id))
+	return nil
+}
+
+// WithTx 设置事务
+// 事务内读操作不使用缓存；无法感知外部事务的提交时机，写操作立即失效缓存，
+// 并在 cacheTxInvalidateDelay 后再次失效，清除提交前被并发读回填的旧数据
+func (d *cachedTagDao) WithTx(tx interface{}) TagModel {
+	return &cachedTagDao{
+		model:   d.model.WithTx(tx),
+		cache:   d.cache,
+		barrier: d.barrier,
+		tx:      true,
+	}
+}
+
+// Trans 事务处理
+// 事务内的写操作延迟到提交成功后再失效缓存
+func (d *cachedTagDao) Trans(ctx context.Context, fn func(ctx context.Context, model TagModel) error) error {
+	var keys []string
+	err := d.model.Trans(ctx, func(ctx context.Context, model TagModel) error {
+		txModel := &cachedTagDao{
+			model:   model,
+			cache:   d.cache,
+			barrier: d.barrier,
+			tx:      true,
+			pending: &keys,
+		}
+		return fn(ctx, txModel)
+	})
+	if err != nil {
+		return err
+	}
+	d.del(ctx, keys...)
+	return nil
+}
+
+// invalidate 失效缓存，事务内则延迟到提交后
+func (d *cachedTagDao) invalidate(ctx context.Context, keys ...string) {
+	if d.pending != nil {
+		*d.pending = append(*d.pending, keys...)
+		return
+	}
+	d.del(ctx, keys...)
+	if d.tx {
+		ctx = context.WithoutCancel(ctx)
+		time.AfterFunc(cacheTxInvalidateDelay, func() { d.del(ctx, keys...) })
+	}
+}
+
+// get 读取缓存，出错时视为未命中
+func (d *cachedTagDao) get(ctx context.Context, key string) (string, bool) {
+	val, err := d.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, cache.ErrMiss) {
+			logx.WithContext(ctx).Errorf("读取标签缓存失败: key=%s, err=%v", key, err)
+		}
+		return "", false
+	}
+	return val, true
+}
+
+// set 写入缓存，失败只记录日志
+func (d *cachedTagDao) set(ctx context.Context, key, val string, ttl time.Duration) {
+	if err := d.cache.Set(ctx, key, val, ttl); err != nil {
+		logx.WithContext(ctx).Errorf("写入标签缓存失败: key=%s, err=%v", key, err)
+	}
+}
+
+// del 删除缓存，失败只记录日志
+func (d *cachedTagDao) del(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	if err := d.cache.Del(ctx, keys...); err != nil {
+		logx.WithContext(ctx).Errorf("删除标签缓存失败: keys=%v, err=%v", keys, err)
+	}
+}
+
+// cacheIdKey 按ID缓存的key
+func cacheIdKey(id int64) string {
+	return fmt.Sprintf("%s%d", cacheTagIdPrefix, id)
+}
+
+// cacheMissKey 按租户缓存不可见ID的key
+func cacheMissKey(tenantID string, id int64) string {
+	return fmt.Sprintf("%s%s:%d", cacheTagMissPrefix, tenantID, id)
+}
+
+// cacheNameKey 按租户和名称缓存的key
+func cacheNameKey(tenantID, name string) string {
+	return cacheTagNamePrefix + tenantID + ":" + name
+}
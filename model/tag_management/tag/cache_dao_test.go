@@ -0,0 +1,281 @@
+package tag
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"idrm/pkg/cache"
+	"idrm/pkg/tenant"
+)
+
+// countingTagModel 统计回源次数的TagModel
+type countingTagModel struct {
+	TagModel
+	findOneCalls atomic.Int32
+	delay        time.Duration
+	loaded       [][]int64
+}
+
+func (m *countingTagModel) FindOne(ctx context.Context, id int64) (*Tag, error) {
+	m.findOneCalls.Add(1)
+	time.Sleep(m.delay)
+	return m.TagModel.FindOne(ctx, id)
+}
+
+func (m *countingTagModel) FindByIds(ctx context.Context, ids []int64) ([]*Tag, error) {
+	m.loaded = append(m.loaded, ids)
+	return m.TagModel.FindByIds(ctx, ids)
+}
+
+// setupCachedDao 创建带内存缓存的dao
+func setupCachedDao(t *testing.T) (*tagDao, *countingTagModel, TagModel) {
+	db := setupTestDB(t)
+	dao := &tagDao{db: db}
+	counting := &countingTagModel{TagModel: dao}
+	return dao, counting, newCachedTagDao(counting, cache.NewMemoryCache())
+}
+
+// TestCachedTagDao_FindOne 测试按ID读穿缓存
+func TestCachedTagDao_FindOne(t *testing.T) {
+	dao, counting, cached := setupCachedDao(t)
+	ctx := tenant.WithTenant(context.Background(), "t1")
+
+	tag := &Tag{Name: "缓存标签", Status: StatusEnabled, CreatedBy: 1}
+	dao.Insert(ctx, tag)
+
+	for i := 0; i < 3; i++ {
+		result, err := cached.FindOne(ctx, tag.Id)
+		if err != nil {
+			t.Fatalf("查询失败: %v", err)
+		}
+		if result.Name != "缓存标签" {
+			t.Errorf("期望名称=缓存标签, 实际=%s", result.Name)
+		}
+	}
+
+	if calls := counting.findOneCalls.Load(); calls != 1 {
+		t.Errorf("期望回源1次, 实际=%d", calls)
+	}
+}
+
+// TestCachedTagDao_NotFound 测试负缓存
+func TestCachedTagDao_NotFound(t *testing.T) {
+	dao, counting, cached := setupCachedDao(t)
+	ctx := tenant.WithTenant(context.Background(), "t1")
+
+	if _, err := cached.FindOne(ctx, 1); err != ErrNotFound {
+		t.Fatalf("期望ErrNotFound, 实际=%v", err)
+	}
+
+	// 绕过缓存直接写库，负缓存仍然生效
+	dao.Insert(ctx, &Tag{Name: "绕过缓存", Status: StatusEnabled, CreatedBy: 1})
+	if _, err := cached.FindOne(ctx, 1); err != ErrNotFound {
+		t.Errorf("期望命中负缓存, 实际=%v", err)
+	}
+	if calls := counting.findOneCalls.Load(); calls != 1 {
+		t.Errorf("期望回源1次, 实际=%d", calls)
+	}
+
+	// 通过装饰器写入会清除负缓存
+	name, _ := cached.FindByName(ctx, "新标签")
+	if name != nil {
+		t.Fatal("新标签不应存在")
+	}
+	created, err := cached.Insert(ctx, &Tag{Name: "新标签", Status: StatusEnabled, CreatedBy: 1})
+	if err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+	result, err := cached.FindByName(ctx, "新标签")
+	if err != nil || result == nil {
+		t.Fatalf("插入后应能查到, err=%v", err)
+	}
+	if result.Id != created.Id {
+		t.Errorf("期望ID=%d, 实际=%d", created.Id, result.Id)
+	}
+}
+
+// TestCachedTagDao_Invalidate 测试写操作失效缓存
+func TestCachedTagDao_Invalidate(t *testing.T) {
+	_, _, cached := setupCachedDao(t)
+	ctx := tenant.WithTenant(context.Background(), "t1")
+
+	tag, _ := cached.Insert(ctx, &Tag{Name: "旧名称", Status: StatusEnabled, CreatedBy: 1})
+	cached.FindOne(ctx, tag.Id)
+	cached.FindByName(ctx, "旧名称")
+
+	// 改名后旧名称映射不再命中
+	tag.Name = "新名称"
+	if err := cached.Update(ctx, tag); err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+	if result, _ := cached.FindByName(ctx, "旧名称"); result != nil {
+		t.Error("旧名称不应再查到标签")
+	}
+	result, _ := cached.FindOne(ctx, tag.Id)
+	if result.Name != "新名称" {
+		t.Errorf("期望名称=新名称, 实际=%s", result.Name)
+	}
+
+	// 状态更新
+	cached.UpdateStatus(ctx, tag.Id, StatusDisabled)
+	result, _ = cached.FindOne(ctx, tag.Id)
+	if result.Status != StatusDisabled {
+		t.Errorf("期望状态=%d, 实际=%d", StatusDisabled, result.Status)
+	}
+
+	// 部分更新
+	if err := cached.Patch(ctx, tag.Id, result.Version, map[string]interface{}{"description": "已归档"}); err != nil {
+		t.Fatalf("部分更新失败: %v", err)
+	}
+	result, _ = cached.FindOne(ctx, tag.Id)
+	if result.Description != "已归档" {
+		t.Errorf("期望描述=已归档, 实际=%s", result.Description)
+	}
+
+	// 删除
+	cached.Delete(ctx, tag.Id)
+	if _, err := cached.FindOne(ctx, tag.Id); err != ErrNotFound {
+		t.Errorf("期望ErrNotFound, 实际=%v", err)
+	}
+	if result, _ := cached.FindByName(ctx, "新名称"); result != nil {
+		t.Error("删除后不应再按名称查到标签")
+	}
+}
+
+// TestCachedTagDao_Trans 测试事务提交后才失效缓存
+func TestCachedTagDao_Trans(t *testing.T) {
+	_, _, cached := setupCachedDao(t)
+	ctx := tenant.WithTenant(context.Background(), "t1")
+
+	tag, _ := cached.Insert(ctx, &Tag{Name: "事务标签", Status: StatusEnabled, CreatedBy: 1})
+	cached.FindOne(ctx, tag.Id)
+
+	err := cached.Trans(ctx, func(ctx context.Context, model TagModel) error {
+		return model.UpdateStatus(ctx, tag.Id, StatusDisabled)
+	})
+	if err != nil {
+		t.Fatalf("事务执行失败: %v", err)
+	}
+
+	result, _ := cached.FindOne(ctx, tag.Id)
+	if result.Status != StatusDisabled {
+		t.Errorf("期望状态=%d, 实际=%d", StatusDisabled, result.Status)
+	}
+}
+
+// TestCachedTagDao_TransRollback 测试事务内读取的未提交数据不进入缓存
+func TestCachedTagDao_TransRollback(t *testing.T) {
+	_, _, cached := setupCachedDao(t)
+	ctx := tenant.WithTenant(context.Background(), "t1")
+
+	tag, _ := cached.Insert(ctx, &Tag{Name: "回滚标签", Status: StatusEnabled, CreatedBy: 1})
+
+	rollback := errors.New("rollback")
+	err := cached.Trans(ctx, func(ctx context.Context, model TagModel) error {
+		if err := model.UpdateStatus(ctx, tag.Id, StatusDisabled); err != nil {
+			return err
+		}
+		result, err := model.FindOne(ctx, tag.Id)
+		if err != nil {
+			return err
+		}
+		if result.Status != StatusDisabled {
+			t.Errorf("事务内期望状态=%d, 实际=%d", StatusDisabled, result.Status)
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatalf("期望事务回滚, 实际=%v", err)
+	}
+
+	result, _ := cached.FindOne(ctx, tag.Id)
+	if result.Status != StatusEnabled {
+		t.Errorf("回滚后期望状态=%d, 实际=%d", StatusEnabled, result.Status)
+	}
+}
+
+// TestCachedTagDao_SingleFlight 测试并发回源合并
+func TestCachedTagDao_SingleFlight(t *testing.T) {
+	dao, counting, cached := setupCachedDao(t)
+	counting.delay = 50 * time.Millisecond
+	ctx := tenant.WithTenant(context.Background(), "t1")
+
+	tag := &Tag{Name: "热点标签", Status: StatusEnabled, CreatedBy: 1}
+	dao.Insert(ctx, tag)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cached.FindOne(ctx, tag.Id); err != nil {
+				t.Errorf("查询失败: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls := counting.findOneCalls.Load(); calls != 1 {
+		t.Errorf("期望回源1次, 实际=%d", calls)
+	}
+}
+
+// TestCachedTagDao_TenantIsolation 测试缓存不会跨租户泄露
+func TestCachedTagDao_TenantIsolation(t *testing.T) {
+	_, _, cached := setupCachedDao(t)
+	ctx1 := tenant.WithTenant(context.Background(), "t1")
+	ctx2 := tenant.WithTenant(context.Background(), "t2")
+
+	created, err := cached.Insert(ctx1, &Tag{Name: "私有", Status: StatusEnabled, CreatedBy: 1})
+	if err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+	// t1 读取后记录已进入缓存
+	if _, err := cached.FindOne(ctx1, created.Id); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if _, err := cached.FindOne(ctx2, created.Id); err != ErrNotFound {
+		t.Errorf("其他租户不应命中缓存, 实际=%v", err)
+	}
+	if result, _ := cached.FindByName(ctx2, "私有"); result != nil {
+		t.Errorf("其他租户按名称不应查到, 实际=%v", result)
+	}
+
+	// t2 的负缓存不影响 t1
+	if _, err := cached.FindOne(ctx1, created.Id); err != nil {
+		t.Errorf("本租户查询失败: %v", err)
+	}
+}
+
+// TestCachedTagDao_FindByIds 测试批量查询只回源未命中的ID
+func TestCachedTagDao_FindByIds(t *testing.T) {
+	dao, counting, cached := setupCachedDao(t)
+	ctx1 := tenant.WithTenant(context.Background(), "t1")
+	ctx2 := tenant.WithTenant(context.Background(), "t2")
+
+	tag1 := &Tag{Name: "标签1", Status: StatusEnabled, CreatedBy: 1}
+	tag2 := &Tag{Name: "标签2", Status: StatusEnabled, CreatedBy: 1}
+	dao.Insert(ctx1, tag1)
+	dao.Insert(ctx1, tag2)
+	cached.FindOne(ctx1, tag1.Id)
+
+	results, err := cached.FindByIds(ctx1, []int64{tag1.Id, tag2.Id, 999})
+	if err != nil {
+		t.Fatalf("批量查询失败: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("期望2条记录, 实际=%d", len(results))
+	}
+
+	// 缓存中的记录同样校验租户可见性
+	if results, _ := cached.FindByIds(ctx2, []int64{tag1.Id, tag2.Id}); len(results) != 0 {
+		t.Errorf("其他租户不应查到, 实际=%v", results)
+	}
+	if len(counting.loaded) != 1 || len(counting.loaded[0]) != 2 || counting.loaded[0][0] != tag2.Id {
+		t.Errorf("期望只回源未命中的ID, 实际=%v", counting.loaded)
+	}
+}
@@ -1,11 +1,16 @@
 package tag
 
 import (
+	"idrm/pkg/cache"
+
 	"gorm.io/gorm"
 )
 
 var (
-	gormFactory func(db *gorm.DB) TagModel
+	gormFactory    func(db *gorm.DB) TagModel
+	cacheFactory   func(model TagModel, c cache.Cache) TagModel
+	metricsFactory func(model TagModel) TagModel
+	auditFactory   func(model, source TagModel) TagModel
 )
 
 // RegisterGormFactory 注册GORM工厂函数
@@ -13,6 +18,21 @@ func RegisterGormFactory(fn func(db *gorm.DB) TagModel) {
 	gormFactory = fn
 }
 
+// RegisterCacheFactory 注册缓存装饰器工厂函数
+func RegisterCacheFactory(fn func(model TagModel, c cache.Cache) TagModel) {
+	cacheFactory = fn
+}
+
+// RegisterMetricsFactory 注册指标装饰器工厂函数
+func RegisterMetricsFactory(fn func(model TagModel) TagModel) {
+	metricsFactory = fn
+}
+
+// RegisterAuditFactory 注册审计装饰器工厂函数
+func RegisterAuditFactory(fn func(model, source TagModel) TagModel) {
+	auditFactory = fn
+}
+
 // NewTagModel 创建TagModel实例
 func NewTagModel(db *gorm.DB) TagModel {
 	if gormFactory != nil {
@@ -20,3 +40,30 @@ func NewTagModel(db *gorm.DB) TagModel {
 	}
 	return nil
 }
+
+// NewCachedTagModel 创建带缓存的TagModel实例，未提供缓存时退化为 NewTagModel
+func NewCachedTagModel(db *gorm.DB, c cache.Cache) TagModel {
+	model := NewTagModel(db)
+	if model == nil || c == nil || cacheFactory == nil {
+		return model
+	}
+	return cacheFactory(model, c)
+}
+
+// NewInstrumentedTagModel 为TagModel添加指标装饰器，记录各方法的调用耗时
+func NewInstrumentedTagModel(model TagModel) TagModel {
+	if model == nil || metricsFactory == nil {
+		return model
+	}
+	return metricsFactory(model)
+}
+
+// NewAuditedTagModel 为TagModel添加审计装饰器，写操作记录审计日志
+// 变更前后的快照直接从 db 的主库读取，不经过 model 上的缓存
+func NewAuditedTagModel(model TagModel, db *gorm.DB) TagModel {
+	source := NewTagModel(db)
+	if model == nil || source == nil || auditFactory == nil {
+		return model
+	}
+	return auditFactory(model, source)
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"fmt"
 
+	"idrm/model/tag_management/history"
+	"idrm/pkg/db"
+	"idrm/pkg/tenant"
+
 	"gorm.io/gorm"
 )
 
@@ -12,6 +16,7 @@ type tagDao struct {
 }
 
 func init() {
+	tenant.RegisterTable(Tag{}.TableName(), tenant.Shareable)
 	RegisterGormFactory(newTagDao)
 }
 
@@ -20,10 +25,23 @@ func newTagDao(db *gorm.DB) TagModel {
 	return &tagDao{db: db}
 }
 
-// Insert 插入新记录
+// Insert 插入新记录，归属当前租户
 func (d *tagDao) Insert(ctx context.Context, data *Tag) (*Tag, error) {
-	if err := d.db.WithContext(ctx).Create(data).Error; err != nil {
-		return nil, fmt.Errorf("插入标签失败: %w", err)
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data.TenantId = tenantID
+	data.Version = 1
+
+	err = d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(data).Error; err != nil {
+			return fmt.Errorf("插入标签失败: %w", err)
+		}
+		return recordHistory(ctx, tx, history.ActionCreate, data)
+	})
+	if err != nil {
+		return nil, err
 	}
 	return data, nil
 }
@@ -58,27 +76,112 @@ func (d *tagDao) FindByName(ctx context.Context, name string) (*Tag, error) {
 	return &result, nil
 }
 
-// Update 更新记录
-func (d *tagDao) Update(ctx context.Context, data *Tag) error {
+// FindByIds 根据ID批量查询
+func (d *tagDao) FindByIds(ctx context.Context, ids []int64) ([]*Tag, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var results []*Tag
 	err := d.db.WithContext(ctx).
-		Model(&Tag{}).
-		Where("id = ?", data.Id).
-		Updates(data).Error
+		Where("id IN ?", ids).
+		Find(&results).Error
 	if err != nil {
-		return fmt.Errorf("更新标签失败: %w", err)
+		return nil, fmt.Errorf("批量查询标签失败: %w", err)
 	}
-	return nil
+	return results, nil
 }
 
-// Delete 删除记录
-func (d *tagDao) Delete(ctx context.Context, id int64) error {
-	err := d.db.WithContext(ctx).
+// Update 更新记录
+// 乐观锁：仅当数据库中的版本号等于 data.Version 时更新，成功后 data.Version 递增
+func (d *tagDao) Update(ctx context.Context, data *Tag) error {
+	expected := data.Version
+	data.Version = expected + 1
+
+	updated := false
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Tag{}).
+			Where("id = ? AND version = ?", data.Id, expected).
+			Omit(tenant.Column).
+			Updates(data)
+		if result.Error != nil {
+			return fmt.Errorf("更新标签失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return checkConflict(ctx, tx, data.Id)
+		}
+		updated = true
+		return recordSnapshot(ctx, tx, history.ActionUpdate, data.Id)
+	})
+	if err != nil || !updated {
+		data.Version = expected
+	}
+	return err
+}
+
+// Patch 按列部分更新
+// 使用 map 写入，空字符串和 0 等零值不会被跳过；乐观锁语义同 Update
+func (d *tagDao) Patch(ctx context.Context, id, version int64, fields map[string]interface{}) error {
+	values := make(map[string]interface{}, len(fields)+1)
+	for column, value := range fields {
+		if !PatchableColumns[column] {
+			return fmt.Errorf("%w: %s", ErrFieldNotPatchable, column)
+		}
+		values[column] = value
+	}
+	values["version"] = gorm.Expr("version + 1")
+
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Tag{}).
+			Where("id = ? AND version = ?", id, version).
+			Updates(values)
+		if result.Error != nil {
+			return fmt.Errorf("更新标签失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return checkConflict(ctx, tx, id)
+		}
+		return recordSnapshot(ctx, tx, history.ActionUpdate, id)
+	})
+}
+
+// checkConflict 带版本条件的更新未命中时，区分版本冲突与记录不存在
+// 共享标签对其他租户只读，仅统计当前租户自己的记录，其余情况均视为记录不存在
+func checkConflict(ctx context.Context, tx *gorm.DB, id int64) error {
+	var count int64
+	err := tx.Model(&Tag{}).
+		Scopes(tenant.WriteScope(ctx)).
 		Where("id = ?", id).
-		Delete(&Tag{}).Error
+		Count(&count).Error
 	if err != nil {
-		return fmt.Errorf("删除标签失败: %w", err)
+		return fmt.Errorf("更新标签失败: %w", err)
 	}
-	return nil
+	if count > 0 {
+		return ErrVersionConflict
+	}
+	return ErrNotFound
+}
+
+// Delete 删除记录，历史中保留删除前的快照
+func (d *tagDao) Delete(ctx context.Context, id int64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing Tag
+		err := tx.Scopes(tenant.WriteScope(ctx)).
+			Where("id = ?", id).
+			Limit(1).
+			Find(&existing).Error
+		if err != nil {
+			return fmt.Errorf("删除标签失败: %w", err)
+		}
+		if existing.Id == 0 {
+			return nil
+		}
+
+		if err := tx.Where("id = ?", id).Delete(&Tag{}).Error; err != nil {
+			return fmt.Errorf("删除标签失败: %w", err)
+		}
+		return recordHistory(ctx, tx, history.ActionDelete, &existing)
+	})
 }
 
 // FindAll 查询所有记录
@@ -127,8 +230,8 @@ func (d *tagDao) Search(ctx context.Context, keyword string, page, pageSize int)
 	query := d.db.WithContext(ctx).Model(&Tag{})
 
 	if keyword != "" {
-		pattern := "%" + keyword + "%"
-		query = query.Where("name LIKE ? OR description LIKE ?", pattern, pattern)
+		pattern := db.ContainsPattern(keyword)
+		query = query.Where(db.LikeCondition(d.db, "name", "description"), pattern, pattern)
 	}
 
 	// 查询总数
@@ -151,14 +254,21 @@ func (d *tagDao) Search(ctx context.Context, keyword string, page, pageSize int)
 
 // UpdateStatus 更新状态
 func (d *tagDao) UpdateStatus(ctx context.Context, id int64, status int) error {
-	err := d.db.WithContext(ctx).
-		Model(&Tag{}).
-		Where("id = ?", id).
-		Update("status", status).Error
-	if err != nil {
-		return fmt.Errorf("更新标签状态失败: %w", err)
-	}
-	return nil
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Tag{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"status":  status,
+				"version": gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return fmt.Errorf("更新标签状态失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return recordSnapshot(ctx, tx, history.ActionStatus, id)
+	})
 }
 
 // WithTx 设置事务
@@ -181,3 +291,26 @@ func (d *tagDao) Trans(ctx context.Context, fn func(ctx context.Context, model T
 	}
 	return nil
 }
+
+// recordSnapshot 读取更新后的记录并写入变更历史
+func recordSnapshot(ctx context.Context, tx *gorm.DB, action string, id int64) error {
+	var current Tag
+	if err := tx.Where("id = ?", id).First(&current).Error; err != nil {
+		return fmt.Errorf("读取标签快照失败: %w", err)
+	}
+	return recordHistory(ctx, tx, action, &current)
+}
+
+// recordHistory 在同一事务内记录标签变更历史
+func recordHistory(ctx context.Context, tx *gorm.DB, action string, t *Tag) error {
+	return history.NewHistoryModel(tx).RecordTag(ctx, &history.TagHistory{
+		TenantId:    t.TenantId,
+		TagId:       t.Id,
+		Action:      action,
+		Version:     t.Version,
+		Name:        t.Name,
+		Description: t.Description,
+		Color:       t.Color,
+		Status:      t.Status,
+	})
+}
@@ -2,11 +2,15 @@ package tag
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+
+	"idrm/model/tag_management/history"
+	"idrm/pkg/tenant"
 )
 
 // setupTestDB 创建测试数据库
@@ -17,10 +21,13 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	}
 
 	// 自动迁移
-	err = db.AutoMigrate(&Tag{})
+	err = db.AutoMigrate(&Tag{}, &history.TagHistory{})
 	if err != nil {
 		t.Fatalf("数据库迁移失败: %v", err)
 	}
+	if err := db.Use(tenant.NewPlugin()); err != nil {
+		t.Fatalf("注册租户插件失败: %v", err)
+	}
 
 	return db
 }
@@ -30,7 +37,7 @@ func TestTagDao_Insert(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &tagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	tag := &Tag{
 		Name:        "测试标签",
 		Description: "测试描述",
@@ -57,7 +64,7 @@ func TestTagDao_FindOne(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &tagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	// 先插入
 	tag := &Tag{Name: "测试标签", Status: StatusEnabled, CreatedBy: 1}
 	dao.Insert(ctx, tag)
@@ -78,7 +85,7 @@ func TestTagDao_FindByName(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &tagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	tag := &Tag{Name: "唯一标签", Status: StatusEnabled, CreatedBy: 1}
 	dao.Insert(ctx, tag)
 
@@ -106,7 +113,7 @@ func TestTagDao_Update(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &tagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	tag := &Tag{Name: "原始名称", Status: StatusEnabled, CreatedBy: 1}
 	dao.Insert(ctx, tag)
 
@@ -130,7 +137,7 @@ func TestTagDao_Delete(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &tagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	tag := &Tag{Name: "待删除", Status: StatusEnabled, CreatedBy: 1}
 	dao.Insert(ctx, tag)
 
@@ -152,7 +159,7 @@ func TestTagDao_List(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &tagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	// 插入测试数据
 	for i := 1; i <= 25; i++ {
 		tag := &Tag{
@@ -181,7 +188,7 @@ func TestTagDao_Search(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &tagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	// 插入测试数据
 	dao.Insert(ctx, &Tag{Name: "红色标签", Description: "红色", Status: StatusEnabled, CreatedBy: 1})
 	dao.Insert(ctx, &Tag{Name: "蓝色标签", Description: "蓝色", Status: StatusEnabled, CreatedBy: 1})
@@ -205,7 +212,7 @@ func TestTagDao_Trans(t *testing.T) {
 	db := setupTestDB(t)
 	dao := &tagDao{db: db}
 
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 
 	// 测试事务成功
 	err := dao.Trans(ctx, func(ctx context.Context, model TagModel) error {
@@ -230,3 +237,203 @@ func TestTagDao_Trans(t *testing.T) {
 		t.Errorf("期望插入2条, 实际=%d", len(results))
 	}
 }
+
+// TestTagDao_TenantIsolation 测试租户隔离与共享标签
+func TestTagDao_TenantIsolation(t *testing.T) {
+	db := setupTestDB(t)
+	dao := &tagDao{db: db}
+
+	ctx1 := tenant.WithTenant(context.Background(), "t1")
+	ctx2 := tenant.WithTenant(context.Background(), "t2")
+	sharedCtx := tenant.WithTenant(context.Background(), tenant.Shared)
+
+	// 不同租户可以使用相同名称
+	own, err := dao.Insert(ctx1, &Tag{Name: "财务", Status: StatusEnabled, CreatedBy: 1})
+	if err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+	other, err := dao.Insert(ctx2, &Tag{Name: "财务", Status: StatusEnabled, CreatedBy: 2})
+	if err != nil {
+		t.Fatalf("其他租户插入同名标签失败: %v", err)
+	}
+	shared, err := dao.Insert(sharedCtx, &Tag{Name: "公共", Status: StatusEnabled, CreatedBy: 3})
+	if err != nil {
+		t.Fatalf("插入共享标签失败: %v", err)
+	}
+	if own.TenantId != "t1" || shared.TenantId != tenant.Shared {
+		t.Errorf("租户ID应取自上下文, 实际=%s,%s", own.TenantId, shared.TenantId)
+	}
+
+	// 读取：本租户 + 共享
+	if _, err := dao.FindOne(ctx1, other.Id); err != ErrNotFound {
+		t.Errorf("不应读取其他租户的标签, 实际=%v", err)
+	}
+	if _, err := dao.FindOne(ctx1, shared.Id); err != nil {
+		t.Errorf("应可读取共享标签: %v", err)
+	}
+	found, _ := dao.FindByName(ctx1, "财务")
+	if found == nil || found.Id != own.Id {
+		t.Errorf("按名称应查到本租户标签, 实际=%v", found)
+	}
+	_, total, _ := dao.List(ctx1, 1, 10)
+	if total != 2 {
+		t.Errorf("期望可见2个标签, 实际=%d", total)
+	}
+
+	// 写入：仅限本租户，且不能修改归属
+	dao.Update(ctx1, &Tag{Id: shared.Id, Name: "篡改"})
+	dao.UpdateStatus(ctx1, other.Id, StatusDisabled)
+	dao.Delete(ctx1, other.Id)
+	dao.Update(ctx1, &Tag{Id: own.Id, TenantId: "t2", Name: "财务部", Version: own.Version})
+
+	if result, _ := dao.FindOne(sharedCtx, shared.Id); result.Name != "公共" {
+		t.Errorf("租户不应修改共享标签, 实际=%s", result.Name)
+	}
+	if result, err := dao.FindOne(ctx2, other.Id); err != nil || result.Status != StatusEnabled {
+		t.Errorf("租户不应修改其他租户的标签, err=%v", err)
+	}
+	if result, _ := dao.FindOne(ctx1, own.Id); result.TenantId != "t1" || result.Name != "财务部" {
+		t.Errorf("更新不应改变租户归属, 实际=%+v", result)
+	}
+
+	// 缺少租户信息时拒绝访问
+	if _, err := dao.FindOne(context.Background(), own.Id); !errors.Is(err, tenant.ErrMissingTenant) {
+		t.Errorf("期望 ErrMissingTenant, 实际=%v", err)
+	}
+	if _, err := dao.Insert(context.Background(), &Tag{Name: "无租户"}); !errors.Is(err, tenant.ErrMissingTenant) {
+		t.Errorf("期望 ErrMissingTenant, 实际=%v", err)
+	}
+}
+
+// TestTagDao_UpdateVersionConflict 测试乐观锁
+func TestTagDao_UpdateVersionConflict(t *testing.T) {
+	db := setupTestDB(t)
+	dao := &tagDao{db: db}
+	ctx := tenant.WithTenant(context.Background(), "t1")
+
+	created, _ := dao.Insert(ctx, &Tag{Name: "并发编辑", Status: StatusEnabled, CreatedBy: 1})
+	if created.Version != 1 {
+		t.Fatalf("新建标签版本应为1, 实际=%d", created.Version)
+	}
+
+	// 两个管理员读取同一版本
+	first, _ := dao.FindOne(ctx, created.Id)
+	second, _ := dao.FindOne(ctx, created.Id)
+
+	first.Description = "管理员A"
+	if err := dao.Update(ctx, first); err != nil {
+		t.Fatalf("第一次更新失败: %v", err)
+	}
+	if first.Version != 2 {
+		t.Errorf("更新后版本应为2, 实际=%d", first.Version)
+	}
+
+	second.Description = "管理员B"
+	if err := dao.Update(ctx, second); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("期望 ErrVersionConflict, 实际=%v", err)
+	}
+	if second.Version != 1 {
+		t.Errorf("冲突时不应修改调用方的版本号, 实际=%d", second.Version)
+	}
+
+	// 状态变更同样推进版本
+	if err := dao.UpdateStatus(ctx, created.Id, StatusDisabled); err != nil {
+		t.Fatalf("更新状态失败: %v", err)
+	}
+	result, _ := dao.FindOne(ctx, created.Id)
+	if result.Description != "管理员A" || result.Version != 3 {
+		t.Errorf("期望描述=管理员A 版本=3, 实际=%s,%d", result.Description, result.Version)
+	}
+
+	// 记录已被删除时不应报告成功
+	dao.Delete(ctx, created.Id)
+	if err := dao.Update(ctx, result); !errors.Is(err, ErrNotFound) {
+		t.Errorf("期望 ErrNotFound, 实际=%v", err)
+	}
+	if err := dao.Patch(ctx, created.Id, result.Version, map[string]interface{}{"description": "已删除"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("期望 ErrNotFound, 实际=%v", err)
+	}
+}
+
+// TestTagDao_Patch 测试部分更新
+func TestTagDao_Patch(t *testing.T) {
+	db := setupTestDB(t)
+	dao := &tagDao{db: db}
+	ctx := tenant.WithTenant(context.Background(), "t1")
+
+	created, _ := dao.Insert(ctx, &Tag{Name: "部分更新", Description: "原描述", Color: "#52c41a", Status: StatusEnabled, CreatedBy: 1})
+
+	// 零值同样写入，未出现的列保持不变
+	err := dao.Patch(ctx, created.Id, created.Version, map[string]interface{}{
+		"description": "",
+		"status":      StatusDisabled,
+	})
+	if err != nil {
+		t.Fatalf("部分更新失败: %v", err)
+	}
+	result, _ := dao.FindOne(ctx, created.Id)
+	if result.Description != "" || result.Status != StatusDisabled {
+		t.Errorf("期望描述为空且状态=%d, 实际=%q,%d", StatusDisabled, result.Description, result.Status)
+	}
+	if result.Name != "部分更新" || result.Color != "#52c41a" {
+		t.Errorf("未修改的列不应变化, 实际=%s,%s", result.Name, result.Color)
+	}
+	if result.Version != created.Version+1 {
+		t.Errorf("期望版本=%d, 实际=%d", created.Version+1, result.Version)
+	}
+
+	// 旧版本号
+	err = dao.Patch(ctx, created.Id, created.Version, map[string]interface{}{"name": "冲突"})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("期望 ErrVersionConflict, 实际=%v", err)
+	}
+
+	// 不允许修改的列
+	err = dao.Patch(ctx, created.Id, result.Version, map[string]interface{}{"tenant_id": "t2"})
+	if !errors.Is(err, ErrFieldNotPatchable) {
+		t.Errorf("期望 ErrFieldNotPatchable, 实际=%v", err)
+	}
+}
+
+// TestTagDao_History 测试每次变更都记录标签快照
+func TestTagDao_History(t *testing.T) {
+	db := setupTestDB(t)
+	dao := &tagDao{db: db}
+	ctx := tenant.WithTenant(context.Background(), "t1")
+
+	created, _ := dao.Insert(ctx, &Tag{Name: "历史", Status: StatusEnabled, CreatedBy: 1})
+	created.Name = "历史改名"
+	dao.Update(ctx, created)
+	dao.Patch(ctx, created.Id, created.Version, map[string]interface{}{"description": "备注"})
+	dao.UpdateStatus(ctx, created.Id, StatusDisabled)
+	dao.Delete(ctx, created.Id)
+	// 不存在的记录不产生历史
+	dao.UpdateStatus(ctx, created.Id, StatusEnabled)
+
+	var entries []*history.TagHistory
+	db.WithContext(ctx).Where("tag_id = ?", created.Id).Order("id").Find(&entries)
+
+	expected := []struct {
+		action  string
+		version int64
+		name    string
+	}{
+		{history.ActionCreate, 1, "历史"},
+		{history.ActionUpdate, 2, "历史改名"},
+		{history.ActionUpdate, 3, "历史改名"},
+		{history.ActionStatus, 4, "历史改名"},
+		{history.ActionDelete, 4, "历史改名"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("期望%d条历史, 实际=%d", len(expected), len(entries))
+	}
+	for i, e := range expected {
+		got := entries[i]
+		if got.Action != e.action || got.Version != e.version || got.Name != e.name || got.TenantId != "t1" {
+			t.Errorf("第%d条历史不符合预期: %+v", i, got)
+		}
+	}
+	if entries[4].Description != "备注" || entries[4].Status != StatusDisabled {
+		t.Errorf("删除快照应为删除前的数据, 实际=%+v", entries[4])
+	}
+}
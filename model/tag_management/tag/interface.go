@@ -13,9 +13,15 @@ type TagModel interface {
 	// FindByName 根据名称查询
 	FindByName(ctx context.Context, name string) (*Tag, error)
 
-	// Update 更新记录
+	// FindByIds 根据ID批量查询，不存在或当前租户不可见的ID不返回
+	FindByIds(ctx context.Context, ids []int64) ([]*Tag, error)
+
+	// Update 更新记录，版本不一致时返回 ErrVersionConflict，记录不存在时返回 ErrNotFound
 	Update(ctx context.Context, data *Tag) error
 
+	// Patch 按列部分更新，fields 的键为列名，零值同样写入；错误语义同 Update
+	Patch(ctx context.Context, id, version int64, fields map[string]interface{}) error
+
 	// Delete 删除记录
 	Delete(ctx context.Context, id int64) error
 
@@ -0,0 +1,114 @@
+package tag
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"idrm/pkg/telemetry/metrics"
+)
+
+// metricsModelName 指标中的模型名称
+const metricsModelName = "tag"
+
+// metricsTagDao 记录调用耗时的TagModel装饰器
+type metricsTagDao struct {
+	model TagModel
+}
+
+func init() {
+	RegisterMetricsFactory(newMetricsTagDao)
+}
+
+// newMetricsTagDao 创建metricsTagDao实例
+func newMetricsTagDao(model TagModel) TagModel {
+	return &metricsTagDao{model: model}
+}
+
+// Insert 插入新记录
+func (d *metricsTagDao) Insert(ctx context.Context, data *Tag) (result *Tag, err error) {
+	defer d.observe("Insert", time.Now(), &err)
+	return d.model.Insert(ctx, data)
+}
+
+// FindOne 根据ID查询
+func (d *metricsTagDao) FindOne(ctx context.Context, id int64) (result *Tag, err error) {
+	defer d.observe("FindOne", time.Now(), &err)
+	return d.model.FindOne(ctx, id)
+}
+
+// FindByName 根据名称查询
+func (d *metricsTagDao) FindByName(ctx context.Context, name string) (result *Tag, err error) {
+	defer d.observe("FindByName", time.Now(), &err)
+	return d.model.FindByName(ctx, name)
+}
+
+// FindByIds 根据ID批量查询
+func (d *metricsTagDao) FindByIds(ctx context.Context, ids []int64) (result []*Tag, err error) {
+	defer d.observe("FindByIds", time.Now(), &err)
+	return d.model.FindByIds(ctx, ids)
+}
+
+// Update 更新记录
+func (d *metricsTagDao) Update(ctx context.Context, data *Tag) (err error) {
+	defer d.observe("Update", time.Now(), &err)
+	return d.model.Update(ctx, data)
+}
+
+// Patch 按列部分更新
+func (d *metricsTagDao) Patch(ctx context.Context, id, version int64, fields map[string]interface{}) (err error) {
+	defer d.observe("Patch", time.Now(), &err)
+	return d.model.Patch(ctx, id, version, fields)
+}
+
+// Delete 删除记录
+func (d *metricsTagDao) Delete(ctx context.Context, id int64) (err error) {
+	defer d.observe("Delete", time.Now(), &err)
+	return d.model.Delete(ctx, id)
+}
+
+// FindAll 查询所有记录
+func (d *metricsTagDao) FindAll(ctx context.Context) (result []*Tag, err error) {
+	defer d.observe("FindAll", time.Now(), &err)
+	return d.model.FindAll(ctx)
+}
+
+// List 分页查询
+func (d *metricsTagDao) List(ctx context.Context, page, pageSize int) (result []*Tag, total int64, err error) {
+	defer d.observe("List", time.Now(), &err)
+	return d.model.List(ctx, page, pageSize)
+}
+
+// Search 关键词搜索
+func (d *metricsTagDao) Search(ctx context.Context, keyword string, page, pageSize int) (result []*Tag, total int64, err error) {
+	defer d.observe("Search", time.Now(), &err)
+	return d.model.Search(ctx, keyword, page, pageSize)
+}
+
+// UpdateStatus 更新状态
+func (d *metricsTagDao) UpdateStatus(ctx context.Context, id int64, status int) (err error) {
+	defer d.observe("UpdateStatus", time.Now(), &err)
+	return d.model.UpdateStatus(ctx, id, status)
+}
+
+// WithTx 设置事务
+func (d *metricsTagDao) WithTx(tx interface{}) TagModel {
+	return &metricsTagDao{model: d.model.WithTx(tx)}
+}
+
+// Trans 事务处理，事务内的调用同样记录耗时
+func (d *metricsTagDao) Trans(ctx context.Context, fn func(ctx context.Context, model TagModel) error) (err error) {
+	defer d.observe("Trans", time.Now(), &err)
+	return d.model.Trans(ctx, func(ctx context.Context, model TagModel) error {
+		return fn(ctx, &metricsTagDao{model: model})
+	})
+}
+
+// observe 记录方法耗时，记录不存在不计为失败
+func (d *metricsTagDao) observe(method string, start time.Time, err *error) {
+	e := *err
+	if errors.Is(e, ErrNotFound) {
+		e = nil
+	}
+	metrics.ObserveDAO(metricsModelName, method, start, e)
+}
@@ -1,6 +1,9 @@
 package tag
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 // 常量定义
 const (
@@ -12,15 +15,39 @@ const (
 	StatusEnabled  = 1 // 启用
 )
 
+// PatchableColumns 允许通过 Patch 修改的列
+var PatchableColumns = map[string]bool{
+	"name":        true,
+	"description": true,
+	"color":       true,
+	"status":      true,
+	"updated_by":  true,
+}
+
+// 缓存配置
+const (
+	CacheExpiry         = time.Hour   // 标签缓存过期时间
+	CacheNotFoundExpiry = time.Minute // 不存在记录的负缓存过期时间
+
+	cacheTxInvalidateDelay = time.Second // WithTx 写操作二次失效缓存的延迟，需大于事务提交耗时
+
+	cacheTagIdPrefix         = "cache:tag:id:"
+	cacheTagNamePrefix       = "cache:tag:name:"
+	cacheTagMissPrefix       = "cache:tag:miss:"
+	cacheNotFoundPlaceholder = "*"
+)
+
 // 错误定义
 var (
-	ErrNotFound         = errors.New("标签不存在")
-	ErrAlreadyExists    = errors.New("标签名称已存在")
-	ErrInvalidName      = errors.New("标签名称格式错误")
-	ErrInvalidStatus    = errors.New("标签状态无效")
-	ErrNameRequired     = errors.New("标签名称不能为空")
-	ErrNameTooLong      = errors.New("标签名称过长")
-	ErrNameTooShort     = errors.New("标签名称过短")
+	ErrNotFound           = errors.New("标签不存在")
+	ErrAlreadyExists      = errors.New("标签名称已存在")
+	ErrInvalidName        = errors.New("标签名称格式错误")
+	ErrInvalidStatus      = errors.New("标签状态无效")
+	ErrNameRequired       = errors.New("标签名称不能为空")
+	ErrNameTooLong        = errors.New("标签名称过长")
+	ErrNameTooShort       = errors.New("标签名称过短")
 	ErrDescriptionTooLong = errors.New("标签描述过长")
-	ErrInvalidColor     = errors.New("标签颜色格式错误")
+	ErrInvalidColor       = errors.New("标签颜色格式错误")
+	ErrVersionConflict    = errors.New("标签已被修改")
+	ErrFieldNotPatchable  = errors.New("标签字段不支持部分更新")
 )
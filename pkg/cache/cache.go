@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrMiss 缓存未命中
+var ErrMiss = errors.New("cache miss")
+
+// Cache 缓存接口
+type Cache interface {
+	// Get 读取缓存，未命中时返回 ErrMiss
+	Get(ctx context.Context, key string) (string, error)
+
+	// MGet 批量读取缓存，仅返回命中的key
+	MGet(ctx context.Context, keys ...string) (map[string]string, error)
+
+	// Set 写入缓存
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+
+	// Del 删除缓存
+	Del(ctx context.Context, keys ...string) error
+}
+
+// Config Redis缓存配置
+type Config struct {
+	Host string
+	Type string `json:",default=node"` // node/cluster
+	Pass string `json:",optional"`
+}
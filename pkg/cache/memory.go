@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCache 进程内缓存实现（用于测试和单机调试）
+type MemoryCache struct {
+	mu    sync.RWMutex
+	items map[string]memoryItem
+}
+
+type memoryItem struct {
+	value    string
+	expireAt time.Time
+}
+
+// NewMemoryCache 创建进程内缓存
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		items: make(map[string]memoryItem),
+	}
+}
+
+// Get 读取缓存
+func (c *MemoryCache) Get(ctx context.Context, key string) (string, error) {
+	c.mu.RLock()
+	item, ok := c.items[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		return "", ErrMiss
+	}
+	if !item.expireAt.IsZero() && time.Now().After(item.expireAt) {
+		c.mu.Lock()
+		delete(c.items, key)
+		c.mu.Unlock()
+		return "", ErrMiss
+	}
+	return item.value, nil
+}
+
+// MGet 批量读取缓存
+func (c *MemoryCache) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	result := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if val, err := c.Get(ctx, key); err == nil {
+			result[key] = val
+		}
+	}
+	return result, nil
+}
+
+// Set 写入缓存，ttl<=0 表示永不过期
+func (c *MemoryCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	item := memoryItem{value: value}
+	if ttl > 0 {
+		item.expireAt = time.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	c.items[key] = item
+	c.mu.Unlock()
+	return nil
+}
+
+// Del 删除缓存
+func (c *MemoryCache) Del(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	for _, key := range keys {
+		delete(c.items, key)
+	}
+	c.mu.Unlock()
+	return nil
+}
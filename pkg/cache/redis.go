@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	red "github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// RedisCache 基于 go-zero redis 的缓存实现
+type RedisCache struct {
+	rds *redis.Redis
+}
+
+// NewRedisCache 创建Redis缓存
+func NewRedisCache(c Config) (*RedisCache, error) {
+	redisType := c.Type
+	if redisType == "" {
+		redisType = redis.NodeType
+	}
+
+	rds, err := redis.NewRedis(redis.RedisConf{
+		Host:     c.Host,
+		Type:     redisType,
+		Pass:     c.Pass,
+		NonBlock: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect redis: %w", err)
+	}
+	return &RedisCache{rds: rds}, nil
+}
+
+// NewRedisCacheFromClient 使用已有的Redis客户端创建缓存
+func NewRedisCacheFromClient(rds *redis.Redis) *RedisCache {
+	return &RedisCache{rds: rds}
+}
+
+// Client 获取底层Redis客户端
+func (c *RedisCache) Client() *redis.Redis {
+	return c.rds
+}
+
+// Get 读取缓存
+func (c *RedisCache) Get(ctx context.Context, key string) (string, error) {
+	val, err := c.rds.GetCtx(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	// go-zero 在 key 不存在时返回空字符串
+	if val == "" {
+		return "", ErrMiss
+	}
+	return val, nil
+}
+
+// MGet 批量读取缓存
+// 使用管道逐个 GET 而不是 MGET，集群模式下 key 可能分布在不同槽位
+func (c *RedisCache) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	result := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	cmds := make([]*red.StringCmd, len(keys))
+	err := c.rds.PipelinedCtx(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, key)
+		}
+		return nil
+	})
+	// 未命中的 key 返回 redis.Nil
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	for i, cmd := range cmds {
+		if val, err := cmd.Result(); err == nil && val != "" {
+			result[keys[i]] = val
+		}
+	}
+	return result, nil
+}
+
+// Set 写入缓存
+func (c *RedisCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return c.rds.SetCtx(ctx, key, value)
+	}
+	seconds := int(ttl / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return c.rds.SetexCtx(ctx, key, value, seconds)
+}
+
+// Del 删除缓存
+// 多个 key 时使用管道逐个 DEL，原因同 MGet
+func (c *RedisCache) Del(ctx context.Context, keys ...string) error {
+	switch len(keys) {
+	case 0:
+		return nil
+	case 1:
+		_, err := c.rds.DelCtx(ctx, keys[0])
+		return err
+	}
+	return c.rds.PipelinedCtx(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		return nil
+	})
+}
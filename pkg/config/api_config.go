@@ -5,37 +5,71 @@ import "github.com/zeromicro/go-zero/rest"
 // Config API服务配置
 type Config struct {
 	rest.RestConf
-	
+
 	// 多数据库配置
 	DataSources DataSourcesConfig
-	
+
 	// Redis配置
 	Redis RedisConfig
-	
+
 	// Kafka配置
 	Kafka KafkaConfig
-	
+
 	// JWT配置
 	Auth AuthConfig
-	
+
 	// CORS配置
 	Cors CorsConfig
 }
 
 // DataSourcesConfig 多数据库配置
 type DataSourcesConfig struct {
-	DataView          DatabaseConfig
-	DataUnderstanding DatabaseConfig
-	ResourceCatalog   DatabaseConfig
+	DataView          DatabaseConfig `json:",optional"`
+	DataUnderstanding DatabaseConfig `json:",optional"`
+	ResourceCatalog   DatabaseConfig `json:",optional"`
 }
 
 // DatabaseConfig 单个数据库配置
 type DatabaseConfig struct {
-	Driver          string
+	Driver          string `json:",default=mysql,options=mysql|postgres|sqlite"`
 	Source          string
-	MaxOpenConns    int
-	MaxIdleConns    int
-	ConnMaxLifetime int
+	MaxOpenConns    int `json:",default=100"`
+	MaxIdleConns    int `json:",default=10"`
+	ConnMaxLifetime int `json:",default=3600"` // 秒
+
+	// 只读副本连接串，配置后读操作路由到副本
+	Replicas      []string `json:",optional"`
+	ReplicaMaxLag int      `json:",optional"` // 副本最大复制延迟(秒)，超过后摘除
+
+	SlowThreshold int `json:",default=200"` // 慢查询阈值(毫秒)，超过后记录慢查询日志、链路事件和指标
+}
+
+// TenantConfig 多租户配置
+type TenantConfig struct {
+	Claim   string `json:",default=tenantId"` // 认证主体中租户ID对应的 claim
+	Default string `json:",optional"`         // 主体未携带租户时使用的租户（单租户部署）
+}
+
+// MigrationConfig 数据库迁移配置
+type MigrationConfig struct {
+	AutoMigrate bool   `json:",optional"`           // 启动时自动执行未执行的迁移
+	Dir         string `json:",default=migrations"` // 迁移脚本目录，相对路径依次在工作目录及其上级目录中查找
+	LockTimeout int    `json:",default=30"`         // 获取迁移锁超时时间(秒)
+}
+
+// LifecycleConfig 进程生命周期配置
+type LifecycleConfig struct {
+	ShutdownTimeout int `json:",default=30"` // 停止接收新请求后等待处理中请求完成的超时时间(秒)
+	StopTimeout     int `json:",default=10"` // 其余组件（遥测、数据库等）各自的停止超时时间(秒)
+}
+
+// HealthConfig 健康检查配置
+type HealthConfig struct {
+	Enabled          bool  `json:",default=true"`  // 注册 /healthz、/readyz、/status
+	Timeout          int   `json:",default=3"`     // 单个检查项超时时间(秒)
+	MaxAuditBacklog  int64 `json:",default=10000"` // 审计日志积压上限(条)，超过后就绪状态降级
+	TraceErrorWindow int   `json:",default=60"`    // 链路数据导出失败后就绪状态降级的时长(秒)
+	DrainDelay       int   `json:",default=0"`     // 退出时先标记未就绪，等待 DrainDelay 秒后再停止接收请求
 }
 
 // RedisConfig Redis配置
@@ -57,6 +91,8 @@ type KafkaConfig struct {
 type AuthConfig struct {
 	AccessSecret string
 	AccessExpire int64
+	RoleClaim    string `json:",default=roles"` // 认证主体中角色对应的 claim，支持数组或逗号分隔的字符串
+	AdminRole    string `json:",default=admin"` // 管理员角色，可查询和导出审计日志
 }
 
 // CorsConfig CORS配置
@@ -65,3 +101,13 @@ type CorsConfig struct {
 	AllowMethods []string
 	AllowHeaders []string
 }
+
+// MiddlewareConfig 全局中间件配置，中间件按固定顺序组装（见 middleware.Chain），此处只控制是否启用
+type MiddlewareConfig struct {
+	RequestID bool `json:",default=true"`
+	Trace     bool `json:",default=true"` // 启用时应关闭 RestConf 内置的 Middlewares.Trace，避免重复创建 Span
+	Logger    bool `json:",default=true"` // 启用时应关闭 RestConf 内置的 Middlewares.Log，避免重复记录请求日志
+	Metrics   bool `json:",default=true"`
+	Recovery  bool `json:",default=true"`
+	Cors      bool `json:",default=true"` // 按 Cors 配置在路由层处理跨域（见 middleware.CorsOption），未配置 AllowOrigins 时允许所有来源
+}
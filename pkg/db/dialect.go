@@ -0,0 +1,177 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 支持的数据库类型
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// 配置错误
+var (
+	ErrUnsupportedDriver = errors.New("不支持的数据库类型")
+	ErrMissingSource     = errors.New("数据库连接信息未配置")
+	ErrMissingUsername   = errors.New("数据库用户名未配置")
+)
+
+// Validate 校验数据库配置，缺少连接信息或凭据时返回错误
+func (c Config) Validate() error {
+	switch c.driver() {
+	case DriverMySQL:
+		if c.Source != "" {
+			dsn, err := mysqldriver.ParseDSN(c.Source)
+			if err != nil {
+				return fmt.Errorf("解析 mysql 连接串失败: %w", err)
+			}
+			if dsn.User == "" {
+				return ErrMissingUsername
+			}
+			return nil
+		}
+		return c.validateFields()
+	case DriverPostgres:
+		if c.Source != "" {
+			if !postgresHasUser(c.Source) {
+				return ErrMissingUsername
+			}
+			return nil
+		}
+		return c.validateFields()
+	case DriverSQLite:
+		if c.Source == "" && c.Database == "" {
+			return ErrMissingSource
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedDriver, c.Driver)
+	}
+}
+
+// validateFields 校验基础连接配置
+func (c Config) validateFields() error {
+	if c.Host == "" || c.Database == "" {
+		return ErrMissingSource
+	}
+	if c.Username == "" {
+		return ErrMissingUsername
+	}
+	return nil
+}
+
+// driver 返回数据库类型，未配置时默认为 mysql
+func (c Config) driver() string {
+	if c.Driver == "" {
+		return DriverMySQL
+	}
+	return strings.ToLower(c.Driver)
+}
+
+// DSN 构建连接串
+func (c Config) DSN() string {
+	if c.Source != "" {
+		return c.Source
+	}
+
+	switch c.driver() {
+	case DriverPostgres:
+		port := c.Port
+		if port == 0 {
+			port = 5432
+		}
+		return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable TimeZone=Local",
+			c.Host, port, c.Username, c.Password, c.Database)
+	case DriverSQLite:
+		return c.Database
+	default:
+		port := c.Port
+		if port == 0 {
+			port = 3306
+		}
+		charset := c.Charset
+		if charset == "" {
+			charset = "utf8mb4"
+		}
+		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local",
+			c.Username, c.Password, c.Host, port, c.Database, charset)
+	}
+}
+
+// openDialector 根据数据库类型创建 GORM 方言
+func openDialector(c Config) (gorm.Dialector, error) {
+	switch c.driver() {
+	case DriverMySQL:
+		return mysql.Open(c.DSN()), nil
+	case DriverPostgres:
+		return postgres.Open(c.DSN()), nil
+	case DriverSQLite:
+		return sqlite.Open(c.DSN()), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDriver, c.Driver)
+	}
+}
+
+// postgresHasUser 检查 postgres 连接串是否包含用户名（支持 URL 和 key=value 两种格式）
+func postgresHasUser(source string) bool {
+	if strings.HasPrefix(source, "postgres://") || strings.HasPrefix(source, "postgresql://") {
+		u, err := url.Parse(source)
+		return err == nil && u.User != nil && u.User.Username() != ""
+	}
+	for _, field := range strings.Fields(source) {
+		if strings.HasPrefix(field, "user=") && len(field) > len("user=") {
+			return true
+		}
+	}
+	return false
+}
+
+// Dialect 获取连接使用的数据库类型
+func Dialect(db *gorm.DB) string {
+	return db.Dialector.Name()
+}
+
+// EscapeLike 转义 LIKE 通配符
+func EscapeLike(keyword string) string {
+	return likeEscaper.Replace(keyword)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ContainsPattern 构建包含匹配的 LIKE 模式（已转义）
+func ContainsPattern(keyword string) string {
+	return "%" + EscapeLike(keyword) + "%"
+}
+
+// LikeCondition 构建 LIKE 条件，多个列之间为 OR 关系
+// 显式声明转义符：sqlite 没有默认转义符，mysql 字符串字面量中的反斜杠需要双写
+func LikeCondition(db *gorm.DB, columns ...string) string {
+	escape := `ESCAPE '\'`
+	if Dialect(db) == DriverMySQL {
+		escape = `ESCAPE '\\'`
+	}
+
+	conds := make([]string, 0, len(columns))
+	for _, column := range columns {
+		conds = append(conds, fmt.Sprintf("%s LIKE ? %s", column, escape))
+	}
+	return strings.Join(conds, " OR ")
+}
+
+// OnConflictIgnore 插入时忽略唯一键冲突
+// mysql 生成 ON DUPLICATE KEY UPDATE，postgres/sqlite 生成 ON CONFLICT DO NOTHING
+func OnConflictIgnore() clause.Expression {
+	return clause.OnConflict{DoNothing: true}
+}
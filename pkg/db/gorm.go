@@ -4,21 +4,30 @@ import (
 	"fmt"
 	"time"
 
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
+	"gorm.io/plugin/dbresolver"
 )
 
 // Config 数据库配置
 type Config struct {
+	// 数据源名称，用于链路追踪和指标标签，通过 Registry 注册时自动填充
+	Name string `json:",optional"`
+
+	// 数据库类型: mysql/postgres/sqlite
+	Driver string `json:",default=mysql,options=mysql|postgres|sqlite"`
+
+	// 完整连接串，配置后忽略下面的基础连接配置
+	Source string `json:",optional"`
+
 	// 基础连接配置
-	Host     string `json:",default=127.0.0.1"`
-	Port     int    `json:",default=3306"`
-	Database string
-	Username string
-	Password string
-	Charset  string `json:",default=utf8mb4"`
+	Host     string `json:",optional"`
+	Port     int    `json:",optional"` // 为空时使用驱动默认端口
+	Database string `json:",optional"` // sqlite 时为数据库文件路径
+	Username string `json:",optional"`
+	Password string `json:",optional"`
+	Charset  string `json:",default=utf8mb4"` // 仅 mysql 使用
 
 	// 连接池配置
 	MaxIdleConns    int `json:",default=10"`   // 最大空闲连接数
@@ -26,6 +35,11 @@ type Config struct {
 	ConnMaxLifetime int `json:",default=3600"` // 连接最大生存时间(秒)
 	ConnMaxIdleTime int `json:",default=600"`  // 连接最大空闲时间(秒)
 
+	// 读写分离配置
+	Replicas             []string `json:",optional"`  // 只读副本连接串
+	ReplicaCheckInterval int      `json:",default=5"` // 副本健康检查间隔(秒)
+	ReplicaMaxLag        int      `json:",default=0"` // 副本最大复制延迟(秒)，0 表示不检查
+
 	// 日志配置
 	LogLevel          string `json:",default=warn"` // silent/error/warn/info
 	SlowThreshold     int    `json:",default=200"`  // 慢查询阈值(毫秒)
@@ -37,15 +51,14 @@ type Config struct {
 
 // InitGorm 初始化 GORM 连接
 func InitGorm(c Config) (*gorm.DB, error) {
-	// 1. 构建 DSN
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local",
-		c.Username,
-		c.Password,
-		c.Host,
-		c.Port,
-		c.Database,
-		c.Charset,
-	)
+	// 1. 校验配置并选择方言
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	dialector, err := openDialector(c)
+	if err != nil {
+		return nil, err
+	}
 
 	// 2. 配置 GORM
 	gormConfig := &gorm.Config{
@@ -66,7 +79,7 @@ func InitGorm(c Config) (*gorm.DB, error) {
 	}
 
 	// 3. 打开连接
-	db, err := gorm.Open(mysql.Open(dsn), gormConfig)
+	db, err := gorm.Open(dialector, gormConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect database: %w", err)
 	}
@@ -83,9 +96,47 @@ func InitGorm(c Config) (*gorm.DB, error) {
 	sqlDB.SetConnMaxLifetime(time.Duration(c.ConnMaxLifetime) * time.Second)
 	sqlDB.SetConnMaxIdleTime(time.Duration(c.ConnMaxIdleTime) * time.Second)
 
+	// 5. 配置读写分离
+	if len(c.Replicas) > 0 {
+		if err := setupReplicas(db, c); err != nil {
+			sqlDB.Close()
+			return nil, err
+		}
+	}
+
+	// 6. 注册链路追踪和指标插件
+	if err := db.Use(newTelemetryPlugin(c)); err != nil {
+		Close(db)
+		return nil, fmt.Errorf("failed to register telemetry plugin: %w", err)
+	}
+
 	return db, nil
 }
 
+// Close 关闭连接，包括读写分离的副本连接、健康检查和连接池指标采集
+func Close(db *gorm.DB) error {
+	if plugin, ok := db.Config.Plugins[telemetryPluginName]; ok {
+		plugin.(*telemetryPlugin).stop()
+	}
+	if plugin, ok := db.Config.Plugins[replicaPluginName]; ok {
+		plugin.(*replicaHealth).stop()
+	}
+	if plugin, ok := db.Config.Plugins["gorm:db_resolver"]; ok {
+		return plugin.(*dbresolver.DBResolver).Call(func(pool gorm.ConnPool) error {
+			if closer, ok := pool.(interface{ Close() error }); ok {
+				return closer.Close()
+			}
+			return nil
+		})
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
 // getLogLevel 获取日志级别
 func getLogLevel(level string) logger.LogLevel {
 	switch level {
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// JSON 跨数据库的 JSON 列类型
+// mysql 使用 JSON，postgres 使用 JSONB，sqlite 使用 TEXT
+type JSON json.RawMessage
+
+// Value 实现 driver.Valuer 接口
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (j *JSON) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append((*j)[:0], v...)
+	case string:
+		*j = JSON(v)
+	default:
+		return fmt.Errorf("无法将 %T 转换为 JSON", value)
+	}
+	return nil
+}
+
+// MarshalJSON 实现 json.Marshaler 接口
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	if j == nil {
+		return errors.New("db.JSON: UnmarshalJSON on nil pointer")
+	}
+	*j = append((*j)[:0], data...)
+	return nil
+}
+
+// GormDataType 通用数据类型
+func (JSON) GormDataType() string {
+	return "json"
+}
+
+// GormDBDataType 按方言返回列类型
+func (JSON) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	switch Dialect(db) {
+	case DriverMySQL:
+		return "JSON"
+	case DriverPostgres:
+		return "JSONB"
+	default:
+		return "TEXT"
+	}
+}
+
+// NewJSON 将任意值序列化为 JSON 列
+func NewJSON(v interface{}) (JSON, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return JSON(data), nil
+}
@@ -0,0 +1,190 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/syncx"
+	"gorm.io/gorm"
+)
+
+// 数据源名称
+const (
+	DataSourceDefault           = "default"
+	DataSourceDataView          = "data_view"
+	DataSourceDataUnderstanding = "data_understanding"
+	DataSourceResourceCatalog   = "resource_catalog"
+)
+
+// ErrDataSourceNotFound 数据源未配置
+var ErrDataSourceNotFound = errors.New("数据源未配置")
+
+// Registry 多数据源注册表
+// 数据源在首次使用时建立连接并做健康检查，连接失败不会缓存，下次获取时重试
+// 建立连接时不持有锁，某个数据源连接缓慢不会阻塞其他数据源的获取
+type Registry struct {
+	mu      sync.Mutex
+	configs map[string]Config
+	conns   map[string]*gorm.DB
+	open    func(c Config) (*gorm.DB, error)
+	barrier syncx.SingleFlight
+}
+
+// NewRegistry 创建数据源注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		configs: make(map[string]Config),
+		conns:   make(map[string]*gorm.DB),
+		open:    InitGorm,
+		barrier: syncx.NewSingleFlight(),
+	}
+}
+
+// Register 注册数据源配置（不会立即建立连接）
+func (r *Registry) Register(name string, c Config) error {
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("数据源 %s 配置错误: %w", name, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.configs[name]; ok {
+		return fmt.Errorf("数据源 %s 重复注册", name)
+	}
+	c.Name = name
+	r.configs[name] = c
+	return nil
+}
+
+// Get 获取数据源连接，首次调用时建立连接
+// 同一数据源的并发首次调用只建立一次连接
+func (r *Registry) Get(ctx context.Context, name string) (*gorm.DB, error) {
+	conn, c, err := r.lookup(name)
+	if conn != nil || err != nil {
+		return conn, err
+	}
+
+	val, err := r.barrier.Do(name, func() (any, error) {
+		// 等待期间其他调用可能已建立连接
+		if conn, _, _ := r.lookup(name); conn != nil {
+			return conn, nil
+		}
+
+		conn, err := r.open(c)
+		if err != nil {
+			return nil, fmt.Errorf("连接数据源 %s 失败: %w", name, err)
+		}
+		if err := ping(ctx, conn); err != nil {
+			closeConn(conn)
+			return nil, fmt.Errorf("数据源 %s 健康检查失败: %w", name, err)
+		}
+
+		r.mu.Lock()
+		r.conns[name] = conn
+		r.mu.Unlock()
+		logx.Infof("数据源 %s 连接成功 [driver=%s]", name, c.driver())
+		return conn, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return val.(*gorm.DB), nil
+}
+
+// lookup 查询已建立的连接和数据源配置
+func (r *Registry) lookup(name string) (*gorm.DB, Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if conn, ok := r.conns[name]; ok {
+		return conn, Config{}, nil
+	}
+	c, ok := r.configs[name]
+	if !ok {
+		return nil, Config{}, fmt.Errorf("%w: %s", ErrDataSourceNotFound, name)
+	}
+	return nil, c, nil
+}
+
+// MustGet 获取数据源连接，失败时 panic
+func (r *Registry) MustGet(ctx context.Context, name string) *gorm.DB {
+	conn, err := r.Get(ctx, name)
+	if err != nil {
+		panic(err)
+	}
+	return conn
+}
+
+// Has 是否配置了数据源
+func (r *Registry) Has(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.configs[name]
+	return ok
+}
+
+// Names 获取所有已配置的数据源名称
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.configs))
+	for name := range r.configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check 检查数据源健康状态，尚未连接时会先建立连接
+func (r *Registry) Check(ctx context.Context, name string) error {
+	conn, err := r.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	return ping(ctx, conn)
+}
+
+// CheckAll 检查所有已配置数据源的健康状态
+func (r *Registry) CheckAll(ctx context.Context) map[string]error {
+	results := make(map[string]error)
+	for _, name := range r.Names() {
+		results[name] = r.Check(ctx, name)
+	}
+	return results
+}
+
+// Close 关闭所有已建立的连接
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	for name, conn := range r.conns {
+		if err := closeConn(conn); err != nil {
+			errs = append(errs, fmt.Errorf("关闭数据源 %s 失败: %w", name, err))
+		}
+		delete(r.conns, name)
+	}
+	return errors.Join(errs...)
+}
+
+// ping 检查连接可用性
+func ping(ctx context.Context, conn *gorm.DB) error {
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// closeConn 关闭连接
+func closeConn(conn *gorm.DB) error {
+	return Close(conn)
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TestRegistry_LazyOpen 测试数据源按需连接
+func TestRegistry_LazyOpen(t *testing.T) {
+	r := NewRegistry()
+	opened := 0
+	r.open = func(c Config) (*gorm.DB, error) {
+		opened++
+		return InitGorm(c)
+	}
+
+	dir := t.TempDir()
+	if err := r.Register(DataSourceDefault, Config{Driver: DriverSQLite, Database: filepath.Join(dir, "default.db")}); err != nil {
+		t.Fatalf("注册失败: %v", err)
+	}
+	if err := r.Register(DataSourceDataView, Config{Driver: DriverSQLite, Database: filepath.Join(dir, "data_view.db")}); err != nil {
+		t.Fatalf("注册失败: %v", err)
+	}
+	if opened != 0 {
+		t.Fatalf("注册时不应建立连接, 实际=%d", opened)
+	}
+
+	ctx := context.Background()
+	first, err := r.Get(ctx, DataSourceDefault)
+	if err != nil {
+		t.Fatalf("获取数据源失败: %v", err)
+	}
+	second, _ := r.Get(ctx, DataSourceDefault)
+	if first != second || opened != 1 {
+		t.Errorf("同一数据源应复用连接, opened=%d", opened)
+	}
+
+	for name, err := range r.CheckAll(ctx) {
+		if err != nil {
+			t.Errorf("数据源 %s 健康检查失败: %v", name, err)
+		}
+	}
+	if opened != 2 {
+		t.Errorf("健康检查应建立所有连接, opened=%d", opened)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("关闭失败: %v", err)
+	}
+	if err := ping(ctx, first); err == nil {
+		t.Error("关闭后连接不应可用")
+	}
+}
+
+// TestRegistry_Errors 测试错误处理
+func TestRegistry_Errors(t *testing.T) {
+	r := NewRegistry()
+	ctx := context.Background()
+
+	if _, err := r.Get(ctx, DataSourceResourceCatalog); !errors.Is(err, ErrDataSourceNotFound) {
+		t.Errorf("期望ErrDataSourceNotFound, 实际=%v", err)
+	}
+	if err := r.Register(DataSourceDataView, Config{Driver: DriverMySQL}); !errors.Is(err, ErrMissingSource) {
+		t.Errorf("期望ErrMissingSource, 实际=%v", err)
+	}
+
+	// 连接失败不缓存，下次重试
+	r.Register(DataSourceDefault, Config{Driver: DriverSQLite, Database: ":memory:"})
+	attempts := 0
+	r.open = func(c Config) (*gorm.DB, error) {
+		attempts++
+		return nil, errors.New("connection refused")
+	}
+	r.Get(ctx, DataSourceDefault)
+	r.Get(ctx, DataSourceDefault)
+	if attempts != 2 {
+		t.Errorf("期望重试2次, 实际=%d", attempts)
+	}
+}
+
+// TestRegistry_SlowSource 测试连接缓慢的数据源不阻塞其他数据源
+func TestRegistry_SlowSource(t *testing.T) {
+	r := NewRegistry()
+	dir := t.TempDir()
+	r.Register(DataSourceDefault, Config{Driver: DriverSQLite, Database: filepath.Join(dir, "default.db")})
+	r.Register(DataSourceDataView, Config{Driver: DriverSQLite, Database: filepath.Join(dir, "data_view.db")})
+
+	release := make(chan struct{})
+	var slowOpens atomic.Int32
+	r.open = func(c Config) (*gorm.DB, error) {
+		if c.Name == DataSourceDataView {
+			slowOpens.Add(1)
+			<-release
+		}
+		return InitGorm(c)
+	}
+	defer r.Close()
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Get(ctx, DataSourceDataView)
+		}()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.Get(ctx, DataSourceDefault)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("获取默认数据源失败: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("默认数据源被缓慢的数据源阻塞")
+	}
+
+	close(release)
+	wg.Wait()
+	if n := slowOpens.Load(); n != 1 {
+		t.Errorf("并发首次获取应只连接1次, 实际=%d", n)
+	}
+}
@@ -0,0 +1,297 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+const replicaPluginName = "idrm:replica_health"
+
+type primaryKey struct{}
+
+// WithPrimary 强制本次调用的读操作走主库（读己之写）
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// UsePrimary 是否强制走主库
+func UsePrimary(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	v, _ := ctx.Value(primaryKey{}).(bool)
+	return v
+}
+
+// setupReplicas 配置读写分离
+// 读操作路由到健康的只读副本，写操作和事务走主库；所有副本不可用时读操作回退到主库的连接池
+func setupReplicas(db *gorm.DB, c Config) error {
+	replicas := make([]gorm.Dialector, 0, len(c.Replicas))
+	for _, dsn := range c.Replicas {
+		replica := c
+		replica.Source = dsn
+		replica.Replicas = nil
+		dialector, err := openDialector(replica)
+		if err != nil {
+			return err
+		}
+		replicas = append(replicas, dialector)
+	}
+
+	policy := &replicaPolicy{total: int64(len(replicas))}
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   policy,
+	})
+	if err := db.Use(resolver); err != nil {
+		return fmt.Errorf("failed to register dbresolver: %w", err)
+	}
+	resolver.
+		SetMaxIdleConns(c.MaxIdleConns).
+		SetMaxOpenConns(c.MaxOpenConns).
+		SetConnMaxLifetime(time.Duration(c.ConnMaxLifetime) * time.Second).
+		SetConnMaxIdleTime(time.Duration(c.ConnMaxIdleTime) * time.Second)
+
+	// 读己之写：上下文要求走主库时标记为写操作；所有副本被摘除时同样标记，复用主库的连接池
+	// 在 dbresolver 之后注册的 Before("*") 回调会排在它前面执行
+	forcePrimary := func(tx *gorm.DB) {
+		if UsePrimary(tx.Statement.Context) || !policy.available() {
+			dbresolver.Write.ModifyStatement(tx.Statement)
+		}
+	}
+	if err := db.Callback().Query().Before("*").Register("idrm:force_primary", forcePrimary); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("*").Register("idrm:force_primary", forcePrimary); err != nil {
+		return err
+	}
+
+	// 副本健康检查
+	health := newReplicaHealth(resolver, policy, c)
+	if err := db.Use(health); err != nil {
+		return err
+	}
+	health.start()
+
+	return nil
+}
+
+// replicaPolicy 跳过被摘除副本的负载均衡策略
+type replicaPolicy struct {
+	unhealthy sync.Map // gorm.ConnPool -> struct{}
+	ejected   atomic.Int64
+	total     int64
+	counter   atomic.Uint64
+}
+
+// Resolve 实现 dbresolver.Policy 接口
+// 所有副本被摘除时读操作已在回调中改走主库，此处仅处理检查期间的竞争，仍按全部副本轮询
+func (p *replicaPolicy) Resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	healthy := make([]gorm.ConnPool, 0, len(pools))
+	for _, pool := range pools {
+		if _, ok := p.unhealthy.Load(pool); !ok {
+			healthy = append(healthy, pool)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = pools
+	}
+	return healthy[p.counter.Add(1)%uint64(len(healthy))]
+}
+
+// available 是否还有未被摘除的副本
+func (p *replicaPolicy) available() bool {
+	return p.ejected.Load() < p.total
+}
+
+// setHealthy 更新副本健康状态
+func (p *replicaPolicy) setHealthy(pool gorm.ConnPool, healthy bool) {
+	if healthy {
+		if _, ok := p.unhealthy.LoadAndDelete(pool); ok {
+			p.ejected.Add(-1)
+		}
+	} else if _, loaded := p.unhealthy.LoadOrStore(pool, struct{}{}); !loaded {
+		p.ejected.Add(1)
+	}
+}
+
+// replicaHealth 副本健康检查，连接失败或复制延迟超限时摘除副本
+type replicaHealth struct {
+	resolver *dbresolver.DBResolver
+	policy   *replicaPolicy
+	driver   string
+	interval time.Duration
+	maxLag   time.Duration
+	replicas []gorm.ConnPool
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newReplicaHealth(resolver *dbresolver.DBResolver, policy *replicaPolicy, c Config) *replicaHealth {
+	interval := time.Duration(c.ReplicaCheckInterval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	// Call 先遍历主库再遍历副本
+	var pools []gorm.ConnPool
+	resolver.Call(func(pool gorm.ConnPool) error {
+		pools = append(pools, pool)
+		return nil
+	})
+	replicas := pools[1:]
+
+	return &replicaHealth{
+		resolver: resolver,
+		policy:   policy,
+		driver:   c.driver(),
+		interval: interval,
+		maxLag:   time.Duration(c.ReplicaMaxLag) * time.Second,
+		replicas: replicas,
+		done:     make(chan struct{}),
+	}
+}
+
+// Name 实现 gorm.Plugin 接口
+func (h *replicaHealth) Name() string {
+	return replicaPluginName
+}
+
+// Initialize 实现 gorm.Plugin 接口
+func (h *replicaHealth) Initialize(*gorm.DB) error {
+	return nil
+}
+
+// start 启动定时检查
+func (h *replicaHealth) start() {
+	go func() {
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				h.checkAll()
+			case <-h.done:
+				return
+			}
+		}
+	}()
+}
+
+// stop 停止检查
+func (h *replicaHealth) stop() {
+	h.stopOnce.Do(func() {
+		close(h.done)
+	})
+}
+
+// checkAll 检查所有副本
+func (h *replicaHealth) checkAll() {
+	for i, pool := range h.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), h.interval)
+		err := h.check(ctx, pool)
+		cancel()
+
+		_, wasUnhealthy := h.policy.unhealthy.Load(pool)
+		if err != nil && !wasUnhealthy {
+			logx.Errorf("只读副本 #%d 已摘除: %v", i, err)
+		} else if err == nil && wasUnhealthy {
+			logx.Infof("只读副本 #%d 已恢复", i)
+		}
+		h.policy.setHealthy(pool, err == nil)
+	}
+}
+
+// check 检查单个副本的连通性和复制延迟
+func (h *replicaHealth) check(ctx context.Context, pool gorm.ConnPool) error {
+	if pinger, ok := pool.(interface{ PingContext(context.Context) error }); ok {
+		if err := pinger.PingContext(ctx); err != nil {
+			return err
+		}
+	}
+	if h.maxLag <= 0 {
+		return nil
+	}
+
+	lag, err := replicationLag(ctx, pool, h.driver)
+	if err != nil {
+		return fmt.Errorf("查询复制延迟失败: %w", err)
+	}
+	if lag > h.maxLag {
+		return fmt.Errorf("复制延迟 %s 超过阈值 %s", lag, h.maxLag)
+	}
+	return nil
+}
+
+// replicationLag 查询副本的复制延迟
+func replicationLag(ctx context.Context, pool gorm.ConnPool, driver string) (time.Duration, error) {
+	switch driver {
+	case DriverPostgres:
+		var seconds sql.NullFloat64
+		row := pool.QueryRowContext(ctx,
+			"SELECT EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())")
+		if err := row.Scan(&seconds); err != nil {
+			return 0, err
+		}
+		return time.Duration(seconds.Float64 * float64(time.Second)), nil
+	case DriverMySQL:
+		return mysqlReplicationLag(ctx, pool)
+	default:
+		return 0, nil
+	}
+}
+
+// mysqlReplicationLag 解析 SHOW REPLICA STATUS 中的延迟字段
+// MySQL 8.0.22 之前不支持 SHOW REPLICA STATUS，失败时改用 SHOW SLAVE STATUS
+func mysqlReplicationLag(ctx context.Context, pool gorm.ConnPool) (time.Duration, error) {
+	rows, err := pool.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		rows, err = pool.QueryContext(ctx, "SHOW SLAVE STATUS")
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if !rows.Next() {
+		return 0, fmt.Errorf("未配置复制")
+	}
+
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, err
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" && column != "Seconds_Behind_Master" {
+			continue
+		}
+		if values[i] == nil {
+			return 0, fmt.Errorf("复制已中断")
+		}
+		seconds, err := strconv.Atoi(string(values[i]))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, fmt.Errorf("未找到复制延迟字段")
+}
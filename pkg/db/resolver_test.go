@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type resolverItem struct {
+	Id   int64
+	Name string
+}
+
+// setupReplicaDB 创建主库和副本为不同 sqlite 文件的连接，便于区分读写路由
+func setupReplicaDB(t *testing.T) *gorm.DB {
+	dir := t.TempDir()
+	primary := filepath.Join(dir, "primary.db")
+	replica := filepath.Join(dir, "replica.db")
+
+	// 主库和副本分别建表，副本写入一条只存在于副本的数据
+	for _, path := range []string{primary, replica} {
+		conn, err := InitGorm(Config{Driver: DriverSQLite, Database: path})
+		if err != nil {
+			t.Fatalf("初始化失败: %v", err)
+		}
+		conn.AutoMigrate(&resolverItem{})
+		if path == replica {
+			conn.Create(&resolverItem{Name: "replica"})
+		}
+		Close(conn)
+	}
+
+	db, err := InitGorm(Config{
+		Driver:   DriverSQLite,
+		Database: primary,
+		Replicas: []string{replica},
+	})
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	t.Cleanup(func() { Close(db) })
+	return db
+}
+
+// TestReplicas_Routing 测试读写分离路由
+func TestReplicas_Routing(t *testing.T) {
+	db := setupReplicaDB(t)
+	ctx := context.Background()
+
+	// 写入走主库
+	if err := db.WithContext(ctx).Create(&resolverItem{Name: "primary"}).Error; err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	// 读取走副本
+	var item resolverItem
+	db.WithContext(ctx).First(&item)
+	if item.Name != "replica" {
+		t.Errorf("期望读取副本数据, 实际=%s", item.Name)
+	}
+
+	// 读己之写：强制走主库
+	item = resolverItem{}
+	db.WithContext(WithPrimary(ctx)).First(&item)
+	if item.Name != "primary" {
+		t.Errorf("期望读取主库数据, 实际=%s", item.Name)
+	}
+
+	// 事务内读写都走主库
+	db.Transaction(func(tx *gorm.DB) error {
+		item = resolverItem{}
+		tx.First(&item)
+		return nil
+	})
+	if item.Name != "primary" {
+		t.Errorf("事务内期望读取主库数据, 实际=%s", item.Name)
+	}
+}
+
+// TestReplicas_Ejection 测试副本摘除后回退主库
+func TestReplicas_Ejection(t *testing.T) {
+	db := setupReplicaDB(t)
+	ctx := context.Background()
+	db.Create(&resolverItem{Name: "primary"})
+
+	health := db.Config.Plugins[replicaPluginName].(*replicaHealth)
+	if len(health.replicas) != 1 {
+		t.Fatalf("期望1个副本, 实际=%d", len(health.replicas))
+	}
+	// 回退时复用主库的连接池，不额外打开连接
+	pools := 0
+	health.resolver.Call(func(gorm.ConnPool) error {
+		pools++
+		return nil
+	})
+	if pools != 2 {
+		t.Errorf("期望主库和副本共2个连接池, 实际=%d", pools)
+	}
+
+	// 模拟副本宕机
+	health.policy.setHealthy(health.replicas[0], false)
+	var item resolverItem
+	db.WithContext(ctx).First(&item)
+	if item.Name != "primary" {
+		t.Errorf("副本摘除后期望读取主库数据, 实际=%s", item.Name)
+	}
+
+	// 健康检查恢复副本
+	health.checkAll()
+	item = resolverItem{}
+	db.WithContext(ctx).First(&item)
+	if item.Name != "replica" {
+		t.Errorf("副本恢复后期望读取副本数据, 实际=%s", item.Name)
+	}
+}
+
+// legacyMySQLPool 模拟 MySQL 8.0.22 之前的副本，只支持 SHOW SLAVE STATUS
+type legacyMySQLPool struct {
+	*sql.DB
+}
+
+func (p legacyMySQLPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if query != "SHOW SLAVE STATUS" {
+		return nil, errors.New("You have an error in your SQL syntax")
+	}
+	return p.DB.QueryContext(ctx, "SELECT 'Yes' AS Slave_IO_Running, 3 AS Seconds_Behind_Master")
+}
+
+// TestReplicationLag_LegacyMySQL 测试旧版 MySQL 改用 SHOW SLAVE STATUS 查询复制延迟
+func TestReplicationLag_LegacyMySQL(t *testing.T) {
+	conn, err := InitGorm(Config{Driver: DriverSQLite, Database: ":memory:"})
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	defer Close(conn)
+	sqlDB, _ := conn.DB()
+
+	lag, err := replicationLag(context.Background(), legacyMySQLPool{sqlDB}, DriverMySQL)
+	if err != nil {
+		t.Fatalf("查询复制延迟失败: %v", err)
+	}
+	if lag != 3*time.Second {
+		t.Errorf("期望延迟=3s, 实际=%s", lag)
+	}
+}
@@ -0,0 +1,262 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/metric"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	telemetryPluginName = "idrm:telemetry"
+
+	// spanKey 语句执行期间保存 span 和开始时间的键
+	spanKey = "idrm:telemetry_span"
+
+	// maxStatementLength span 中记录的 SQL 最大长度
+	maxStatementLength = 2048
+
+	// poolStatsInterval 连接池指标采集间隔
+	poolStatsInterval = 15 * time.Second
+)
+
+// 数据库指标，按数据源区分
+var (
+	metricDuration = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: "db",
+		Subsystem: "client",
+		Name:      "duration_ms",
+		Help:      "SQL 执行耗时(毫秒)",
+		Labels:    []string{"datasource", "table", "operation"},
+		Buckets:   []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500},
+	})
+	metricErrors = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "db",
+		Subsystem: "client",
+		Name:      "errors_total",
+		Help:      "SQL 执行失败次数",
+		Labels:    []string{"datasource", "table", "operation"},
+	})
+	metricSlow = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "db",
+		Subsystem: "client",
+		Name:      "slow_total",
+		Help:      "慢查询次数",
+		Labels:    []string{"datasource", "table", "operation"},
+	})
+	metricPool = metric.NewGaugeVec(&metric.GaugeVecOpts{
+		Namespace: "db",
+		Subsystem: "pool",
+		Name:      "connections",
+		Help:      "连接池连接数，state 为 open/in_use/idle/max_open",
+		Labels:    []string{"datasource", "state"},
+	})
+	metricPoolWait = metric.NewGaugeVec(&metric.GaugeVecOpts{
+		Namespace: "db",
+		Subsystem: "pool",
+		Name:      "wait_total",
+		Help:      "等待空闲连接的累计次数",
+		Labels:    []string{"datasource"},
+	})
+	metricPoolWaitDuration = metric.NewGaugeVec(&metric.GaugeVecOpts{
+		Namespace: "db",
+		Subsystem: "pool",
+		Name:      "wait_duration_ms",
+		Help:      "等待空闲连接的累计耗时(毫秒)",
+		Labels:    []string{"datasource"},
+	})
+)
+
+var (
+	// 字符串字面量（含转义的单引号）
+	stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	// 不属于标识符和 $n 占位符的数字字面量
+	numberLiteral = regexp.MustCompile(`(^|[^\w$.])\d+(?:\.\d+)?\b`)
+)
+
+// spanState 语句开始时记录的 span 和时间
+type spanState struct {
+	span  trace.Span
+	start time.Time
+}
+
+// telemetryPlugin 为每条 SQL 创建客户端 span，记录耗时、影响行数和慢查询，并定时采集连接池指标
+type telemetryPlugin struct {
+	datasource    string
+	system        string
+	slowThreshold time.Duration
+	tracer        trace.Tracer
+
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// newTelemetryPlugin 创建链路追踪和指标插件
+func newTelemetryPlugin(c Config) *telemetryPlugin {
+	datasource := c.Name
+	if datasource == "" {
+		datasource = DataSourceDefault
+	}
+	system := c.driver()
+	if system == DriverPostgres {
+		system = "postgresql"
+	}
+	return &telemetryPlugin{
+		datasource:    datasource,
+		system:        system,
+		slowThreshold: time.Duration(c.SlowThreshold) * time.Millisecond,
+		tracer:        otel.Tracer("idrm/pkg/db"),
+		done:          make(chan struct{}),
+	}
+}
+
+// Name 插件名称
+func (p *telemetryPlugin) Name() string {
+	return telemetryPluginName
+}
+
+// Initialize 注册回调并启动连接池指标采集
+func (p *telemetryPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	hooks := []struct {
+		name      string
+		operation string // 为空时从 SQL 中提取
+		before    func(string, func(*gorm.DB)) error
+		after     func(string, func(*gorm.DB)) error
+	}{
+		{"create", "INSERT", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", "SELECT", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", "UPDATE", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", "DELETE", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", "", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", "", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, h := range hooks {
+		operation := h.operation
+		if err := h.before("idrm:telemetry_before_"+h.name, p.before); err != nil {
+			return err
+		}
+		if err := h.after("idrm:telemetry_after_"+h.name, func(tx *gorm.DB) { p.after(tx, operation) }); err != nil {
+			return err
+		}
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		go p.collectPoolStats(sqlDB.Stats)
+	}
+	return nil
+}
+
+// before 开始 span
+func (p *telemetryPlugin) before(tx *gorm.DB) {
+	ctx, span := p.tracer.Start(tx.Statement.Context, "db", trace.WithSpanKind(trace.SpanKindClient))
+	tx.Statement.Context = ctx
+	tx.InstanceSet(spanKey, &spanState{span: span, start: time.Now()})
+}
+
+// after 补充语句信息并结束 span，记录指标
+func (p *telemetryPlugin) after(tx *gorm.DB, operation string) {
+	value, ok := tx.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	state := value.(*spanState)
+	duration := time.Since(state.start)
+
+	statement := sanitizeSQL(tx.Statement.SQL.String())
+	if operation == "" {
+		operation = sqlOperation(statement)
+	}
+	table := tx.Statement.Table
+
+	span := state.span
+	name := operation
+	if table != "" {
+		name += " " + table
+	}
+	span.SetName(name)
+	span.SetAttributes(
+		attribute.String("db.system", p.system),
+		attribute.String("db.name", p.datasource),
+		attribute.String("db.operation", operation),
+		attribute.String("db.sql.table", table),
+		attribute.String("db.statement", statement),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+
+	labels := []string{p.datasource, table, operation}
+	metricDuration.Observe(duration.Milliseconds(), labels...)
+	if p.slowThreshold > 0 && duration > p.slowThreshold {
+		metricSlow.Inc(labels...)
+		span.AddEvent("slow_query", trace.WithAttributes(
+			attribute.Int64("db.duration_ms", duration.Milliseconds()),
+			attribute.Int64("db.slow_threshold_ms", p.slowThreshold.Milliseconds()),
+		))
+	}
+	if err := tx.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		metricErrors.Inc(labels...)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// collectPoolStats 定时采集连接池指标，直到插件停止
+func (p *telemetryPlugin) collectPoolStats(stats func() sql.DBStats) {
+	ticker := time.NewTicker(poolStatsInterval)
+	defer ticker.Stop()
+
+	for {
+		p.recordPoolStats(stats())
+		select {
+		case <-ticker.C:
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// recordPoolStats 记录一次连接池指标
+func (p *telemetryPlugin) recordPoolStats(s sql.DBStats) {
+	metricPool.Set(float64(s.OpenConnections), p.datasource, "open")
+	metricPool.Set(float64(s.InUse), p.datasource, "in_use")
+	metricPool.Set(float64(s.Idle), p.datasource, "idle")
+	metricPool.Set(float64(s.MaxOpenConnections), p.datasource, "max_open")
+	metricPoolWait.Set(float64(s.WaitCount), p.datasource)
+	metricPoolWaitDuration.Set(float64(s.WaitDuration.Milliseconds()), p.datasource)
+}
+
+// stop 停止连接池指标采集
+func (p *telemetryPlugin) stop() {
+	p.stopOnce.Do(func() {
+		close(p.done)
+	})
+}
+
+// sanitizeSQL 将 SQL 中的字符串和数字字面量替换为 ?，避免泄露数据，并限制长度
+func sanitizeSQL(query string) string {
+	query = stringLiteral.ReplaceAllString(query, "?")
+	query = numberLiteral.ReplaceAllString(query, "${1}?")
+	if len(query) > maxStatementLength {
+		query = query[:maxStatementLength]
+	}
+	return query
+}
+
+// sqlOperation 取 SQL 的第一个关键字作为操作类型
+func sqlOperation(query string) string {
+	query = strings.TrimSpace(query)
+	if i := strings.IndexAny(query, " \t\n("); i > 0 {
+		query = query[:i]
+	}
+	return strings.ToUpper(query)
+}
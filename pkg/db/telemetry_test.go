@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestSanitizeSQL 测试 SQL 脱敏
+func TestSanitizeSQL(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT * FROM `tag` WHERE `name` = ? AND id = 42", "SELECT * FROM `tag` WHERE `name` = ? AND id = ?"},
+		{"UPDATE t1 SET note = 'it''s secret', score = 3.5 WHERE id = $1", "UPDATE t1 SET note = ?, score = ? WHERE id = $1"},
+		{"SELECT 1", "SELECT ?"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeSQL(tt.sql); got != tt.want {
+			t.Errorf("sanitizeSQL(%q) = %q, 期望 %q", tt.sql, got, tt.want)
+		}
+	}
+}
+
+// TestTelemetryPlugin 测试每条 SQL 生成客户端 span
+func TestTelemetryPlugin(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	defer provider.Shutdown(context.Background())
+
+	db, err := InitGorm(Config{Name: "test", Driver: DriverSQLite, Database: ":memory:", MaxOpenConns: 1, MaxIdleConns: 1})
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	defer Close(db)
+
+	type Note struct {
+		Id      int64
+		Content string
+	}
+	if err := db.AutoMigrate(&Note{}); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	exporter.Reset()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	db.WithContext(ctx).Create(&Note{Content: "私密内容"})
+	db.WithContext(ctx).Model(&Note{}).Where("id = ?", 1).Update("content", "新内容")
+	var notes []Note
+	db.WithContext(ctx).Where("content = ?", "新内容").Find(&notes)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 4 {
+		t.Fatalf("期望 3 个 SQL span 和 1 个父 span, 实际 %d", len(spans))
+	}
+	wantNames := []string{"INSERT notes", "UPDATE notes", "SELECT notes"}
+	for i, name := range wantNames {
+		span := spans[i]
+		if span.Name != name {
+			t.Errorf("span[%d] 名称期望 %s, 实际 %s", i, name, span.Name)
+		}
+		if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("span[%d] 应属于请求链路", i)
+		}
+		attrs := attribute.NewSet(span.Attributes...)
+		if v, _ := attrs.Value("db.rows_affected"); v.AsInt64() != 1 {
+			t.Errorf("span[%d] 影响行数期望 1, 实际 %d", i, v.AsInt64())
+		}
+		if v, _ := attrs.Value("db.name"); v.AsString() != "test" {
+			t.Errorf("span[%d] 数据源期望 test, 实际 %s", i, v.AsString())
+		}
+	}
+
+	// 超过慢查询阈值时记录事件
+	db.Config.Plugins[telemetryPluginName].(*telemetryPlugin).slowThreshold = time.Nanosecond
+	exporter.Reset()
+	db.Find(&notes)
+	spans = exporter.GetSpans()
+	if len(spans) != 1 || len(spans[0].Events) != 1 || spans[0].Events[0].Name != "slow_query" {
+		t.Errorf("期望记录慢查询事件, 实际 %+v", spans)
+	}
+}
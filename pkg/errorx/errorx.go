@@ -21,6 +21,8 @@ const (
 	ErrCodeAlreadyExists   = 30002
 	ErrCodePermissionDeny  = 30003
 	ErrCodeOperationFailed = 30004
+	ErrCodeIdempotencyBusy = 30005
+	ErrCodeIdempotencyKey  = 30006
 
 	// 认证授权错误 (40000-49999)
 	ErrCodeAuth         = 40000
@@ -28,6 +30,7 @@ const (
 	ErrCodeTokenExpired = 40002
 	ErrCodeUnauthorized = 40003
 	ErrCodeForbidden    = 40004
+	ErrCodeRateLimited  = 40005
 )
 
 // 错误消息映射
@@ -48,12 +51,15 @@ var errMsgMap = map[int]string{
 	ErrCodeAlreadyExists:   "数据已存在",
 	ErrCodePermissionDeny:  "权限不足",
 	ErrCodeOperationFailed: "操作失败",
+	ErrCodeIdempotencyBusy: "相同幂等键的请求正在处理中，请稍后重试",
+	ErrCodeIdempotencyKey:  "幂等键已用于不同的请求",
 
 	ErrCodeAuth:         "认证失败",
 	ErrCodeTokenInvalid: "Token无效",
 	ErrCodeTokenExpired: "Token已过期",
 	ErrCodeUnauthorized: "未登录",
 	ErrCodeForbidden:    "禁止访问",
+	ErrCodeRateLimited:  "请求过于频繁，请稍后重试",
 }
 
 // CodeError 业务错误
@@ -0,0 +1,63 @@
+package health
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// 构建信息，通过 -ldflags 注入，例如：
+//
+//	go build -ldflags "-X idrm/pkg/health.Version=1.2.0 -X idrm/pkg/health.Commit=$(git rev-parse HEAD)"
+//
+// 未注入时 Commit 和 BuildTime 取自 Go 工具链记录的版本控制信息
+var (
+	Version   string
+	Commit    string
+	BuildTime string
+)
+
+// BuildInfo 构建信息
+type BuildInfo struct {
+	Service     string `json:"service"`
+	Version     string `json:"version"`
+	Environment string `json:"environment,omitempty"`
+	Commit      string `json:"commit,omitempty"`
+	BuildTime   string `json:"buildTime,omitempty"`
+	Modified    bool   `json:"modified,omitempty"` // 构建时工作区有未提交的修改
+	GoVersion   string `json:"goVersion"`
+}
+
+// NewBuildInfo 生成构建信息，注入的 Version 优先于配置的版本号
+func NewBuildInfo(service, version, environment string) BuildInfo {
+	info := BuildInfo{
+		Service:     service,
+		Version:     version,
+		Environment: environment,
+		Commit:      Commit,
+		BuildTime:   BuildTime,
+		GoVersion:   runtime.Version(),
+	}
+	if Version != "" {
+		info.Version = Version
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.BuildTime == "" {
+				info.BuildTime = s.Value
+			}
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
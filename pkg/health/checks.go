@@ -0,0 +1,82 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"idrm/pkg/db"
+	"idrm/pkg/telemetry/audit"
+	"idrm/pkg/telemetry/trace"
+
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// DataSources 为每个已配置的数据源生成 ping 检查，检查名为 db:<数据源名称>
+// required 中的数据源为必需检查，其余数据源为可选检查；尚未连接的数据源在检查时建立连接
+func DataSources(registry *db.Registry, required ...string) []Check {
+	var checks []Check
+	for _, name := range registry.Names() {
+		checks = append(checks, Check{
+			Name:     "db:" + name,
+			Optional: !slices.Contains(required, name),
+			Check: func(ctx context.Context) error {
+				return registry.Check(ctx, name)
+			},
+		})
+	}
+	return checks
+}
+
+// Redis 检查 Redis 连接，缓存不可用时回源数据库，因此为可选检查
+func Redis(rds *redis.Redis) Check {
+	return Check{
+		Name:     "redis",
+		Optional: true,
+		Check: func(ctx context.Context) error {
+			if !rds.PingCtx(ctx) {
+				return errors.New("Redis ping 失败")
+			}
+			return nil
+		},
+	}
+}
+
+// TraceExporter 检查链路追踪导出器，window 内出现过导出失败时检查失败
+// 导出失败只丢失链路数据，因此为可选检查；未启用链路追踪时始终通过
+func TraceExporter(window time.Duration) Check {
+	return Check{
+		Name:     "trace-exporter",
+		Optional: true,
+		Check: func(ctx context.Context) error {
+			if !trace.Enabled() {
+				return nil
+			}
+			at, err := trace.LastExportError()
+			if err != nil && time.Since(at) < window {
+				return fmt.Errorf("链路数据导出失败于 %s: %w", at.Format(time.RFC3339), err)
+			}
+			return nil
+		},
+	}
+}
+
+// AuditBacklog 检查审计日志待发送记录数，超过 max 时检查失败
+// 积压的记录已落盘，存储目标恢复后继续发送，因此为可选检查；未启用审计日志时始终通过
+func AuditBacklog(max int64) Check {
+	return Check{
+		Name:     "audit-backlog",
+		Optional: true,
+		Check: func(ctx context.Context) error {
+			if !audit.IsEnabled() {
+				return nil
+			}
+			if queued := audit.Stats().Queued; queued > max {
+				return fmt.Errorf("审计日志积压 %d 条，超过上限 %d 条", queued, max)
+			}
+			return nil
+		},
+	}
+}
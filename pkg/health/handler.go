@@ -0,0 +1,55 @@
+package health
+
+import (
+	"net/http"
+	"time"
+
+	"idrm/pkg/response"
+)
+
+// startTime 进程启动时间
+var startTime = time.Now()
+
+// StatusReport 服务状态报告
+type StatusReport struct {
+	Report
+	Build     BuildInfo `json:"build"`
+	StartTime time.Time `json:"startTime"`
+	Uptime    string    `json:"uptime"`
+}
+
+// LivenessHandler 存活探针，进程能处理请求即返回 200，不检查依赖，避免依赖故障导致进程被重启
+func LivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response.WriteJSON(w, http.StatusOK, Report{Status: StatusUp})
+	}
+}
+
+// ReadinessHandler 就绪探针，执行所有检查项，状态为 down 时返回 503，degraded 时仍返回 200
+func (c *Checker) ReadinessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context())
+		response.WriteJSON(w, statusCode(report.Status), report)
+	}
+}
+
+// StatusHandler 服务状态，在就绪检查结果的基础上附加构建信息和运行时长
+func (c *Checker) StatusHandler(build BuildInfo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context())
+		response.WriteJSON(w, statusCode(report.Status), StatusReport{
+			Report:    report,
+			Build:     build,
+			StartTime: startTime,
+			Uptime:    time.Since(startTime).Round(time.Second).String(),
+		})
+	}
+}
+
+// statusCode 健康状态对应的 HTTP 状态码
+func statusCode(status Status) int {
+	if status == StatusDown {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,165 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTimeout 检查项未指定超时时间时使用的默认值
+const DefaultTimeout = 3 * time.Second
+
+// ErrDraining 服务正在停止，不再接收新流量
+var ErrDraining = errors.New("服务正在停止")
+
+// Status 健康状态
+type Status string
+
+const (
+	StatusUp       Status = "up"       // 全部检查通过
+	StatusDegraded Status = "degraded" // 可选检查失败，仍可接收流量
+	StatusDown     Status = "down"     // 必需检查失败或服务正在停止，不可接收流量
+)
+
+// Check 依赖检查项
+// 必需检查失败时服务不可用（就绪探针失败），可选检查失败时服务降级（就绪探针仍通过）
+type Check struct {
+	Name     string
+	Optional bool
+	Timeout  time.Duration // 检查超时时间，0 表示使用 Checker 的默认值
+	Check    func(ctx context.Context) error
+}
+
+// Result 单个检查项的结果
+type Result struct {
+	Name      string  `json:"name"`
+	Status    Status  `json:"status"`
+	Optional  bool    `json:"optional,omitempty"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report 检查报告
+type Report struct {
+	Status Status   `json:"status"`
+	Error  string   `json:"error,omitempty"`
+	Checks []Result `json:"checks,omitempty"`
+}
+
+// Checker 依赖健康检查器
+type Checker struct {
+	mu       sync.RWMutex
+	checks   []Check
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// New 创建健康检查器，timeout 为检查项未指定超时时的默认值
+func New(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{timeout: timeout}
+}
+
+// Register 注册检查项，报告中按注册顺序排列
+func (c *Checker) Register(checks ...Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, checks...)
+}
+
+// Drain 标记服务正在停止，之后就绪检查始终失败，使负载均衡在停止接收请求前摘除实例
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Draining 服务是否正在停止
+func (c *Checker) Draining() bool {
+	return c.draining.Load()
+}
+
+// Run 并发执行所有检查项
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := append([]Check(nil), c.checks...)
+	c.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{Status: aggregate(results), Checks: results}
+	if c.Draining() {
+		report.Status = StatusDown
+		report.Error = ErrDraining.Error()
+	}
+	return report
+}
+
+// run 在超时时间内执行检查项，超时后不再等待检查返回
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = c.timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("panic: %v", p)
+			}
+		}()
+		done <- check.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("检查超时: %w", ctx.Err())
+	}
+
+	result := Result{
+		Name:      check.Name,
+		Status:    StatusUp,
+		Optional:  check.Optional,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		if check.Optional {
+			result.Status = StatusDegraded
+		}
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// aggregate 汇总检查结果：任一必需检查失败为 down，任一可选检查失败为 degraded
+func aggregate(results []Result) Status {
+	status := StatusUp
+	for _, r := range results {
+		switch r.Status {
+		case StatusDown:
+			return StatusDown
+		case StatusDegraded:
+			status = StatusDegraded
+		}
+	}
+	return status
+}
@@ -0,0 +1,175 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"idrm/pkg/db"
+)
+
+// check 返回固定结果的检查项
+func check(name string, optional bool, err error) Check {
+	return Check{
+		Name:     name,
+		Optional: optional,
+		Check:    func(ctx context.Context) error { return err },
+	}
+}
+
+func TestChecker_Run(t *testing.T) {
+	boom := errors.New("boom")
+	tests := []struct {
+		name   string
+		checks []Check
+		want   Status
+	}{
+		{"全部通过", []Check{check("db", false, nil), check("redis", true, nil)}, StatusUp},
+		{"可选检查失败", []Check{check("db", false, nil), check("redis", true, boom)}, StatusDegraded},
+		{"必需检查失败", []Check{check("db", false, boom), check("redis", true, boom)}, StatusDown},
+		{"无检查项", nil, StatusUp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(time.Second)
+			c.Register(tt.checks...)
+			report := c.Run(context.Background())
+			if report.Status != tt.want {
+				t.Errorf("Status = %s, want %s", report.Status, tt.want)
+			}
+			if len(report.Checks) != len(tt.checks) {
+				t.Fatalf("Checks = %d, want %d", len(report.Checks), len(tt.checks))
+			}
+			for i, r := range report.Checks {
+				if r.Name != tt.checks[i].Name {
+					t.Errorf("Checks[%d] = %s, 应按注册顺序排列", i, r.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestChecker_TimeoutAndPanic(t *testing.T) {
+	c := New(time.Second)
+	c.Register(
+		Check{
+			Name:    "stuck",
+			Timeout: 20 * time.Millisecond,
+			Check: func(ctx context.Context) error {
+				select {}
+			},
+		},
+		Check{
+			Name:     "broken",
+			Optional: true,
+			Check: func(ctx context.Context) error {
+				panic("boom")
+			},
+		},
+	)
+
+	start := time.Now()
+	report := c.Run(context.Background())
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("检查耗时 %v，未按超时时间放弃等待", time.Since(start))
+	}
+	if report.Status != StatusDown {
+		t.Errorf("Status = %s, want down", report.Status)
+	}
+	if r := report.Checks[0]; r.Status != StatusDown || r.Error == "" || r.LatencyMs < 20 {
+		t.Errorf("stuck = %+v, want down with timeout error", r)
+	}
+	if r := report.Checks[1]; r.Status != StatusDegraded || r.Error != "panic: boom" {
+		t.Errorf("broken = %+v, want degraded with panic error", r)
+	}
+}
+
+func TestDataSources(t *testing.T) {
+	registry := db.NewRegistry()
+	dir := t.TempDir()
+	registry.Register(db.DataSourceDefault, db.Config{Driver: db.DriverSQLite, Database: filepath.Join(dir, "default.db")})
+	registry.Register(db.DataSourceDataView, db.Config{Driver: db.DriverSQLite, Database: filepath.Join(dir, "missing", "data_view.db")})
+	defer registry.Close()
+
+	c := New(time.Second)
+	c.Register(DataSources(registry, db.DataSourceDefault)...)
+	report := c.Run(context.Background())
+
+	// 业务数据源不可用时降级，默认数据源可用时仍可接收流量
+	if report.Status != StatusDegraded {
+		t.Errorf("Status = %s, want degraded: %+v", report.Status, report.Checks)
+	}
+	results := map[string]Result{}
+	for _, r := range report.Checks {
+		results[r.Name] = r
+	}
+	if r := results["db:"+db.DataSourceDefault]; r.Status != StatusUp || r.Optional {
+		t.Errorf("default = %+v, want required and up", r)
+	}
+	if r := results["db:"+db.DataSourceDataView]; r.Status != StatusDegraded || r.Error == "" {
+		t.Errorf("data_view = %+v, want degraded", r)
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	c := New(time.Second)
+	c.Register(check("db", false, nil), check("redis", true, errors.New("boom")))
+
+	serve := func(h http.HandlerFunc) (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		var body map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("响应不是 JSON: %s", rec.Body.String())
+		}
+		return rec.Code, body
+	}
+
+	if code, body := serve(LivenessHandler()); code != http.StatusOK || body["status"] != "up" {
+		t.Errorf("healthz = %d %v", code, body)
+	}
+
+	// 可选检查失败时仍就绪
+	if code, body := serve(c.ReadinessHandler()); code != http.StatusOK || body["status"] != "degraded" {
+		t.Errorf("readyz = %d %v", code, body)
+	}
+
+	code, body := serve(c.StatusHandler(NewBuildInfo("idrm-api", "1.0.0", "test")))
+	build, _ := body["build"].(map[string]any)
+	if code != http.StatusOK || build["service"] != "idrm-api" || build["goVersion"] == "" || body["uptime"] == nil {
+		t.Errorf("status = %d %v", code, body)
+	}
+	if checks, _ := body["checks"].([]any); len(checks) != 2 {
+		t.Errorf("status checks = %v, want 2", body["checks"])
+	}
+
+	// 停止时就绪探针失败，存活探针不受影响
+	c.Drain()
+	if code, body := serve(c.ReadinessHandler()); code != http.StatusServiceUnavailable || body["status"] != "down" {
+		t.Errorf("readyz while draining = %d %v", code, body)
+	}
+	if code, _ := serve(LivenessHandler()); code != http.StatusOK {
+		t.Errorf("healthz while draining = %d", code)
+	}
+}
+
+func TestChecker_Hook(t *testing.T) {
+	c := New(time.Second)
+	hook := c.Hook(30 * time.Millisecond)
+
+	start := time.Now()
+	if err := hook.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if !c.Draining() {
+		t.Error("停止后应标记为正在停止")
+	}
+	if time.Since(start) < 30*time.Millisecond {
+		t.Errorf("停止耗时 %v，未等待摘除流量", time.Since(start))
+	}
+}
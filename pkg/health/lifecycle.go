@@ -0,0 +1,28 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"idrm/pkg/lifecycle"
+)
+
+// Hook 返回就绪状态的生命周期钩子，应注册在 HTTP 服务之后，使其先于 HTTP 服务停止
+// Stop 标记服务正在停止并等待 delay，使负载均衡在 HTTP 服务停止接收请求前摘除实例
+func (c *Checker) Hook(delay time.Duration) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name:    "readiness",
+		Timeout: delay + time.Second,
+		Stop: func(ctx context.Context) error {
+			c.Drain()
+			if delay <= 0 {
+				return nil
+			}
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	}
+}
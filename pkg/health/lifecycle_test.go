@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"idrm/pkg/lifecycle"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/service"
+	"github.com/zeromicro/go-zero/rest"
+)
+
+// TestHook_SIGTERM 测试收到 SIGTERM 后就绪探针先返回 503，等待 delay 后才关闭监听
+func TestHook_SIGTERM(t *testing.T) {
+	// 持续接管 SIGTERM，go-zero 在强制退出时间后会向进程再次发送信号，不能让它结束测试进程
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("获取端口失败: %v", err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	server := rest.MustNewServer(rest.RestConf{
+		ServiceConf: service.ServiceConf{Name: "health-test", Log: logx.LogConf{Mode: "console", Level: "error"}},
+		Host:        "127.0.0.1",
+		Port:        port,
+		Timeout:     5000,
+	})
+	checker := New(time.Second)
+	server.AddRoute(rest.Route{Method: http.MethodGet, Path: "/readyz", Handler: checker.ReadinessHandler()})
+
+	const delay = 2 * time.Second
+	m := lifecycle.New(time.Second)
+	m.Append(lifecycle.RestServer(m, server, time.Second), checker.Hook(delay))
+	done := make(chan error, 1)
+	go func() { done <- m.Run(context.Background()) }()
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/readyz", port)
+	ready := false
+	for i := 0; i < 100 && !ready; i++ {
+		if resp, err := http.Get(url); err == nil {
+			resp.Body.Close()
+			ready = resp.StatusCode == http.StatusOK
+		}
+		if !ready {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if !ready {
+		t.Fatal("服务未就绪")
+	}
+
+	start := time.Now()
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("发送信号失败: %v", err)
+	}
+
+	// 监听关闭前就绪探针应返回 503
+	draining := false
+	for time.Since(start) < 5*time.Second {
+		resp, err := http.Get(url)
+		if err != nil {
+			break
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusServiceUnavailable {
+			draining = true
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	closedAfter := time.Since(start)
+
+	if !draining {
+		t.Error("监听关闭前就绪探针未返回 503")
+	}
+	if closedAfter < delay {
+		t.Errorf("监听在 %v 后关闭，未等待摘除时间 %v", closedAfter.Round(time.Millisecond), delay)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("服务未退出")
+	}
+}
@@ -0,0 +1,147 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"idrm/pkg/db"
+	"idrm/pkg/lifecycle"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errAcquireConflict 并发占用同一幂等键
+var errAcquireConflict = errors.New("占用幂等键失败: 并发冲突")
+
+// DBStore 数据库幂等键存储，过期记录由 Cleaner 定期删除
+// 所有语句都走主库，读副本在复制延迟期间查不到刚写入的记录，重试会被当作新请求再次执行
+type DBStore struct {
+	db *gorm.DB
+}
+
+// NewDBStore 创建数据库幂等键存储
+func NewDBStore(db *gorm.DB) *DBStore {
+	return &DBStore{db: db}
+}
+
+// primary 返回走主库的会话
+func (s *DBStore) primary(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(db.WithPrimary(ctx))
+}
+
+// Acquire 占用幂等键，已过期的记录删除后重新占用
+func (s *DBStore) Acquire(ctx context.Context, rec *Record) (*Record, error) {
+	for i := 0; i < 2; i++ {
+		res := s.primary(ctx).
+			Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "key_hash"}}, DoNothing: true}).
+			Create(rec)
+		if res.Error != nil {
+			return nil, fmt.Errorf("写入幂等键失败: %w", res.Error)
+		}
+		if res.RowsAffected == 1 {
+			return nil, nil
+		}
+
+		var existing Record
+		err := s.primary(ctx).Where("key_hash = ?", rec.Key).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("查询幂等键失败: %w", err)
+		}
+		if existing.ExpiresAt.After(time.Now()) {
+			return &existing, nil
+		}
+
+		// 已过期，删除后重新占用，并发请求中只有一个能占用成功
+		err = s.primary(ctx).
+			Where("key_hash = ? AND expires_at <= ?", rec.Key, time.Now()).
+			Delete(&Record{}).Error
+		if err != nil {
+			return nil, fmt.Errorf("删除过期幂等键失败: %w", err)
+		}
+	}
+	return nil, errAcquireConflict
+}
+
+// Complete 保存请求的响应
+func (s *DBStore) Complete(ctx context.Context, rec *Record) error {
+	err := s.primary(ctx).Model(&Record{}).
+		Where("key_hash = ?", rec.Key).
+		Updates(map[string]interface{}{
+			"status":     rec.Status,
+			"header":     rec.Header,
+			"body":       rec.Body,
+			"expires_at": rec.ExpiresAt,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("保存幂等响应失败: %w", err)
+	}
+	return nil
+}
+
+// Release 删除处理中的记录
+func (s *DBStore) Release(ctx context.Context, key string) error {
+	err := s.primary(ctx).
+		Where("key_hash = ? AND status = ?", key, statusProcessing).
+		Delete(&Record{}).Error
+	if err != nil {
+		return fmt.Errorf("释放幂等键失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired 删除已过期的记录，返回删除的记录数
+func (s *DBStore) DeleteExpired(ctx context.Context) (int64, error) {
+	res := s.primary(ctx).Where("expires_at <= ?", time.Now()).Delete(&Record{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("清理过期幂等键失败: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// Cleaner 返回定期清理过期记录的生命周期钩子
+func (s *DBStore) Cleaner(interval time.Duration) lifecycle.Hook {
+	var (
+		done = make(chan struct{})
+		wg   sync.WaitGroup
+		once sync.Once
+	)
+
+	return lifecycle.Hook{
+		Name: "idempotency-cleaner",
+		Start: func(ctx context.Context) error {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+					}
+					n, err := s.DeleteExpired(context.Background())
+					if err != nil {
+						logx.Error(err)
+					} else if n > 0 {
+						logx.Infof("已清理过期幂等键 %d 条", n)
+					}
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			once.Do(func() { close(done) })
+			wg.Wait()
+			return nil
+		},
+	}
+}
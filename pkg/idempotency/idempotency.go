@@ -0,0 +1,260 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"idrm/pkg/errorx"
+	"idrm/pkg/operator"
+	"idrm/pkg/response"
+	"idrm/pkg/telemetry/metrics"
+	"idrm/pkg/tenant"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// Header 请求头，客户端为每个逻辑请求生成唯一值，重试时携带相同的值
+	Header = "Idempotency-Key"
+	// ReplayedHeader 响应头，值为 true 表示响应来自首次请求的重放
+	ReplayedHeader = "Idempotent-Replayed"
+	// MaxKeyLength 幂等键最大长度
+	MaxKeyLength = 255
+)
+
+// Guard 幂等键中间件
+// POST/PUT/PATCH/DELETE 请求携带 Idempotency-Key 时，同一主体相同幂等键的首次响应被保存，
+// 重试时直接重放；请求内容不同时返回 422，首次请求处理中时返回 409
+type Guard struct {
+	store        Store
+	ttl          time.Duration
+	lockTimeout  time.Duration
+	maxBodyBytes int
+}
+
+// NewGuard 创建幂等键中间件
+func NewGuard(c Config, store Store) *Guard {
+	return &Guard{
+		store:        store,
+		ttl:          time.Duration(c.TTL) * time.Second,
+		lockTimeout:  time.Duration(c.LockTimeout) * time.Second,
+		maxBodyBytes: c.MaxBodyBytes,
+	}
+}
+
+// Handle 幂等键中间件，需在认证中间件之后执行以识别请求主体
+// 存储不可用时按普通请求处理，避免幂等键故障导致接口不可用
+func (g *Guard) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(Header)
+		if key == "" || !mutating(r.Method) {
+			next(w, r)
+			return
+		}
+		if !validKey(key) {
+			writeError(w, r, http.StatusBadRequest, errorx.NewWithMsg(errorx.ErrCodeParamInvalid, "Idempotency-Key 格式错误"))
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, errorx.NewWithMsg(errorx.ErrCodeParamInvalid, "读取请求体失败"))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		rec := &Record{
+			Key:         scopedKey(r, key),
+			RequestHash: requestHash(r, body),
+			ExpiresAt:   time.Now().Add(g.lockTimeout),
+		}
+		existing, err := g.store.Acquire(r.Context(), rec)
+		if err != nil {
+			logx.WithContext(r.Context()).Errorf("幂等键不可用，按普通请求处理: %v", err)
+			next(w, r)
+			return
+		}
+		if existing != nil {
+			g.replay(w, r, rec, existing)
+			return
+		}
+
+		g.execute(w, r, next, rec)
+	}
+}
+
+// execute 处理首次请求并保存响应，5xx 或 panic 时释放幂等键使重试可以重新执行
+func (g *Guard) execute(w http.ResponseWriter, r *http.Request, next http.HandlerFunc, rec *Record) {
+	ctx := context.WithoutCancel(r.Context())
+	saved := false
+	defer func() {
+		if saved {
+			return
+		}
+		if err := g.store.Release(ctx, rec.Key); err != nil {
+			logx.WithContext(ctx).Error(err)
+		}
+	}()
+
+	before := w.Header().Clone()
+	cw := &captureWriter{ResponseWriter: w, status: http.StatusOK, limit: g.maxBodyBytes}
+	next(cw, r)
+
+	if cw.status >= http.StatusInternalServerError {
+		return
+	}
+	if cw.overflow {
+		logx.WithContext(ctx).Infof("响应体超过 %d 字节，不保存幂等响应", g.maxBodyBytes)
+		return
+	}
+
+	header, err := json.Marshal(changedHeader(before, w.Header()))
+	if err != nil {
+		logx.WithContext(ctx).Errorf("序列化响应头失败: %v", err)
+		return
+	}
+	rec.Status = cw.status
+	rec.Header = string(header)
+	rec.Body = cw.body.String()
+	rec.ExpiresAt = time.Now().Add(g.ttl)
+	if err := g.store.Complete(ctx, rec); err != nil {
+		logx.WithContext(ctx).Error(err)
+		return
+	}
+	saved = true
+}
+
+// replay 重放首次请求的响应
+func (g *Guard) replay(w http.ResponseWriter, r *http.Request, rec, existing *Record) {
+	if existing.RequestHash != rec.RequestHash {
+		writeError(w, r, http.StatusUnprocessableEntity, errorx.NewWithCode(errorx.ErrCodeIdempotencyKey))
+		return
+	}
+	if !existing.Completed() {
+		w.Header().Set("Retry-After", "1")
+		writeError(w, r, http.StatusConflict, errorx.NewWithCode(errorx.ErrCodeIdempotencyBusy))
+		return
+	}
+
+	var header http.Header
+	if existing.Header != "" {
+		if err := json.Unmarshal([]byte(existing.Header), &header); err != nil {
+			logx.WithContext(r.Context()).Errorf("解析幂等响应头失败: %v", err)
+		}
+	}
+	for name, values := range header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(existing.Status)
+	io.WriteString(w, existing.Body)
+}
+
+// writeError 返回 errorx 错误响应
+func writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	codeErr := err.(*errorx.CodeError)
+	metrics.RecordError(r.Context(), codeErr)
+	response.WriteJSON(w, status, &response.HttpResponse{
+		Code: codeErr.GetCode(),
+		Msg:  codeErr.GetMsg(),
+	})
+}
+
+// mutating 是否为需要幂等保护的请求方法
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// validKey 幂等键由可见 ASCII 字符组成且不超过 MaxKeyLength
+func validKey(key string) bool {
+	if len(key) > MaxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// scopedKey 按租户和用户隔离幂等键，未识别用户时按客户端IP
+func scopedKey(r *http.Request, key string) string {
+	ctx := r.Context()
+	var principal string
+	if userID, ok := operator.FromContext(ctx); ok {
+		principal = "user:" + strconv.FormatInt(userID, 10)
+	} else if ip := operator.ClientIP(ctx); ip != "" {
+		principal = "ip:" + ip
+	} else {
+		principal = "ip:" + operator.RemoteIP(r)
+	}
+	tenantID, _ := tenant.FromContext(ctx)
+	return digest(tenantID, principal, key)
+}
+
+// requestHash 请求内容摘要，相同幂等键的请求方法、路径和请求体必须一致
+func requestHash(r *http.Request, body []byte) string {
+	return digest(r.Method, r.URL.RequestURI(), string(body))
+}
+
+// digest 计算各部分的 sha256 摘要
+func digest(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(strconv.Itoa(len(p))))
+		h.Write([]byte{':'})
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// changedHeader 返回业务处理新增或修改的响应头，外层中间件设置的响应头（如请求ID）不保存
+func changedHeader(before, after http.Header) http.Header {
+	changed := make(http.Header)
+	for name, values := range after {
+		if !slices.Equal(before[name], values) {
+			changed[name] = values
+		}
+	}
+	return changed
+}
+
+// captureWriter 记录响应状态码和响应体
+type captureWriter struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (w *captureWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	if !w.overflow {
+		if w.body.Len()+len(b) > w.limit {
+			w.overflow = true
+			w.body.Reset()
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
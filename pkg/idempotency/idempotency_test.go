@@ -0,0 +1,291 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"idrm/pkg/db"
+	"idrm/pkg/errorx"
+	"idrm/pkg/operator"
+	"idrm/pkg/tenant"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var testConfig = Config{TTL: 3600, LockTimeout: 60, MaxBodyBytes: 1024}
+
+// stores 返回各存储后端
+func stores(t *testing.T) map[string]Store {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法创建测试数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&Record{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+	mr := miniredis.RunT(t)
+	return map[string]Store{
+		BackendDB:    NewDBStore(db),
+		BackendRedis: NewRedisStore(redis.New(mr.Addr()), "test:idempotency"),
+	}
+}
+
+// request 创建携带幂等键的请求
+func request(method, path, body, key string, userID int64) *http.Request {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(Header, key)
+	}
+	ctx := tenant.WithTenant(r.Context(), "t1")
+	ctx = operator.WithOperator(ctx, userID)
+	return r.WithContext(ctx)
+}
+
+// createHandler 模拟创建标签，每次执行返回递增的ID
+func createHandler(calls *atomic.Int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		id := calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/api/v1/tags/"+strconv.FormatInt(id, 10))
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{"id": id, "req": string(body)})
+	}
+}
+
+// errorCode 解析 errorx 错误码
+func errorCode(rec *httptest.ResponseRecorder) int {
+	var body struct {
+		Code int `json:"code"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	return body.Code
+}
+
+func TestGuard_Replay(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			var calls atomic.Int64
+			h := NewGuard(testConfig, store).Handle(createHandler(&calls))
+
+			first := httptest.NewRecorder()
+			first.Header().Set("X-Request-ID", "req-1")
+			h(first, request(http.MethodPost, "/api/v1/tags", `{"name":"a"}`, "key-1", 1))
+
+			retry := httptest.NewRecorder()
+			retry.Header().Set("X-Request-ID", "req-2")
+			h(retry, request(http.MethodPost, "/api/v1/tags", `{"name":"a"}`, "key-1", 1))
+
+			if calls.Load() != 1 {
+				t.Fatalf("重试不应重新执行, calls=%d", calls.Load())
+			}
+			if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+				t.Errorf("重放响应 = %d %s, want %d %s", retry.Code, retry.Body.String(), first.Code, first.Body.String())
+			}
+			if retry.Header().Get(ReplayedHeader) != "true" || retry.Header().Get("Location") != first.Header().Get("Location") {
+				t.Errorf("重放响应头 = %v", retry.Header())
+			}
+			// 外层中间件设置的响应头不重放
+			if got := retry.Header().Get("X-Request-ID"); got != "req-2" {
+				t.Errorf("X-Request-ID = %s, want req-2", got)
+			}
+
+			// 不同用户、不同幂等键或未携带幂等键时正常执行
+			h(httptest.NewRecorder(), request(http.MethodPost, "/api/v1/tags", `{"name":"a"}`, "key-1", 2))
+			h(httptest.NewRecorder(), request(http.MethodPost, "/api/v1/tags", `{"name":"a"}`, "key-2", 1))
+			h(httptest.NewRecorder(), request(http.MethodPost, "/api/v1/tags", `{"name":"a"}`, "", 1))
+			if calls.Load() != 4 {
+				t.Errorf("calls = %d, want 4", calls.Load())
+			}
+
+			// PATCH 同样重放
+			for i := 0; i < 2; i++ {
+				h(httptest.NewRecorder(), request(http.MethodPatch, "/api/v1/tags/1", `{"name":"b"}`, "key-3", 1))
+			}
+			if calls.Load() != 5 {
+				t.Errorf("PATCH 重试不应重新执行, calls = %d, want 5", calls.Load())
+			}
+		})
+	}
+}
+
+func TestGuard_Mismatch(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			var calls atomic.Int64
+			h := NewGuard(testConfig, store).Handle(createHandler(&calls))
+			h(httptest.NewRecorder(), request(http.MethodPost, "/api/v1/tags", `{"name":"a"}`, "key-1", 1))
+
+			// 相同幂等键的请求体或路径不同时拒绝
+			for _, r := range []*http.Request{
+				request(http.MethodPost, "/api/v1/tags", `{"name":"b"}`, "key-1", 1),
+				request(http.MethodPut, "/api/v1/tags/1", `{"name":"a"}`, "key-1", 1),
+			} {
+				rec := httptest.NewRecorder()
+				h(rec, r)
+				if rec.Code != http.StatusUnprocessableEntity || errorCode(rec) != errorx.ErrCodeIdempotencyKey {
+					t.Errorf("%s %s = %d %s, want 422", r.Method, r.URL.Path, rec.Code, rec.Body.String())
+				}
+			}
+			if calls.Load() != 1 {
+				t.Errorf("calls = %d, want 1", calls.Load())
+			}
+		})
+	}
+}
+
+func TestGuard_InProgress(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			var calls atomic.Int64
+			guard := NewGuard(testConfig, store)
+			var inner *httptest.ResponseRecorder
+			var h http.HandlerFunc
+			h = guard.Handle(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				// 首次请求处理中收到重试
+				inner = httptest.NewRecorder()
+				h(inner, request(http.MethodDelete, "/api/v1/tags/1", "", "key-1", 1))
+				w.WriteHeader(http.StatusOK)
+			})
+
+			h(httptest.NewRecorder(), request(http.MethodDelete, "/api/v1/tags/1", "", "key-1", 1))
+			if inner.Code != http.StatusConflict || errorCode(inner) != errorx.ErrCodeIdempotencyBusy || inner.Header().Get("Retry-After") == "" {
+				t.Errorf("处理中的重试 = %d %s, want 409", inner.Code, inner.Body.String())
+			}
+			if calls.Load() != 1 {
+				t.Errorf("calls = %d, want 1", calls.Load())
+			}
+		})
+	}
+}
+
+func TestGuard_Release(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			var calls atomic.Int64
+			h := NewGuard(testConfig, store).Handle(func(w http.ResponseWriter, r *http.Request) {
+				switch calls.Add(1) {
+				case 1:
+					w.WriteHeader(http.StatusInternalServerError)
+				case 2:
+					panic("boom")
+				default:
+					w.WriteHeader(http.StatusOK)
+				}
+			})
+			serve := func() {
+				defer func() { recover() }()
+				h(httptest.NewRecorder(), request(http.MethodPost, "/api/v1/resources/tags/assign", `{}`, "key-1", 1))
+			}
+
+			// 5xx 和 panic 后释放幂等键，重试重新执行；成功后不再执行
+			for i := 0; i < 4; i++ {
+				serve()
+			}
+			if calls.Load() != 3 {
+				t.Errorf("calls = %d, want 3", calls.Load())
+			}
+		})
+	}
+}
+
+func TestGuard_Skip(t *testing.T) {
+	var calls atomic.Int64
+	h := NewGuard(testConfig, stores(t)[BackendDB]).Handle(createHandler(&calls))
+
+	// GET 请求不做幂等处理
+	for i := 0; i < 2; i++ {
+		h(httptest.NewRecorder(), request(http.MethodGet, "/api/v1/tags", "", "key-1", 1))
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+
+	// 幂等键格式错误
+	for _, key := range []string{"has space", strings.Repeat("k", MaxKeyLength+1)} {
+		rec := httptest.NewRecorder()
+		h(rec, request(http.MethodPost, "/api/v1/tags", "", key, 1))
+		if rec.Code != http.StatusBadRequest || errorCode(rec) != errorx.ErrCodeParamInvalid {
+			t.Errorf("key %q = %d, want 400", key, rec.Code)
+		}
+	}
+
+	// 响应体超过上限时不保存，重试重新执行
+	large := NewGuard(Config{TTL: 3600, LockTimeout: 60, MaxBodyBytes: 8}, stores(t)[BackendDB]).Handle(createHandler(&calls))
+	calls.Store(0)
+	for i := 0; i < 2; i++ {
+		large(httptest.NewRecorder(), request(http.MethodPost, "/api/v1/tags", `{"name":"a"}`, "key-1", 1))
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+}
+
+func TestDBStore_Expired(t *testing.T) {
+	store := stores(t)[BackendDB].(*DBStore)
+	ctx := context.Background()
+
+	expired := &Record{Key: "k1", RequestHash: "h1", Status: http.StatusOK, ExpiresAt: time.Now().Add(-time.Second)}
+	if existing, err := store.Acquire(ctx, expired); err != nil || existing != nil {
+		t.Fatalf("Acquire() = %v, %v", existing, err)
+	}
+
+	// 过期记录可以被重新占用
+	rec := &Record{Key: "k1", RequestHash: "h2", ExpiresAt: time.Now().Add(time.Minute)}
+	if existing, err := store.Acquire(ctx, rec); err != nil || existing != nil {
+		t.Fatalf("过期后 Acquire() = %+v, %v, want acquired", existing, err)
+	}
+
+	store.Acquire(ctx, &Record{Key: "k2", RequestHash: "h", ExpiresAt: time.Now().Add(-time.Second)})
+	if n, err := store.DeleteExpired(ctx); err != nil || n != 1 {
+		t.Errorf("DeleteExpired() = %d, %v, want 1", n, err)
+	}
+}
+
+// TestDBStore_Replica 测试配置只读副本时，副本复制延迟不影响识别已占用的幂等键
+func TestDBStore_Replica(t *testing.T) {
+	dir := t.TempDir()
+	primary := filepath.Join(dir, "primary.db")
+	replica := filepath.Join(dir, "replica.db")
+	for _, path := range []string{primary, replica} {
+		conn, err := db.InitGorm(db.Config{Driver: db.DriverSQLite, Database: path})
+		if err != nil {
+			t.Fatalf("初始化失败: %v", err)
+		}
+		if err := conn.AutoMigrate(&Record{}); err != nil {
+			t.Fatalf("数据库迁移失败: %v", err)
+		}
+		db.Close(conn)
+	}
+
+	// 副本始终为空，模拟复制延迟
+	conn, err := db.InitGorm(db.Config{Driver: db.DriverSQLite, Database: primary, Replicas: []string{replica}})
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	defer db.Close(conn)
+	store := NewDBStore(conn)
+	ctx := context.Background()
+
+	if existing, err := store.Acquire(ctx, &Record{Key: "k1", RequestHash: "h", ExpiresAt: time.Now().Add(time.Minute)}); err != nil || existing != nil {
+		t.Fatalf("Acquire() = %v, %v", existing, err)
+	}
+	existing, err := store.Acquire(ctx, &Record{Key: "k1", RequestHash: "h", ExpiresAt: time.Now().Add(time.Minute)})
+	if err != nil || existing == nil || existing.RequestHash != "h" {
+		t.Errorf("重试 Acquire() = %+v, %v, want 已占用的记录", existing, err)
+	}
+}
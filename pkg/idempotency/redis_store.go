@@ -0,0 +1,85 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// RedisStore Redis 幂等键存储，记录按 ExpiresAt 设置过期时间
+type RedisStore struct {
+	rds    *redis.Redis
+	prefix string
+}
+
+// NewRedisStore 创建 Redis 幂等键存储，prefix 为键前缀
+func NewRedisStore(rds *redis.Redis, prefix string) *RedisStore {
+	return &RedisStore{rds: rds, prefix: prefix}
+}
+
+// Acquire 占用幂等键
+func (s *RedisStore) Acquire(ctx context.Context, rec *Record) (*Record, error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("序列化幂等键失败: %w", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		ok, err := s.rds.SetnxExCtx(ctx, s.key(rec.Key), string(data), ttlSeconds(rec.ExpiresAt))
+		if err != nil {
+			return nil, fmt.Errorf("写入幂等键失败: %w", err)
+		}
+		if ok {
+			return nil, nil
+		}
+
+		val, err := s.rds.GetCtx(ctx, s.key(rec.Key))
+		if err != nil {
+			return nil, fmt.Errorf("查询幂等键失败: %w", err)
+		}
+		if val == "" {
+			// 读取前已过期，重新占用
+			continue
+		}
+		var existing Record
+		if err := json.Unmarshal([]byte(val), &existing); err != nil {
+			return nil, fmt.Errorf("解析幂等键失败: %w", err)
+		}
+		return &existing, nil
+	}
+	return nil, errAcquireConflict
+}
+
+// Complete 保存请求的响应
+func (s *RedisStore) Complete(ctx context.Context, rec *Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("序列化幂等响应失败: %w", err)
+	}
+	if err := s.rds.SetexCtx(ctx, s.key(rec.Key), string(data), ttlSeconds(rec.ExpiresAt)); err != nil {
+		return fmt.Errorf("保存幂等响应失败: %w", err)
+	}
+	return nil
+}
+
+// Release 删除处理中的记录
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	if _, err := s.rds.DelCtx(ctx, s.key(key)); err != nil {
+		return fmt.Errorf("释放幂等键失败: %w", err)
+	}
+	return nil
+}
+
+// key 返回带前缀的 Redis 键
+func (s *RedisStore) key(key string) string {
+	return s.prefix + ":" + key
+}
+
+// ttlSeconds 距离过期时间的秒数，至少 1 秒
+func ttlSeconds(expiresAt time.Time) int {
+	return max(1, int(math.Ceil(time.Until(expiresAt).Seconds())))
+}
@@ -0,0 +1,59 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// 存储后端
+const (
+	BackendDB    = "db"    // 默认数据源的 idempotency_keys 表
+	BackendRedis = "redis" // Redis，过期记录自动删除
+)
+
+// statusProcessing 处理中的记录状态
+const statusProcessing = 0
+
+// Config 幂等键配置
+type Config struct {
+	Enabled       bool   `json:",optional"`
+	Backend       string `json:",default=db,options=db|redis"` // redis 需配置 Redis
+	Prefix        string `json:",default=idrm:idempotency"`    // Redis 键前缀
+	TTL           int    `json:",default=86400"`               // 响应保存时长(秒)，过期后相同幂等键视为新请求
+	LockTimeout   int    `json:",default=60"`                  // 处理中记录的超时时间(秒)，进程异常退出后允许重试重新执行
+	MaxBodyBytes  int    `json:",default=1048576"`             // 保存的响应体上限(字节)，超过时不保存，重试会重新执行
+	CleanInterval int    `json:",default=3600"`                // db 后端清理过期记录的间隔(秒)
+}
+
+// Record 幂等键记录，Status 为 0 表示请求处理中
+type Record struct {
+	Key         string    `json:"key" gorm:"column:key_hash;type:varchar(64);primaryKey"`
+	RequestHash string    `json:"requestHash" gorm:"column:request_hash;type:varchar(64);not null"`
+	Status      int       `json:"status" gorm:"column:status;not null;default:0"`
+	Header      string    `json:"header" gorm:"column:header;type:text"` // 业务处理设置的响应头(JSON)
+	Body        string    `json:"body" gorm:"column:body;type:text"`
+	ExpiresAt   time.Time `json:"expiresAt" gorm:"column:expires_at;not null;index:idx_idempotency_keys_expires_at"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:created_at;autoCreateTime"`
+}
+
+// TableName 指定表名
+func (Record) TableName() string {
+	return "idempotency_keys"
+}
+
+// Completed 请求是否已处理完成
+func (r *Record) Completed() bool {
+	return r.Status != statusProcessing
+}
+
+// Store 幂等键存储
+type Store interface {
+	// Acquire 占用幂等键：键不存在或已过期时写入处理中记录并返回 nil，否则返回已有记录
+	Acquire(ctx context.Context, rec *Record) (*Record, error)
+
+	// Complete 保存请求的响应，记录在 rec.ExpiresAt 后过期
+	Complete(ctx context.Context, rec *Record) error
+
+	// Release 删除处理中的记录，使重试可以重新执行
+	Release(ctx context.Context, key string) error
+}
@@ -0,0 +1,185 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/proc"
+)
+
+// DefaultStopTimeout 组件未指定停止超时时间时使用的默认值
+const DefaultStopTimeout = 10 * time.Second
+
+// ErrStopTimeout 组件未在超时时间内停止
+var ErrStopTimeout = errors.New("组件停止超时")
+
+// Hook 组件的生命周期钩子
+// Start 不应阻塞，长时间运行的任务在 Start 中启动协程，并在 Stop 中等待其退出
+type Hook struct {
+	Name    string
+	Start   func(ctx context.Context) error // 可选
+	Stop    func(ctx context.Context) error // 可选，ctx 在 Timeout 后取消
+	Timeout time.Duration                   // 停止超时时间，0 表示使用 Manager 的默认值
+}
+
+// Manager 生命周期管理器
+// 组件按注册顺序启动，收到 SIGTERM/SIGINT 或 Run 的 ctx 取消后按注册的逆序停止，
+// 因此应先注册被依赖的组件（遥测、数据库），最后注册 HTTP 服务
+type Manager struct {
+	mu          sync.Mutex
+	hooks       []Hook
+	started     int
+	stopTimeout time.Duration
+	failed      chan error
+	failOnce    sync.Once
+}
+
+// New 创建生命周期管理器，stopTimeout 为组件未指定停止超时时的默认值
+func New(stopTimeout time.Duration) *Manager {
+	if stopTimeout <= 0 {
+		stopTimeout = DefaultStopTimeout
+	}
+	return &Manager{
+		stopTimeout: stopTimeout,
+		failed:      make(chan error, 1),
+	}
+}
+
+// Append 注册组件
+func (m *Manager) Append(hooks ...Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hooks...)
+}
+
+// Fail 报告组件运行失败（如 HTTP 服务异常退出），触发停止流程
+func (m *Manager) Fail(err error) {
+	m.failOnce.Do(func() {
+		m.failed <- err
+	})
+}
+
+// Run 启动所有组件并阻塞，直到收到退出信号、ctx 取消或组件报告失败，然后停止所有组件
+// 启动失败时停止已启动的组件并返回启动错误；停止错误合并后返回
+func (m *Manager) Run(ctx context.Context) error {
+	// go-zero 同样监听退出信号：WrapUpTime 后执行关闭监听（直接关闭 HTTP 服务，绕过就绪摘除和 ShutdownTimeout），
+	// WaitTime 后强制退出进程。两者都延后到所有组件停止之后，由 Manager 按注册的逆序停止；
+	// 需在注册信号监听之前设置，保证 go-zero 的信号处理协程读到新值
+	total := m.totalTimeout()
+	proc.Setup(proc.ShutdownConf{
+		WrapUpTime: total + time.Second,
+		WaitTime:   total + 2*time.Second,
+	})
+
+	sigCtx, stopSignals := signal.NotifyContext(ctx, syscall.SIGTERM, syscall.SIGINT)
+	defer stopSignals()
+
+	if err := m.Start(sigCtx); err != nil {
+		return errors.Join(err, m.Stop(context.Background()))
+	}
+
+	var runErr error
+	select {
+	case <-sigCtx.Done():
+		logx.Info("收到退出信号，开始停止服务")
+	case runErr = <-m.failed:
+		logx.Errorf("组件运行失败，开始停止服务: %v", runErr)
+	}
+
+	// 恢复默认信号处理，停止过程中再次收到信号时立即退出
+	stopSignals()
+
+	return errors.Join(runErr, m.Stop(context.Background()))
+}
+
+// Start 按注册顺序启动组件，遇到错误时停止启动并返回
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for m.started < len(m.hooks) {
+		hook := m.hooks[m.started]
+		if hook.Start != nil {
+			if err := hook.Start(ctx); err != nil {
+				return fmt.Errorf("启动组件 %s 失败: %w", hook.Name, err)
+			}
+		}
+		logx.Infof("组件已启动: %s", hook.Name)
+		m.started++
+	}
+	return nil
+}
+
+// Stop 按注册的逆序停止已启动的组件，单个组件停止失败或超时不影响后续组件
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	for ; m.started > 0; m.started-- {
+		hook := m.hooks[m.started-1]
+		if hook.Stop == nil {
+			continue
+		}
+
+		start := time.Now()
+		if err := m.stopHook(ctx, hook); err != nil {
+			logx.Errorf("停止组件 %s 失败: %v", hook.Name, err)
+			errs = append(errs, fmt.Errorf("停止组件 %s 失败: %w", hook.Name, err))
+			continue
+		}
+		logx.Infof("组件已停止: %s (%v)", hook.Name, time.Since(start).Round(time.Millisecond))
+	}
+	return errors.Join(errs...)
+}
+
+// stopHook 在超时时间内停止组件，超时后不再等待 Stop 返回
+func (m *Manager) stopHook(ctx context.Context, hook Hook) error {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout(hook))
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("panic: %v", p)
+			}
+		}()
+		done <- hook.Stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ErrStopTimeout
+	}
+}
+
+// timeout 返回组件的停止超时时间
+func (m *Manager) timeout(hook Hook) time.Duration {
+	if hook.Timeout > 0 {
+		return hook.Timeout
+	}
+	return m.stopTimeout
+}
+
+// totalTimeout 返回停止所有组件的最长时间
+func (m *Manager) totalTimeout() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var total time.Duration
+	for _, hook := range m.hooks {
+		if hook.Stop != nil {
+			total += m.timeout(hook)
+		}
+	}
+	return total
+}
@@ -0,0 +1,232 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/service"
+	"github.com/zeromicro/go-zero/rest"
+)
+
+// recorder 记录组件的启动和停止顺序
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) hook(name string, startErr error) Hook {
+	return Hook{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			r.add("start " + name)
+			return startErr
+		},
+		Stop: func(ctx context.Context) error {
+			r.add("stop " + name)
+			return nil
+		},
+	}
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func TestManager_Order(t *testing.T) {
+	rec := &recorder{}
+	m := New(time.Second)
+	m.Append(rec.hook("telemetry", nil), rec.hook("db", nil), rec.hook("http", nil))
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("启动失败: %v", err)
+	}
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatalf("停止失败: %v", err)
+	}
+
+	want := []string{"start telemetry", "start db", "start http", "stop http", "stop db", "stop telemetry"}
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+
+	// 重复停止不会再次执行
+	m.Stop(context.Background())
+	if got := rec.get(); len(got) != len(want) {
+		t.Errorf("重复停止后 events = %v", got)
+	}
+}
+
+func TestManager_StartFailure(t *testing.T) {
+	rec := &recorder{}
+	boom := errors.New("boom")
+	m := New(time.Second)
+	m.Append(rec.hook("telemetry", nil), rec.hook("db", boom), rec.hook("http", nil))
+
+	err := m.Run(context.Background())
+	if !errors.Is(err, boom) {
+		t.Fatalf("Run() = %v, want %v", err, boom)
+	}
+
+	// 只停止已启动的组件
+	want := []string{"start telemetry", "start db", "stop telemetry"}
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestManager_StopTimeout(t *testing.T) {
+	rec := &recorder{}
+	m := New(time.Second)
+	m.Append(rec.hook("telemetry", nil))
+	m.Append(Hook{
+		Name:    "stuck",
+		Timeout: 20 * time.Millisecond,
+		Stop: func(ctx context.Context) error {
+			select {}
+		},
+	})
+	m.Append(Hook{
+		Name: "broken",
+		Stop: func(ctx context.Context) error {
+			panic("boom")
+		},
+	})
+
+	m.Start(context.Background())
+	start := time.Now()
+	err := m.Stop(context.Background())
+	if !errors.Is(err, ErrStopTimeout) {
+		t.Errorf("Stop() = %v, want ErrStopTimeout", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("停止耗时 %v，未按组件超时时间放弃等待", time.Since(start))
+	}
+
+	// 前面的组件停止失败不影响后续组件
+	if got := rec.get(); got[len(got)-1] != "stop telemetry" {
+		t.Errorf("events = %v, want telemetry stopped", got)
+	}
+}
+
+func TestManager_Run(t *testing.T) {
+	t.Run("ctx取消", func(t *testing.T) {
+		rec := &recorder{}
+		m := New(time.Second)
+		m.Append(rec.hook("db", nil))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+		if err := m.Run(ctx); err != nil {
+			t.Fatalf("Run() = %v", err)
+		}
+		if got := rec.get(); !reflect.DeepEqual(got, []string{"start db", "stop db"}) {
+			t.Errorf("events = %v", got)
+		}
+	})
+
+	t.Run("组件失败", func(t *testing.T) {
+		rec := &recorder{}
+		boom := errors.New("boom")
+		m := New(time.Second)
+		m.Append(rec.hook("db", nil))
+		m.Append(Hook{
+			Name: "http",
+			Start: func(ctx context.Context) error {
+				go m.Fail(boom)
+				return nil
+			},
+		})
+
+		if err := m.Run(context.Background()); !errors.Is(err, boom) {
+			t.Fatalf("Run() = %v, want %v", err, boom)
+		}
+		if got := rec.get(); !reflect.DeepEqual(got, []string{"start db", "stop db"}) {
+			t.Errorf("events = %v", got)
+		}
+	})
+}
+
+func TestRestServer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("获取端口失败: %v", err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	server := rest.MustNewServer(rest.RestConf{
+		ServiceConf: service.ServiceConf{Name: "lifecycle-test", Log: logx.LogConf{Mode: "console", Level: "error"}},
+		Host:        "127.0.0.1",
+		Port:        port,
+		Timeout:     5000,
+	})
+	entered := make(chan struct{})
+	server.AddRoute(rest.Route{
+		Method: http.MethodGet,
+		Path:   "/slow",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			close(entered)
+			time.Sleep(200 * time.Millisecond)
+			io.WriteString(w, "done")
+		},
+	})
+
+	m := New(time.Second)
+	m.Append(RestServer(m, server, 2*time.Second))
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("启动失败: %v", err)
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/slow", port)
+	result := make(chan string, 1)
+	go func() {
+		// 等待服务开始监听
+		for i := 0; i < 100; i++ {
+			resp, err := http.Get(url)
+			if err == nil {
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				result <- string(body)
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		result <- "unreachable"
+	}()
+
+	select {
+	case <-entered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("请求未到达服务")
+	}
+
+	// 停止时等待处理中的请求完成
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatalf("停止失败: %v", err)
+	}
+	if got := <-result; got != "done" {
+		t.Errorf("处理中的请求结果 = %q, want done", got)
+	}
+
+	// 停止后不再接收新请求
+	if _, err := http.Get(url); err == nil {
+		t.Error("停止后仍接收新请求")
+	}
+}
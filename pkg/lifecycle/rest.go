@@ -0,0 +1,51 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/rest"
+)
+
+// RestServer 返回 go-zero HTTP 服务的生命周期钩子
+// Start 在协程中启动服务，服务异常退出时通过 m.Fail 触发停止流程；
+// Stop 停止接收新请求，并在 timeout 内等待处理中的请求完成
+func RestServer(m *Manager, server *rest.Server, timeout time.Duration) Hook {
+	var (
+		mu  sync.Mutex
+		srv *http.Server
+	)
+
+	return Hook{
+		Name:    "http",
+		Timeout: timeout,
+		Start: func(ctx context.Context) error {
+			go func() {
+				// 启动失败（如端口被占用）时 go-zero 会 panic
+				defer func() {
+					if p := recover(); p != nil {
+						m.Fail(fmt.Errorf("HTTP 服务异常退出: %v", p))
+					}
+				}()
+				server.StartWithOpts(func(s *http.Server) {
+					mu.Lock()
+					srv = s
+					mu.Unlock()
+				})
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			mu.Lock()
+			s := srv
+			mu.Unlock()
+			if s == nil {
+				return nil
+			}
+			return s.Shutdown(ctx)
+		},
+	}
+}
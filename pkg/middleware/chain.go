@@ -0,0 +1,57 @@
+package middleware
+
+import "net/http"
+
+// Middleware names, in chain order
+const (
+	NameRequestID = "requestid"
+	NameTrace     = "trace"
+	NameLogger    = "logger"
+	NameMetrics   = "metrics"
+	NameRecovery  = "recovery"
+)
+
+// ChainConfig selects the global middlewares; their order is fixed by Chain.
+// CORS is not part of the chain, it is set up on the router with CorsOption
+type ChainConfig struct {
+	RequestID bool
+	Trace     bool
+	Logger    bool
+	Metrics   bool
+	Recovery  bool
+}
+
+// Middleware is a named global middleware
+type Middleware struct {
+	Name   string
+	Handle func(http.HandlerFunc) http.HandlerFunc
+}
+
+// Chain assembles the enabled global middlewares, outermost first:
+//
+//  1. RequestID - every later middleware can read the request ID
+//  2. Trace     - joins the upstream trace, the span covers the whole request
+//  3. Logger    - logs with trace ID and the final status, including recovered panics
+//  4. Metrics   - counts the final status and errorx code, including recovered panics
+//  5. Recovery  - turns handler panics into 500 errorx responses seen by the middlewares above;
+//     go-zero's built-in recover handler still guards the middlewares themselves
+func Chain(c ChainConfig) []Middleware {
+	candidates := []struct {
+		enabled bool
+		Middleware
+	}{
+		{c.RequestID, Middleware{NameRequestID, RequestID()}},
+		{c.Trace, Middleware{NameTrace, Trace()}},
+		{c.Logger, Middleware{NameLogger, Logger()}},
+		{c.Metrics, Middleware{NameMetrics, Metrics()}},
+		{c.Recovery, Middleware{NameRecovery, Recovery()}},
+	}
+
+	var chain []Middleware
+	for _, m := range candidates {
+		if m.enabled {
+			chain = append(chain, m.Middleware)
+		}
+	}
+	return chain
+}
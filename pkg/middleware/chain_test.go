@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"idrm/pkg/errorx"
+	"idrm/pkg/telemetry/metrics"
+	"idrm/pkg/telemetry/trace"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/service"
+	"github.com/zeromicro/go-zero/rest"
+	"github.com/zeromicro/go-zero/rest/pathvar"
+)
+
+// allEnabled enables every global middleware
+var allEnabled = ChainConfig{RequestID: true, Trace: true, Logger: true, Metrics: true, Recovery: true}
+
+// chainNames returns the middleware names in chain order
+func chainNames(chain []Middleware) []string {
+	var names []string
+	for _, m := range chain {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+// wrap applies the chain the way rest.Server.Use does: the first middleware is outermost
+func wrap(chain []Middleware, h http.HandlerFunc) http.HandlerFunc {
+	for i := len(chain) - 1; i >= 0; i-- {
+		h = chain[i].Handle(h)
+	}
+	return h
+}
+
+func TestChain_Order(t *testing.T) {
+	want := []string{NameRequestID, NameTrace, NameLogger, NameMetrics, NameRecovery}
+	if got := chainNames(Chain(allEnabled)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Chain() = %v, want %v", got, want)
+	}
+
+	// Disabling middlewares keeps the relative order
+	partial := ChainConfig{Recovery: true, RequestID: true, Metrics: true}
+	want = []string{NameRequestID, NameMetrics, NameRecovery}
+	if got := chainNames(Chain(partial)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Chain() = %v, want %v", got, want)
+	}
+
+	if got := Chain(ChainConfig{}); len(got) != 0 {
+		t.Errorf("Chain() = %v, want empty", chainNames(got))
+	}
+}
+
+// TestChain_Panic checks that a recovered panic is visible to the outer middlewares
+func TestChain_Panic(t *testing.T) {
+	metrics.Init(metrics.MetricsConfig{Enabled: true, Path: "/metrics"})
+
+	var requestID string
+	h := wrap(Chain(allEnabled), func(w http.ResponseWriter, r *http.Request) {
+		requestID = GetRequestID(r.Context())
+		panic("boom")
+	})
+	r := pathvar.WithVars(httptest.NewRequest(http.MethodDelete, "/api/v1/tags/9", nil), map[string]string{"id": "9"})
+	rec := httptest.NewRecorder()
+	h(rec, r)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	var body struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Code != errorx.ErrCodeSystem {
+		t.Errorf("body = %s, want errorx code %d", rec.Body.String(), errorx.ErrCodeSystem)
+	}
+	if requestID == "" || rec.Header().Get("X-Request-ID") != requestID {
+		t.Errorf("X-Request-ID = %q, want %q", rec.Header().Get("X-Request-ID"), requestID)
+	}
+
+	scraped := httptest.NewRecorder()
+	metrics.Handler()(scraped, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	want := `idrm_http_requests_total{code="10000",method="DELETE",route="/api/v1/tags/:id",status="500"}`
+	if !strings.Contains(scraped.Body.String(), want) {
+		t.Errorf("metrics missing %s", want)
+	}
+}
+
+// TestCorsOption checks that preflight requests are answered by the router, not rejected with 405
+func TestCorsOption(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	server := rest.MustNewServer(rest.RestConf{
+		ServiceConf: service.ServiceConf{Log: logx.LogConf{Mode: "console", Level: "error"}},
+		Host:        "127.0.0.1",
+		Port:        port,
+	}, CorsOption([]string{"https://idrm.example.com"}, nil, nil))
+	server.AddRoute(rest.Route{Method: http.MethodPut, Path: "/api/v1/tags/:id", Handler: func(w http.ResponseWriter, r *http.Request) {}})
+	go server.Start()
+	defer server.Stop()
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/api/v1/tags/1", port)
+	preflight := func(origin string) *http.Response {
+		r, _ := http.NewRequest(http.MethodOptions, url, nil)
+		r.Header.Set("Origin", origin)
+		r.Header.Set("Access-Control-Request-Method", http.MethodPut)
+		var resp *http.Response
+		for i := 0; i < 50; i++ {
+			if resp, err = http.DefaultClient.Do(r); err == nil {
+				resp.Body.Close()
+				return resp
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("request failed: %v", err)
+		return nil
+	}
+
+	resp := preflight("https://idrm.example.com")
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://idrm.example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q", got)
+	}
+	if got := resp.Header.Get("Access-Control-Allow-Methods"); !strings.Contains(got, "PATCH") {
+		t.Errorf("Access-Control-Allow-Methods = %q, want default methods", got)
+	}
+	for _, header := range []string{"Idempotency-Key", "If-Match", "X-Change-Reason"} {
+		if got := resp.Header.Get("Access-Control-Allow-Headers"); !strings.Contains(got, header) {
+			t.Errorf("Access-Control-Allow-Headers = %q, want %s", got, header)
+		}
+	}
+	if got := resp.Header.Get("Access-Control-Expose-Headers"); !strings.Contains(got, "ETag") {
+		t.Errorf("Access-Control-Expose-Headers = %q, want ETag", got)
+	}
+
+	// Origins outside the allow list get no CORS headers
+	resp = preflight("https://evil.example.com")
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want empty", got)
+	}
+	if got := resp.Header.Get("Access-Control-Allow-Methods"); got != "" {
+		t.Errorf("Access-Control-Allow-Methods = %q, want empty", got)
+	}
+}
+
+func TestTrace_Propagation(t *testing.T) {
+	if err := trace.Init(trace.TraceConfig{Enabled: true, Batcher: trace.BatcherMemory, Sampler: 1}, "test", "1.0.0", "test"); err != nil {
+		t.Fatalf("trace init failed: %v", err)
+	}
+	defer trace.Close(context.Background())
+
+	h := Trace()(func(w http.ResponseWriter, r *http.Request) {})
+	r := pathvar.WithVars(httptest.NewRequest(http.MethodGet, "/api/v1/tags/5", nil), map[string]string{"id": "5"})
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.Header.Set("X-Forwarded-For", "10.9.9.9")
+	h(httptest.NewRecorder(), r)
+
+	spans := trace.MemoryExporter().GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "GET /api/v1/tags/:id" {
+		t.Errorf("span name = %q", span.Name)
+	}
+	if got := span.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace id = %s, want upstream trace id", got)
+	}
+	if got := span.Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("parent span id = %s, want upstream span id", got)
+	}
+	// Client supplied forwarding headers are not trusted
+	for _, attr := range span.Attributes {
+		if attr.Key == "http.client_ip" && attr.Value.AsString() != "192.0.2.1" {
+			t.Errorf("http.client_ip = %s, want peer address", attr.Value.AsString())
+		}
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		code  int
+	}{
+		{"string", "boom", errorx.ErrCodeSystem},
+		{"errorx", errorx.NewWithCode(errorx.ErrCodeDatabase), errorx.ErrCodeDatabase},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Recovery()(func(w http.ResponseWriter, r *http.Request) { panic(tt.value) })
+			rec := httptest.NewRecorder()
+			h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			var body struct {
+				Code int `json:"code"`
+			}
+			json.Unmarshal(rec.Body.Bytes(), &body)
+			if rec.Code != http.StatusInternalServerError || body.Code != tt.code {
+				t.Errorf("response = %d %s, want 500 code %d", rec.Code, rec.Body.String(), tt.code)
+			}
+		})
+	}
+
+	// http.ErrAbortHandler is re-panicked for net/http to abort the response
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("recover() = %v, want http.ErrAbortHandler", v)
+		}
+	}()
+	Recovery()(func(w http.ResponseWriter, r *http.Request) { panic(http.ErrAbortHandler) })(
+		httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
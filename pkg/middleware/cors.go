@@ -1,6 +1,47 @@
 package middleware
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+
+	"github.com/zeromicro/go-zero/rest"
+)
+
+// exposeHeaders are the response headers readable by browser clients
+const exposeHeaders = "X-Request-ID, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset, Retry-After, Idempotent-Replayed, ETag"
+
+// Default CORS methods and request headers, used when the config leaves them empty
+var (
+	defaultCorsMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"}
+	defaultCorsHeaders = []string{"Content-Type", "Authorization", "X-Request-ID", "Idempotency-Key", "If-Match", "X-Change-Reason"}
+)
+
+// CorsOption enables CORS on the server router rather than as a route middleware,
+// so preflight requests are answered before routing instead of getting a 405.
+// All origins are allowed when origins is empty.
+func CorsOption(origins, methods, headers []string) rest.RunOption {
+	if len(methods) == 0 {
+		methods = defaultCorsMethods
+	}
+	if len(headers) == 0 {
+		headers = defaultCorsHeaders
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+
+	setHeaders := func(header http.Header) {
+		// go-zero only sets Access-Control-Allow-Origin for allowed origins
+		if header.Get("Access-Control-Allow-Origin") == "" {
+			return
+		}
+		header.Set("Access-Control-Allow-Methods", allowMethods)
+		header.Set("Access-Control-Allow-Headers", allowHeaders)
+		header.Set("Access-Control-Expose-Headers", exposeHeaders)
+	}
+	return rest.WithCustomCors(setHeaders, func(w http.ResponseWriter) {
+		setHeaders(w.Header())
+	}, origins...)
+}
 
 // CORS handles Cross-Origin Resource Sharing
 func CORS() func(http.HandlerFunc) http.HandlerFunc {
@@ -9,8 +50,8 @@ func CORS() func(http.HandlerFunc) http.HandlerFunc {
 			// Set CORS headers
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
-			w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, Idempotency-Key, If-Match, X-Change-Reason")
+			w.Header().Set("Access-Control-Expose-Headers", exposeHeaders)
 			w.Header().Set("Access-Control-Max-Age", "86400")
 
 			// Handle preflight request
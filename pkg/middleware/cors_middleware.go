@@ -17,6 +17,7 @@ func CorsMiddleware(allowOrigins, allowMethods, allowHeaders []string) func(http
 
 			w.Header().Set("Access-Control-Allow-Methods", strings.Join(allowMethods, ", "))
 			w.Header().Set("Access-Control-Allow-Headers", strings.Join(allowHeaders, ", "))
+			w.Header().Set("Access-Control-Expose-Headers", exposeHeaders)
 			w.Header().Set("Access-Control-Max-Age", "86400")
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
 
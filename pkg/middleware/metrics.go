@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"idrm/pkg/telemetry/metrics"
+
+	"github.com/zeromicro/go-zero/rest/pathvar"
+)
+
+// Metrics records Prometheus request counters and latency histograms
+// labelled by route template, status code and errorx code.
+// The errorx code is reported by metrics.ErrorHandler, which must be
+// installed via httpx.SetErrorHandlerCtx.
+func Metrics() func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			// Inject metrics state so error handlers can report the errorx code
+			ctx, req := metrics.NewRequestContext(r.Context())
+			r = r.WithContext(ctx)
+
+			// Wrap response writer to capture status code
+			sw := &traceStatusWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			// Execute next handler
+			next(sw, r)
+
+			metrics.ObserveRequest(r.Method, routeTemplate(r), sw.statusCode, req.Code(), time.Since(start))
+		}
+	}
+}
+
+// routeTemplate rebuilds the matched route template (e.g. /api/v1/tags/:id)
+// from the path variables set by the go-zero router
+func routeTemplate(r *http.Request) string {
+	vars := pathvar.Vars(r)
+	if len(vars) == 0 {
+		return r.URL.Path
+	}
+
+	values := make(map[string]string, len(vars))
+	for name, value := range vars {
+		values[value] = name
+	}
+
+	// Match from the end, path variables usually follow static segments
+	segments := strings.Split(r.URL.Path, "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if name, ok := values[segments[i]]; ok {
+			delete(values, segments[i])
+			segments[i] = ":" + name
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"idrm/pkg/errorx"
+	"idrm/pkg/telemetry/metrics"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"github.com/zeromicro/go-zero/rest/pathvar"
+)
+
+func TestRouteTemplate(t *testing.T) {
+	tests := []struct {
+		path string
+		vars map[string]string
+		want string
+	}{
+		{"/api/v1/tags", nil, "/api/v1/tags"},
+		{"/api/v1/tags/42", map[string]string{"id": "42"}, "/api/v1/tags/:id"},
+		{"/api/v1/tags/42/history", map[string]string{"id": "42"}, "/api/v1/tags/:id/history"},
+		// 与静态段同名的参数值只替换最后一次出现
+		{"/api/v1/tags/tags", map[string]string{"id": "tags"}, "/api/v1/tags/:id"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.vars != nil {
+			r = pathvar.WithVars(r, tt.vars)
+		}
+		if got := routeTemplate(r); got != tt.want {
+			t.Errorf("routeTemplate(%s) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	metrics.Init(metrics.MetricsConfig{Enabled: true, Path: "/metrics"})
+	httpx.SetErrorHandlerCtx(metrics.ErrorHandler(nil))
+	defer httpx.SetErrorHandlerCtx(nil)
+
+	handler := Metrics()(func(w http.ResponseWriter, r *http.Request) {
+		httpx.ErrorCtx(r.Context(), w, errorx.NewWithCode(errorx.ErrCodeNotFound))
+	})
+	r := pathvar.WithVars(httptest.NewRequest(http.MethodGet, "/api/v1/tags/7", nil), map[string]string{"id": "7"})
+	rec := httptest.NewRecorder()
+	handler(rec, r)
+
+	// 错误响应保持 httpx 默认格式
+	if rec.Code != http.StatusBadRequest || strings.TrimSpace(rec.Body.String()) != "数据不存在" {
+		t.Errorf("响应 = %d %q", rec.Code, rec.Body.String())
+	}
+
+	scraped := httptest.NewRecorder()
+	metrics.Handler()(scraped, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	want := `idrm_http_requests_total{code="30001",method="GET",route="/api/v1/tags/:id",status="400"}`
+	if !strings.Contains(scraped.Body.String(), want) {
+		t.Errorf("指标输出缺少 %s", want)
+	}
+}
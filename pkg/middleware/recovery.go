@@ -1,31 +1,49 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"runtime/debug"
 
+	"idrm/pkg/errorx"
+	"idrm/pkg/response"
+	"idrm/pkg/telemetry/metrics"
+	"idrm/pkg/telemetry/trace"
+
 	"github.com/zeromicro/go-zero/core/logx"
-	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
-// Recovery recovers from panics and returns 500 error
+// Recovery recovers from panics and returns a 500 errorx response.
+// Panics carrying an errorx error keep their code, anything else is
+// reported as errorx.ErrCodeSystem without leaking panic details.
 func Recovery() func(http.HandlerFunc) http.HandlerFunc {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
-				if err := recover(); err != nil {
+				if v := recover(); v != nil {
+					// Let net/http abort the response as requested
+					if v == http.ErrAbortHandler {
+						panic(v)
+					}
+
 					// Log panic with stack trace
 					logx.WithContext(r.Context()).Errorw("Panic recovered",
-						logx.Field("error", err),
+						logx.Field("error", v),
 						logx.Field("stack", string(debug.Stack())),
 						logx.Field("method", r.Method),
 						logx.Field("path", r.URL.Path),
 						logx.Field("request_id", GetRequestID(r.Context())),
 					)
 
-					// Return 500 error
-					httpx.Error(w, fmt.Errorf("internal server error"))
+					// Report to span and metrics, then return 500 error
+					err := panicError(v)
+					trace.RecordError(r.Context(), fmt.Errorf("panic: %v", v))
+					metrics.RecordError(r.Context(), err)
+					response.WriteJSON(w, http.StatusInternalServerError, &response.HttpResponse{
+						Code: err.GetCode(),
+						Msg:  err.GetMsg(),
+					})
 				}
 			}()
 
@@ -33,3 +51,14 @@ func Recovery() func(http.HandlerFunc) http.HandlerFunc {
 		}
 	}
 }
+
+// panicError converts a recovered value into an errorx error
+func panicError(v interface{}) *errorx.CodeError {
+	if err, ok := v.(error); ok {
+		var codeErr *errorx.CodeError
+		if errors.As(err, &codeErr) {
+			return codeErr
+		}
+	}
+	return errorx.NewWithCode(errorx.ErrCodeSystem).(*errorx.CodeError)
+}
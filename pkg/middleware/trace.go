@@ -3,24 +3,36 @@ package middleware
 import (
 	"net/http"
 
+	"idrm/pkg/operator"
 	"idrm/pkg/telemetry/trace"
 
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
 )
 
-// Trace creates OpenTelemetry spans for HTTP requests
+// Trace creates OpenTelemetry spans for HTTP requests.
+// Spans join the upstream trace carried by the traceparent header
+// and are named after the route template, e.g. "GET /api/v1/tags/:id".
+// http.client_ip starts as the direct peer address; forwarding headers are
+// only trusted once the auth middleware resolves them against TrustedProxies.
 func Trace() func(http.HandlerFunc) http.HandlerFunc {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
+			// Extract upstream trace context
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
 			// Create Server Span
-			ctx, span := trace.StartServer(r.Context(), r.URL.Path,
+			route := routeTemplate(r)
+			ctx, span := trace.StartServer(ctx, r.Method+" "+route,
 				attribute.String("http.method", r.Method),
+				attribute.String("http.route", route),
 				attribute.String("http.url", r.URL.String()),
 				attribute.String("http.host", r.Host),
 				attribute.String("http.scheme", getScheme(r)),
 				attribute.String("http.user_agent", r.UserAgent()),
-				attribute.String("http.client_ip", getClientIP(r)),
+				attribute.String("http.client_ip", operator.RemoteIP(r)),
 				attribute.String("http.request_id", GetRequestID(r.Context())),
 			)
 			defer span.End()
@@ -70,17 +82,3 @@ func getScheme(r *http.Request) string {
 	}
 	return "http"
 }
-
-// getClientIP extracts real client IP
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header
-	if ip := r.Header.Get("X-Forwarded-For"); ip != "" {
-		return ip
-	}
-	// Check X-Real-IP header
-	if ip := r.Header.Get("X-Real-IP"); ip != "" {
-		return ip
-	}
-	// Fall back to RemoteAddr
-	return r.RemoteAddr
-}
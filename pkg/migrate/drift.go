@@ -0,0 +1,327 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"idrm/pkg/db"
+)
+
+// DriftKind 结构漂移类型
+type DriftKind string
+
+const (
+	DriftPendingMigration DriftKind = "pending_migration" // 迁移脚本未执行，数据库与脚本不一致
+	DriftMissingTable     DriftKind = "missing_table"     // 模型对应的表不存在
+	DriftMissingColumn    DriftKind = "missing_column"    // 模型字段在数据库中不存在
+	DriftExtraColumn      DriftKind = "extra_column"      // 数据库列未在模型中声明
+	DriftTypeMismatch     DriftKind = "type_mismatch"     // 列类型不一致
+	DriftMissingIndex     DriftKind = "missing_index"     // 模型声明的索引在数据库中不存在
+	DriftIndexMismatch    DriftKind = "index_mismatch"    // 同名索引的列或唯一性不一致
+	DriftUndeclaredIndex  DriftKind = "undeclared_index"  // 数据库索引未在模型中声明
+)
+
+// Drift 单条结构漂移
+type Drift struct {
+	Kind     DriftKind
+	Table    string
+	Column   string
+	Index    string
+	Expected string // 模型声明
+	Actual   string // 数据库实际
+}
+
+// String 格式化输出
+func (d Drift) String() string {
+	target := d.Table
+	switch {
+	case d.Column != "":
+		target += "." + d.Column
+	case d.Index != "":
+		target += "#" + d.Index
+	}
+	s := fmt.Sprintf("%s %s", d.Kind, target)
+	if d.Expected != "" {
+		s += fmt.Sprintf(" expected=%q", d.Expected)
+	}
+	if d.Actual != "" {
+		s += fmt.Sprintf(" actual=%q", d.Actual)
+	}
+	return s
+}
+
+// CheckDrift 对比模型结构与数据库实际结构
+// 先检查迁移脚本是否全部执行，再逐表比对列和索引
+func (m *Migrator) CheckDrift(ctx context.Context, models ...interface{}) ([]Drift, error) {
+	var drifts []Drift
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range statuses {
+		if !s.Applied || s.Modified {
+			drifts = append(drifts, Drift{
+				Kind:  DriftPendingMigration,
+				Table: TableName,
+				Index: fmt.Sprintf("%d_%s", s.Version, s.Name),
+			})
+		}
+	}
+
+	conn := m.session(ctx)
+	for _, model := range models {
+		tableDrifts, err := checkTable(conn, model)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, tableDrifts...)
+	}
+	return drifts, nil
+}
+
+// checkTable 比对单个模型
+func checkTable(conn *gorm.DB, model interface{}) ([]Drift, error) {
+	stmt := &gorm.Statement{DB: conn}
+	if err := stmt.Parse(model); err != nil {
+		return nil, fmt.Errorf("解析模型失败: %w", err)
+	}
+	table := stmt.Schema.Table
+	migrator := conn.Migrator()
+
+	if !migrator.HasTable(table) {
+		return []Drift{{Kind: DriftMissingTable, Table: table}}, nil
+	}
+
+	columnTypes, err := migrator.ColumnTypes(model)
+	if err != nil {
+		return nil, fmt.Errorf("查询表 %s 列信息失败: %w", table, err)
+	}
+	indexes, err := migrator.GetIndexes(model)
+	if err != nil {
+		return nil, fmt.Errorf("查询表 %s 索引失败: %w", table, err)
+	}
+
+	drifts := checkColumns(conn, table, stmt.Schema, columnTypes)
+	drifts = append(drifts, checkIndexes(table, stmt.Schema, indexes)...)
+	return drifts, nil
+}
+
+// checkColumns 比对列
+func checkColumns(conn *gorm.DB, table string, s *schema.Schema, columnTypes []gorm.ColumnType) []Drift {
+	var drifts []Drift
+	actual := make(map[string]gorm.ColumnType, len(columnTypes))
+	for _, ct := range columnTypes {
+		actual[ct.Name()] = ct
+	}
+
+	for _, field := range s.Fields {
+		if field.DBName == "" || field.IgnoreMigration {
+			continue
+		}
+		ct, ok := actual[field.DBName]
+		if !ok {
+			drifts = append(drifts, Drift{Kind: DriftMissingColumn, Table: table, Column: field.DBName})
+			continue
+		}
+		delete(actual, field.DBName)
+
+		expected := conn.Dialector.DataTypeOf(field)
+		actualType, ok := ct.ColumnType()
+		if !ok || actualType == "" {
+			actualType = ct.DatabaseTypeName()
+		}
+		if !sameType(db.Dialect(conn), parseColumnType(expected, field), parseColumnType(actualType, nil)) {
+			drifts = append(drifts, Drift{
+				Kind:     DriftTypeMismatch,
+				Table:    table,
+				Column:   field.DBName,
+				Expected: expected,
+				Actual:   actualType,
+			})
+		}
+	}
+
+	for name := range actual {
+		drifts = append(drifts, Drift{Kind: DriftExtraColumn, Table: table, Column: name})
+	}
+	sortDrifts(drifts)
+	return drifts
+}
+
+// checkIndexes 比对索引（主键除外）
+func checkIndexes(table string, s *schema.Schema, indexes []gorm.Index) []Drift {
+	var drifts []Drift
+	actual := make(map[string]gorm.Index, len(indexes))
+	for _, idx := range indexes {
+		if pk, ok := idx.PrimaryKey(); ok && pk {
+			continue
+		}
+		actual[idx.Name()] = idx
+	}
+
+	for _, idx := range s.ParseIndexes() {
+		columns := make([]string, 0, len(idx.Fields))
+		for _, f := range idx.Fields {
+			columns = append(columns, f.DBName)
+		}
+		expected := describeIndex(columns, idx.Class == "UNIQUE")
+
+		got, ok := actual[idx.Name]
+		if !ok {
+			drifts = append(drifts, Drift{Kind: DriftMissingIndex, Table: table, Index: idx.Name, Expected: expected})
+			continue
+		}
+		delete(actual, idx.Name)
+
+		unique, _ := got.Unique()
+		if desc := describeIndex(got.Columns(), unique); desc != expected {
+			drifts = append(drifts, Drift{
+				Kind:     DriftIndexMismatch,
+				Table:    table,
+				Index:    idx.Name,
+				Expected: expected,
+				Actual:   desc,
+			})
+		}
+	}
+
+	for name, idx := range actual {
+		unique, _ := idx.Unique()
+		drifts = append(drifts, Drift{
+			Kind:   DriftUndeclaredIndex,
+			Table:  table,
+			Index:  name,
+			Actual: describeIndex(idx.Columns(), unique),
+		})
+	}
+	sortDrifts(drifts)
+	return drifts
+}
+
+func describeIndex(columns []string, unique bool) string {
+	desc := "(" + strings.Join(columns, ",") + ")"
+	if unique {
+		return "UNIQUE " + desc
+	}
+	return desc
+}
+
+func sortDrifts(drifts []Drift) {
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Kind != drifts[j].Kind {
+			return drifts[i].Kind < drifts[j].Kind
+		}
+		return drifts[i].Column+drifts[i].Index < drifts[j].Column+drifts[j].Index
+	})
+}
+
+// columnType 归一化后的列类型
+type columnType struct {
+	family   string // int | string | time | float | bool | bytes | 其他原始类型名
+	width    int    // 整数位宽
+	length   int    // 字符串长度，0 表示未知
+	unsigned bool
+}
+
+var typeLengthPattern = regexp.MustCompile(`\(\s*(\d+)`)
+
+// 类型名之后的修饰词
+var typeModifiers = map[string]bool{
+	"unsigned": true, "signed": true, "zerofill": true, "auto_increment": true,
+	"not": true, "null": true, "default": true, "primary": true,
+}
+
+var intWidths = map[string]int{
+	"tinyint": 8, "smallint": 16, "int2": 16, "smallserial": 16, "mediumint": 24,
+	"int": 32, "integer": 32, "int4": 32, "serial": 32,
+	"bigint": 64, "int8": 64, "bigserial": 64,
+}
+
+// parseColumnType 解析类型字符串，field 非空时以 Go 类型判断是否无符号
+func parseColumnType(raw string, field *schema.Field) columnType {
+	lower := strings.ToLower(raw)
+	ct := columnType{unsigned: strings.Contains(lower, "unsigned")}
+	if field != nil && field.DataType == schema.Uint {
+		ct.unsigned = true
+	}
+
+	name := lower
+	if i := strings.Index(name, "("); i >= 0 {
+		name = name[:i]
+		if matches := typeLengthPattern.FindStringSubmatch(lower); matches != nil {
+			ct.length, _ = strconv.Atoi(matches[1])
+		}
+	}
+	var words []string
+	for _, word := range strings.Fields(name) {
+		if typeModifiers[word] {
+			break
+		}
+		words = append(words, word)
+	}
+	base := strings.Join(words, " ")
+
+	switch {
+	case intWidths[base] > 0:
+		ct.family, ct.width, ct.length = "int", intWidths[base], 0
+	case base == "boolean" || base == "bool":
+		ct.family = "bool"
+	case strings.Contains(base, "char") || strings.HasSuffix(base, "text"):
+		ct.family = "string"
+		if strings.HasSuffix(base, "text") {
+			ct.length = 0
+		}
+	case strings.HasPrefix(base, "datetime") || strings.HasPrefix(base, "timestamp") || base == "date" || base == "time":
+		ct.family, ct.length = "time", 0
+	case base == "float" || base == "double" || base == "real" || base == "numeric" || base == "decimal" ||
+		strings.HasPrefix(base, "double precision"):
+		ct.family = "float"
+	case strings.HasSuffix(base, "blob") || base == "bytea" || strings.Contains(base, "binary"):
+		ct.family = "bytes"
+	default:
+		ct.family = base
+	}
+	return ct
+}
+
+// normalizeType 按方言归一化类型
+// MySQL 的 boolean 是 tinyint(1) 的别名；整数的显示宽度（如 int(11)）已在解析时忽略；
+// 只有 MySQL 支持无符号整数，其他方言忽略由 Go 无符号类型推断出的 unsigned
+func normalizeType(dialect string, ct columnType) columnType {
+	switch dialect {
+	case db.DriverMySQL:
+		if ct.family == "bool" {
+			ct.family, ct.width = "int", intWidths["tinyint"]
+		}
+	default:
+		ct.unsigned = false
+	}
+	return ct
+}
+
+// sameType 判断两个归一化类型是否兼容
+func sameType(dialect string, expected, actual columnType) bool {
+	expected, actual = normalizeType(dialect, expected), normalizeType(dialect, actual)
+	if expected.family != actual.family {
+		return false
+	}
+	switch expected.family {
+	case "int":
+		// SQLite 整数统一按 64 位存储，且不区分有无符号
+		if dialect == db.DriverSQLite {
+			return true
+		}
+		return expected.width == actual.width && expected.unsigned == actual.unsigned
+	case "string":
+		return expected.length == 0 || actual.length == 0 || expected.length == actual.length
+	}
+	return true
+}
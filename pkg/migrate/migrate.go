@@ -0,0 +1,453 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"idrm/pkg/db"
+)
+
+// schemaMigration 迁移记录
+type schemaMigration struct {
+	Version     int64     `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name        string    `gorm:"column:name;type:varchar(255);not null"`
+	Checksum    string    `gorm:"column:checksum;type:varchar(64);not null"`
+	AppliedAt   time.Time `gorm:"column:applied_at;not null"`
+	ExecutionMs int64     `gorm:"column:execution_ms;not null"`
+}
+
+// TableName 指定表名
+func (schemaMigration) TableName() string {
+	return TableName
+}
+
+// schemaMigrationLock 无原生咨询锁的数据库(SQLite)使用表锁
+type schemaMigrationLock struct {
+	Id       int64     `gorm:"column:id;primaryKey;autoIncrement:false"`
+	LockedAt time.Time `gorm:"column:locked_at;not null"`
+}
+
+// TableName 指定表名
+func (schemaMigrationLock) TableName() string {
+	return TableName + "_lock"
+}
+
+// Status 单个迁移的执行状态
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	Modified  bool // 已执行后脚本被修改
+	Missing   bool // 已执行但脚本文件不存在
+}
+
+// Migrator 版本化迁移执行器
+type Migrator struct {
+	db          *gorm.DB
+	source      fs.FS
+	lockTimeout time.Duration
+}
+
+// New 创建迁移执行器，source 为迁移脚本目录
+func New(gormDB *gorm.DB, source fs.FS) *Migrator {
+	return &Migrator{
+		db:          gormDB,
+		source:      source,
+		lockTimeout: DefaultLockTimeout,
+	}
+}
+
+// WithLockTimeout 设置获取迁移锁的超时时间
+func (m *Migrator) WithLockTimeout(timeout time.Duration) *Migrator {
+	m.lockTimeout = timeout
+	return m
+}
+
+// Migrations 加载当前数据库方言对应的迁移脚本
+func (m *Migrator) Migrations() ([]*Migration, error) {
+	return Load(m.source, db.Dialect(m.db))
+}
+
+// Up 按版本顺序执行未执行的迁移，steps<=0 时执行全部
+func (m *Migrator) Up(ctx context.Context, steps int) ([]*Migration, error) {
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []*Migration
+	err = m.withLock(ctx, func(conn *gorm.DB) error {
+		records, err := m.appliedRecords(conn)
+		if err != nil {
+			return err
+		}
+		if err := verify(migrations, records); err != nil {
+			return err
+		}
+
+		for _, mg := range migrations {
+			if _, ok := records[mg.Version]; ok {
+				continue
+			}
+			if steps > 0 && len(applied) >= steps {
+				break
+			}
+			if err := m.apply(conn, mg); err != nil {
+				return err
+			}
+			applied = append(applied, mg)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down 按版本倒序回滚已执行的迁移，steps<=0 时回滚一个版本
+func (m *Migrator) Down(ctx context.Context, steps int) ([]*Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*Migration, len(migrations))
+	for _, mg := range migrations {
+		byVersion[mg.Version] = mg
+	}
+
+	var reverted []*Migration
+	err = m.withLock(ctx, func(conn *gorm.DB) error {
+		records, err := m.appliedRecords(conn)
+		if err != nil {
+			return err
+		}
+		if err := verify(migrations, records); err != nil {
+			return err
+		}
+
+		versions := make([]int64, 0, len(records))
+		for version := range records {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		for _, version := range versions {
+			if len(reverted) >= steps {
+				break
+			}
+			mg := byVersion[version]
+			if err := m.revert(conn, mg); err != nil {
+				return err
+			}
+			reverted = append(reverted, mg)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Baseline 将版本号不大于 version 的迁移标记为已执行但不执行脚本
+// 用于接入迁移前已按旧 DDL 建表的数据库，之后的迁移由 Up 正常执行
+func (m *Migrator) Baseline(ctx context.Context, version int64) ([]*Migration, error) {
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, mg := range migrations {
+		found = found || mg.Version == version
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: %d", ErrBaselineVersion, version)
+	}
+
+	var marked []*Migration
+	err = m.withLock(ctx, func(conn *gorm.DB) error {
+		records, err := m.appliedRecords(conn)
+		if err != nil {
+			return err
+		}
+		if err := verify(migrations, records); err != nil {
+			return err
+		}
+
+		for _, mg := range migrations {
+			if mg.Version > version {
+				break
+			}
+			if _, ok := records[mg.Version]; ok {
+				continue
+			}
+			err := conn.Create(&schemaMigration{
+				Version:   mg.Version,
+				Name:      mg.Name,
+				Checksum:  mg.Checksum,
+				AppliedAt: time.Now(),
+			}).Error
+			if err != nil {
+				return fmt.Errorf("标记迁移 %d_%s 失败: %w", mg.Version, mg.Name, err)
+			}
+			marked = append(marked, mg)
+		}
+		return nil
+	})
+	return marked, err
+}
+
+// Status 查询所有迁移的执行状态，按版本升序排列
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+	conn := m.session(ctx)
+	if err := m.ensureTables(conn); err != nil {
+		return nil, err
+	}
+	records, err := m.appliedRecords(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	known := make(map[int64]bool, len(migrations))
+	for _, mg := range migrations {
+		known[mg.Version] = true
+		s := Status{Version: mg.Version, Name: mg.Name}
+		if r, ok := records[mg.Version]; ok {
+			appliedAt := r.AppliedAt
+			s.Applied = true
+			s.AppliedAt = &appliedAt
+			s.Modified = r.Checksum != mg.Checksum
+		}
+		statuses = append(statuses, s)
+	}
+	for version, r := range records {
+		if known[version] {
+			continue
+		}
+		appliedAt := r.AppliedAt
+		statuses = append(statuses, Status{
+			Version:   version,
+			Name:      r.Name,
+			Applied:   true,
+			AppliedAt: &appliedAt,
+			Missing:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// session 迁移相关的读写一律走主库
+func (m *Migrator) session(ctx context.Context) *gorm.DB {
+	return m.db.WithContext(db.WithPrimary(ctx))
+}
+
+// ensureTables 创建迁移记录表
+func (m *Migrator) ensureTables(conn *gorm.DB) error {
+	if err := conn.AutoMigrate(&schemaMigration{}); err != nil {
+		return fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+	return nil
+}
+
+// appliedRecords 查询已执行的迁移记录
+func (m *Migrator) appliedRecords(conn *gorm.DB) (map[int64]schemaMigration, error) {
+	var list []schemaMigration
+	if err := conn.Order("version").Find(&list).Error; err != nil {
+		return nil, fmt.Errorf("查询迁移记录失败: %w", err)
+	}
+	records := make(map[int64]schemaMigration, len(list))
+	for _, r := range list {
+		records[r.Version] = r
+	}
+	return records, nil
+}
+
+// verify 校验已执行迁移的脚本未被修改且仍然存在
+func verify(migrations []*Migration, records map[int64]schemaMigration) error {
+	known := make(map[int64]*Migration, len(migrations))
+	for _, mg := range migrations {
+		known[mg.Version] = mg
+	}
+	for version, r := range records {
+		mg, ok := known[version]
+		if !ok {
+			return fmt.Errorf("%w: %d_%s", ErrUnknownVersion, version, r.Name)
+		}
+		if mg.Checksum != r.Checksum {
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, version, mg.Name)
+		}
+	}
+	return nil
+}
+
+// apply 在事务中执行 up 脚本并写入迁移记录
+// 注意: MySQL 的 DDL 会隐式提交，失败时需人工处理已执行的语句
+func (m *Migrator) apply(conn *gorm.DB, mg *Migration) error {
+	start := time.Now()
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if err := execScript(tx, mg.Up); err != nil {
+			return err
+		}
+		return tx.Create(&schemaMigration{
+			Version:     mg.Version,
+			Name:        mg.Name,
+			Checksum:    mg.Checksum,
+			AppliedAt:   time.Now(),
+			ExecutionMs: time.Since(start).Milliseconds(),
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("执行迁移 %d_%s 失败: %w", mg.Version, mg.Name, err)
+	}
+	logx.WithContext(conn.Statement.Context).Infof("迁移 %d_%s 执行完成, 耗时 %v", mg.Version, mg.Name, time.Since(start))
+	return nil
+}
+
+// revert 在事务中执行 down 脚本并删除迁移记录
+func (m *Migrator) revert(conn *gorm.DB, mg *Migration) error {
+	if mg.Down == "" {
+		return fmt.Errorf("%w: %d_%s", ErrMissingDown, mg.Version, mg.Name)
+	}
+	start := time.Now()
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if err := execScript(tx, mg.Down); err != nil {
+			return err
+		}
+		return tx.Delete(&schemaMigration{}, mg.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("回滚迁移 %d_%s 失败: %w", mg.Version, mg.Name, err)
+	}
+	logx.WithContext(conn.Statement.Context).Infof("迁移 %d_%s 回滚完成, 耗时 %v", mg.Version, mg.Name, time.Since(start))
+	return nil
+}
+
+// execScript 逐条执行脚本中的语句
+func execScript(tx *gorm.DB, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withLock 持有迁移锁执行 fn，防止多个实例并发迁移
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	conn := m.session(ctx)
+	if err := m.ensureTables(conn); err != nil {
+		return err
+	}
+
+	switch db.Dialect(m.db) {
+	case db.DriverMySQL, db.DriverPostgres:
+		// 会话级咨询锁必须在同一连接上获取和释放
+		return conn.Connection(func(session *gorm.DB) error {
+			if err := m.advisoryLock(ctx, session); err != nil {
+				return err
+			}
+			defer m.advisoryUnlock(session)
+			return fn(conn)
+		})
+	default:
+		if err := m.tableLock(ctx, conn); err != nil {
+			return err
+		}
+		defer m.tableUnlock(conn)
+		return fn(conn)
+	}
+}
+
+// advisoryLock 获取 MySQL GET_LOCK / PostgreSQL pg_advisory_lock
+func (m *Migrator) advisoryLock(ctx context.Context, session *gorm.DB) error {
+	pool := session.Statement.ConnPool
+	if db.Dialect(m.db) == db.DriverMySQL {
+		var acquired int
+		// GET_LOCK 的超时单位为秒，向上取整避免不足1秒的超时变为不等待
+		timeout := int(math.Ceil(m.lockTimeout.Seconds()))
+		if err := pool.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, timeout).Scan(&acquired); err != nil {
+			return fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+		if acquired != 1 {
+			return ErrLocked
+		}
+		return nil
+	}
+
+	return retryUntil(ctx, m.lockTimeout, func() (bool, error) {
+		var acquired bool
+		err := pool.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", lockName).Scan(&acquired)
+		return acquired, err
+	})
+}
+
+// advisoryUnlock 释放咨询锁
+func (m *Migrator) advisoryUnlock(session *gorm.DB) {
+	pool := session.Statement.ConnPool
+	query := "SELECT pg_advisory_unlock(hashtext($1))"
+	if db.Dialect(m.db) == db.DriverMySQL {
+		query = "SELECT RELEASE_LOCK(?)"
+	}
+	rows, err := pool.QueryContext(context.Background(), query, lockName)
+	if err != nil {
+		logx.Errorf("释放迁移锁失败: %v", err)
+		return
+	}
+	_ = rows.Close()
+}
+
+// tableLock 通过插入唯一锁记录获取迁移锁
+func (m *Migrator) tableLock(ctx context.Context, conn *gorm.DB) error {
+	if err := conn.AutoMigrate(&schemaMigrationLock{}); err != nil {
+		return fmt.Errorf("创建迁移锁表失败: %w", err)
+	}
+	return retryUntil(ctx, m.lockTimeout, func() (bool, error) {
+		result := conn.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&schemaMigrationLock{Id: 1, LockedAt: time.Now()})
+		return result.RowsAffected == 1, result.Error
+	})
+}
+
+// tableUnlock 删除锁记录
+func (m *Migrator) tableUnlock(conn *gorm.DB) {
+	if err := conn.WithContext(context.Background()).Delete(&schemaMigrationLock{}, 1).Error; err != nil {
+		logx.Errorf("释放迁移锁失败: %v", err)
+	}
+}
+
+// retryUntil 轮询获取锁直至成功或超时
+func retryUntil(ctx context.Context, timeout time.Duration, try func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		acquired, err := try()
+		if err != nil {
+			return fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+		if acquired {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrLocked
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(ErrLocked, ctx.Err())
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"gorm.io/gorm"
+
+	"idrm/pkg/db"
+)
+
+// setupTestDB 创建测试数据库
+func setupTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := db.InitGorm(db.Config{
+		Driver:   db.DriverSQLite,
+		Database: filepath.Join(t.TempDir(), "migrate.db"),
+	})
+	if err != nil {
+		t.Fatalf("初始化数据库失败: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close(gormDB) })
+	return gormDB
+}
+
+func testSource() fstest.MapFS {
+	return fstest.MapFS{
+		"a/1_create_a.up.sql":        {Data: []byte("CREATE TABLE a (id INTEGER PRIMARY KEY); -- 注释; 不拆分\n")},
+		"a/1_create_a.down.sql":      {Data: []byte("DROP TABLE a;")},
+		"b/2_create_b.up.sql":        {Data: []byte("CREATE TABLE b (id INTEGER PRIMARY KEY, note TEXT DEFAULT 'x;y');\nINSERT INTO b (id) VALUES (1);")},
+		"b/2_create_b.up.mysql.sql":  {Data: []byte("CREATE TABLE b (id BIGINT PRIMARY KEY) ENGINE=InnoDB;")},
+		"b/2_create_b.down.sql":      {Data: []byte("DROP TABLE b;")},
+		"b/3_create_c.up.sqlite.sql": {Data: []byte("CREATE TABLE c (id INTEGER PRIMARY KEY);")},
+		"README.md":                  {Data: []byte("忽略非迁移文件")},
+	}
+}
+
+// TestLoad 测试加载与方言选择
+func TestLoad(t *testing.T) {
+	migrations, err := Load(testSource(), db.DriverMySQL)
+	if err != nil {
+		t.Fatalf("加载失败: %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("期望2个迁移(仅sqlite的版本应被忽略), 实际=%d", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[1].Version != 2 {
+		t.Errorf("迁移应按版本排序, 实际=%d,%d", migrations[0].Version, migrations[1].Version)
+	}
+	if migrations[1].Up != "CREATE TABLE b (id BIGINT PRIMARY KEY) ENGINE=InnoDB;" {
+		t.Errorf("方言专用脚本应优先, 实际=%q", migrations[1].Up)
+	}
+
+	src := testSource()
+	src["c/1_other.up.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+	if _, err := Load(src, db.DriverSQLite); !errors.Is(err, ErrDuplicateVersion) {
+		t.Errorf("期望 ErrDuplicateVersion, 实际=%v", err)
+	}
+
+	src = testSource()
+	src["c/4_only_down.down.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+	if _, err := Load(src, db.DriverSQLite); !errors.Is(err, ErrMissingUp) {
+		t.Errorf("期望 ErrMissingUp, 实际=%v", err)
+	}
+}
+
+// TestSplitStatements 测试语句拆分
+func TestSplitStatements(t *testing.T) {
+	script := "-- 头部注释;\nCREATE TABLE t (v VARCHAR(10) DEFAULT 'a;b');\n/* 块注释; */\nINSERT INTO t VALUES ('it\\'s;');\n\n"
+	stmts := splitStatements(script)
+	if len(stmts) != 2 {
+		t.Fatalf("期望2条语句, 实际=%d: %q", len(stmts), stmts)
+	}
+	if stmts[0] != "CREATE TABLE t (v VARCHAR(10) DEFAULT 'a;b')" {
+		t.Errorf("第一条语句不正确: %q", stmts[0])
+	}
+	if stmts[1] != "INSERT INTO t VALUES ('it\\'s;')" {
+		t.Errorf("第二条语句不正确: %q", stmts[1])
+	}
+}
+
+// TestMigrator_UpDown 测试执行与回滚
+func TestMigrator_UpDown(t *testing.T) {
+	gormDB := setupTestDB(t)
+	m := New(gormDB, testSource())
+	ctx := context.Background()
+
+	applied, err := m.Up(ctx, 1)
+	if err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	if len(applied) != 1 || applied[0].Version != 1 {
+		t.Fatalf("期望仅执行版本1, 实际=%v", applied)
+	}
+
+	applied, err = m.Up(ctx, 0)
+	if err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	if len(applied) != 2 {
+		t.Fatalf("期望执行剩余2个迁移, 实际=%d", len(applied))
+	}
+	for _, table := range []string{"a", "b", "c"} {
+		if !gormDB.Migrator().HasTable(table) {
+			t.Errorf("表 %s 应已创建", table)
+		}
+	}
+
+	// 重复执行无待执行迁移
+	applied, err = m.Up(ctx, 0)
+	if err != nil || len(applied) != 0 {
+		t.Errorf("期望无待执行迁移, applied=%d, err=%v", len(applied), err)
+	}
+
+	// 版本3没有down脚本，回滚失败且不影响记录
+	if _, err := m.Down(ctx, 1); !errors.Is(err, ErrMissingDown) {
+		t.Fatalf("期望 ErrMissingDown, 实际=%v", err)
+	}
+
+	src := testSource()
+	src["b/3_create_c.down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE c;")}
+	m = New(gormDB, src)
+	reverted, err := m.Down(ctx, 2)
+	if err != nil {
+		t.Fatalf("回滚失败: %v", err)
+	}
+	if len(reverted) != 2 || reverted[0].Version != 3 || reverted[1].Version != 2 {
+		t.Fatalf("期望倒序回滚3和2, 实际=%v", reverted)
+	}
+	if gormDB.Migrator().HasTable("b") || !gormDB.Migrator().HasTable("a") {
+		t.Error("回滚后表b应删除，表a应保留")
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("查询状态失败: %v", err)
+	}
+	if len(statuses) != 3 || !statuses[0].Applied || statuses[1].Applied || statuses[2].Applied {
+		t.Errorf("状态不正确: %+v", statuses)
+	}
+}
+
+// TestMigrator_ChecksumMismatch 测试已执行脚本被修改
+func TestMigrator_ChecksumMismatch(t *testing.T) {
+	gormDB := setupTestDB(t)
+	ctx := context.Background()
+	if _, err := New(gormDB, testSource()).Up(ctx, 0); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+
+	src := testSource()
+	src["a/1_create_a.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE a (id INTEGER PRIMARY KEY, name TEXT);")}
+	src["d/4_create_d.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE d (id INTEGER PRIMARY KEY);")}
+	m := New(gormDB, src)
+	if _, err := m.Up(ctx, 0); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("期望 ErrChecksumMismatch, 实际=%v", err)
+	}
+	if gormDB.Migrator().HasTable("d") {
+		t.Error("校验失败时不应执行新迁移")
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("查询状态失败: %v", err)
+	}
+	if !statuses[0].Modified {
+		t.Error("版本1应标记为已修改")
+	}
+
+	// 数据库中存在脚本目录中没有的版本
+	src = testSource()
+	delete(src, "b/3_create_c.up.sqlite.sql")
+	if _, err := New(gormDB, src).Up(ctx, 0); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("期望 ErrUnknownVersion, 实际=%v", err)
+	}
+}
+
+// TestMigrator_Baseline 测试已有数据库接入迁移
+func TestMigrator_Baseline(t *testing.T) {
+	gormDB := setupTestDB(t)
+	ctx := context.Background()
+	m := New(gormDB, testSource())
+
+	// 按旧 DDL 建好的表
+	gormDB.Exec("CREATE TABLE a (id INTEGER PRIMARY KEY)")
+
+	if _, err := m.Baseline(ctx, 5); !errors.Is(err, ErrBaselineVersion) {
+		t.Errorf("期望 ErrBaselineVersion, 实际=%v", err)
+	}
+	marked, err := m.Baseline(ctx, 1)
+	if err != nil {
+		t.Fatalf("标记基线失败: %v", err)
+	}
+	if len(marked) != 1 || marked[0].Version != 1 {
+		t.Fatalf("期望标记版本1, 实际=%v", marked)
+	}
+
+	// 基线之后的迁移正常执行
+	applied, err := m.Up(ctx, 0)
+	if err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	if len(applied) != 2 || applied[0].Version != 2 {
+		t.Errorf("期望执行版本2和3, 实际=%v", applied)
+	}
+}
+
+// TestMigrator_Lock 测试迁移锁
+func TestMigrator_Lock(t *testing.T) {
+	gormDB := setupTestDB(t)
+	ctx := context.Background()
+	m := New(gormDB, testSource()).WithLockTimeout(300 * time.Millisecond)
+
+	if err := m.withLock(ctx, func(*gorm.DB) error {
+		// 持锁期间其他执行器无法获取锁
+		_, err := New(gormDB, testSource()).WithLockTimeout(100*time.Millisecond).Up(ctx, 0)
+		if !errors.Is(err, ErrLocked) {
+			t.Errorf("期望 ErrLocked, 实际=%v", err)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("获取锁失败: %v", err)
+	}
+
+	// 锁释放后可正常执行
+	if _, err := m.Up(ctx, 0); err != nil {
+		t.Errorf("释放锁后执行失败: %v", err)
+	}
+}
+
+// TestMigrator_RepositoryMigrations 测试仓库中的迁移脚本可在SQLite上执行和回滚
+func TestMigrator_RepositoryMigrations(t *testing.T) {
+	dir := filepath.Join("..", "..", "migrations")
+	if _, err := os.Stat(dir); err != nil {
+		t.Skipf("迁移目录不存在: %v", err)
+	}
+	gormDB := setupTestDB(t)
+	m := New(gormDB, os.DirFS(dir))
+	ctx := context.Background()
+
+	applied, err := m.Up(ctx, 0)
+	if err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	if len(applied) == 0 {
+		t.Fatal("期望至少执行一个迁移")
+	}
+	for _, table := range []string{"tags", "resource_tags"} {
+		if !gormDB.Migrator().HasTable(table) {
+			t.Errorf("表 %s 应已创建", table)
+		}
+	}
+
+	if _, err := m.Down(ctx, len(applied)); err != nil {
+		t.Fatalf("回滚失败: %v", err)
+	}
+	if gormDB.Migrator().HasTable("tags") {
+		t.Error("回滚后表 tags 应删除")
+	}
+}
+
+// TestResolveDir 测试迁移目录在工作目录的上级目录中查找
+func TestResolveDir(t *testing.T) {
+	want, err := filepath.Abs(filepath.Join("..", "..", "migrations"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Skipf("迁移目录不存在: %v", err)
+	}
+	if got := ResolveDir("migrations"); got != want {
+		t.Errorf("ResolveDir(migrations) = %s, want %s", got, want)
+	}
+	if got := ResolveDir("no_such_migrations"); got != "no_such_migrations" {
+		t.Errorf("不存在的目录应原样返回, 实际=%s", got)
+	}
+	if got := ResolveDir(want); got != want {
+		t.Errorf("绝对路径应原样返回, 实际=%s", got)
+	}
+}
+
+type driftTag struct {
+	Id     int64  `gorm:"column:id;primaryKey"`
+	Name   string `gorm:"column:name;type:varchar(50);not null;uniqueIndex:uk_name"`
+	Status int    `gorm:"column:status;type:tinyint;index:idx_status"`
+	Owner  string `gorm:"column:owner;type:varchar(20)"`
+}
+
+func (driftTag) TableName() string { return "drift_tags" }
+
+type driftMissing struct {
+	Id int64 `gorm:"column:id;primaryKey"`
+}
+
+func (driftMissing) TableName() string { return "drift_missing" }
+
+// TestMigrator_CheckDrift 测试模型与数据库结构漂移检测
+func TestMigrator_CheckDrift(t *testing.T) {
+	gormDB := setupTestDB(t)
+	ctx := context.Background()
+	src := fstest.MapFS{
+		"1_create_drift_tags.up.sql": {Data: []byte(`
+CREATE TABLE drift_tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    status TINYINT NOT NULL DEFAULT 1,
+    color VARCHAR(7)
+);
+CREATE INDEX uk_name ON drift_tags (name);
+CREATE INDEX idx_color ON drift_tags (color);`)},
+		"2_pending.up.sql": {Data: []byte("SELECT 1;")},
+	}
+	m := New(gormDB, src)
+	if _, err := m.Up(ctx, 1); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+
+	drifts, err := m.CheckDrift(ctx, &driftTag{}, &driftMissing{})
+	if err != nil {
+		t.Fatalf("检测失败: %v", err)
+	}
+
+	got := make(map[string]Drift)
+	for _, d := range drifts {
+		got[string(d.Kind)+":"+d.Column+d.Index] = d
+	}
+	expected := []string{
+		"pending_migration:2_pending",
+		"missing_column:owner",
+		"extra_column:color",
+		"type_mismatch:name",
+		"index_mismatch:uk_name",
+		"missing_index:idx_status",
+		"undeclared_index:idx_color",
+		"missing_table:",
+	}
+	for _, key := range expected {
+		if _, ok := got[key]; !ok {
+			t.Errorf("缺少漂移项 %s, 实际=%v", key, drifts)
+		}
+	}
+	if len(drifts) != len(expected) {
+		t.Errorf("期望%d个漂移项, 实际=%d: %v", len(expected), len(drifts), drifts)
+	}
+	if d := got["type_mismatch:name"]; d.Expected != "varchar(50)" {
+		t.Errorf("期望类型 varchar(50), 实际=%q", d.Expected)
+	}
+}
+
+// TestParseColumnType 测试类型归一化
+func TestParseColumnType(t *testing.T) {
+	tests := []struct {
+		expected, actual string
+		dialect          string
+		same             bool
+	}{
+		{"bigint", "BIGINT UNSIGNED", db.DriverMySQL, false},
+		{"bigint unsigned", "bigint unsigned", db.DriverMySQL, true},
+		{"bigint AUTO_INCREMENT", "bigint", db.DriverMySQL, true},
+		{"tinyint", "int", db.DriverMySQL, false},
+		{"tinyint", "INTEGER", db.DriverSQLite, true},
+		{"varchar(50)", "varchar(50)", db.DriverMySQL, true},
+		{"varchar(50)", "character varying(100)", db.DriverPostgres, false},
+		{"datetime(3)", "timestamp", db.DriverPostgres, true},
+		{"longtext", "varchar(20)", db.DriverMySQL, true},
+		{"bigint", "varchar(20)", db.DriverMySQL, false},
+		// MySQL 显示宽度、无符号和布尔别名
+		{"int unsigned", "int(10) unsigned", db.DriverMySQL, true},
+		{"bigint", "bigint(20)", db.DriverMySQL, true},
+		{"int", "int(11) unsigned", db.DriverMySQL, false},
+		{"smallint unsigned", "SMALLINT(5) UNSIGNED ZEROFILL", db.DriverMySQL, true},
+		{"boolean", "tinyint(1)", db.DriverMySQL, true},
+		{"boolean", "int(11)", db.DriverMySQL, false},
+		{"bigint unsigned", "bigint", db.DriverPostgres, true},
+	}
+	for _, tt := range tests {
+		if same := sameType(tt.dialect, parseColumnType(tt.expected, nil), parseColumnType(tt.actual, nil)); same != tt.same {
+			t.Errorf("%s vs %s (%s): 期望=%v, 实际=%v", tt.expected, tt.actual, tt.dialect, tt.same, same)
+		}
+	}
+}
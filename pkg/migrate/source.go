@@ -0,0 +1,207 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migration 单个版本的迁移
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // up 脚本的 sha256
+}
+
+// 文件命名: {version}_{name}.{up|down}.sql 或方言专用的 {version}_{name}.{up|down}.{driver}.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-zA-Z0-9_]+)\.(up|down)(?:\.(mysql|postgres|sqlite))?\.sql$`)
+
+// ResolveDir 解析迁移脚本目录
+// 相对路径依次在工作目录及其上级目录中查找，服务在 api/ 下启动时也能找到仓库根目录的 migrations；
+// 均不存在时原样返回，由加载时报错
+func ResolveDir(dir string) string {
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return dir
+	}
+	for {
+		candidate := filepath.Join(wd, dir)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate
+		}
+		parent := filepath.Dir(wd)
+		if parent == wd {
+			return dir
+		}
+		wd = parent
+	}
+}
+
+// Load 从文件系统加载迁移脚本（递归子目录），方言专用脚本优先于通用脚本
+func Load(source fs.FS, driver string) ([]*Migration, error) {
+	type script struct {
+		generic  string
+		specific string
+		found    bool
+	}
+	type entry struct {
+		name string
+		up   script
+		down script
+	}
+	entries := make(map[int64]*entry)
+
+	err := fs.WalkDir(source, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		matches := fileNamePattern.FindStringSubmatch(path.Base(p))
+		if matches == nil {
+			return nil
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("迁移文件 %s 版本号无效: %w", p, err)
+		}
+		name, direction, fileDriver := matches[2], matches[3], matches[4]
+		if fileDriver != "" && fileDriver != driver {
+			return nil
+		}
+
+		e, ok := entries[version]
+		if !ok {
+			e = &entry{name: name}
+			entries[version] = e
+		} else if e.name != name {
+			return fmt.Errorf("%w: %d (%s, %s)", ErrDuplicateVersion, version, e.name, name)
+		}
+
+		content, err := fs.ReadFile(source, p)
+		if err != nil {
+			return fmt.Errorf("读取迁移文件 %s 失败: %w", p, err)
+		}
+		s := &e.up
+		if direction == "down" {
+			s = &e.down
+		}
+		s.found = true
+		if fileDriver != "" {
+			s.specific = string(content)
+		} else {
+			s.generic = string(content)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]*Migration, 0, len(entries))
+	for version, e := range entries {
+		if !e.up.found {
+			return nil, fmt.Errorf("%w: %d_%s", ErrMissingUp, version, e.name)
+		}
+		m := &Migration{
+			Version: version,
+			Name:    e.name,
+			Up:      pick(e.up.specific, e.up.generic),
+			Down:    pick(e.down.specific, e.down.generic),
+		}
+		m.Checksum = checksum(m.Up)
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// pick 优先使用方言专用脚本
+func pick(specific, generic string) string {
+	if specific != "" {
+		return specific
+	}
+	return generic
+}
+
+// checksum 计算脚本校验和，忽略换行符差异
+func checksum(content string) string {
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// splitStatements 按分号拆分 SQL 语句，忽略注释和引号内的分号
+func splitStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		quote      rune
+	)
+	runes := []rune(script)
+
+	flush := func() {
+		stmt := strings.TrimSpace(current.String())
+		if stmt != "" {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+
+		if quote != 0 {
+			current.WriteRune(c)
+			if c == '\\' && quote != '`' && i+1 < len(runes) {
+				i++
+				current.WriteRune(runes[i])
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			current.WriteRune(c)
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			// 行注释
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			current.WriteRune('\n')
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			// 块注释
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+			i++
+		case c == ';':
+			flush()
+		default:
+			current.WriteRune(c)
+		}
+	}
+	flush()
+	return statements
+}
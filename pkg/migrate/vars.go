@@ -0,0 +1,29 @@
+package migrate
+
+import (
+	"errors"
+	"time"
+)
+
+// 常量定义
+const (
+	// 迁移记录表
+	TableName = "schema_migrations"
+
+	// 迁移锁名称
+	lockName = "idrm_schema_migrations"
+
+	// 默认获取锁超时时间
+	DefaultLockTimeout = 30 * time.Second
+)
+
+// 错误定义
+var (
+	ErrDuplicateVersion = errors.New("迁移版本号重复")
+	ErrMissingUp        = errors.New("缺少 up 迁移脚本")
+	ErrMissingDown      = errors.New("缺少 down 迁移脚本")
+	ErrChecksumMismatch = errors.New("已执行的迁移脚本被修改")
+	ErrUnknownVersion   = errors.New("数据库中存在未知的迁移版本")
+	ErrLocked           = errors.New("其他进程正在执行迁移")
+	ErrBaselineVersion  = errors.New("基线版本不存在")
+)
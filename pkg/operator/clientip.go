@@ -0,0 +1,87 @@
+package operator
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// forwardedForHeader 反向代理追加客户端地址的请求头
+const forwardedForHeader = "X-Forwarded-For"
+
+// TrustedProxies 可信的反向代理地址段
+// 只有直连地址属于可信代理时才解析 X-Forwarded-For，客户端自行携带的请求头不会被采信
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies 解析可信代理列表，元素为 IP 或 CIDR
+func ParseTrustedProxies(list []string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(list))
+	for _, item := range list {
+		item = strings.TrimSpace(item)
+		if strings.Contains(item, "/") {
+			prefix, err := netip.ParsePrefix(item)
+			if err != nil {
+				return nil, fmt.Errorf("可信代理 %q 格式错误: %w", item, err)
+			}
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(item)
+		if err != nil {
+			return nil, fmt.Errorf("可信代理 %q 格式错误: %w", item, err)
+		}
+		addr = addr.Unmap()
+		proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return proxies, nil
+}
+
+// ClientIP 解析请求的客户端IP
+// 直连地址不是可信代理时直接使用直连地址；否则从右向左跳过 X-Forwarded-For 中的可信代理，
+// 第一个不可信的地址即客户端地址，全部可信时取最左侧的地址
+func (p TrustedProxies) ClientIP(r *http.Request) string {
+	ip := RemoteIP(r)
+	if !p.contains(ip) {
+		return ip
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values(forwardedForHeader), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			// 格式错误的地址无法继续向前追溯，以最后一个可信的地址为准
+			return ip
+		}
+		ip = hop
+		if !p.contains(hop) {
+			return hop
+		}
+	}
+	return ip
+}
+
+// contains 地址是否属于可信代理
+func (p TrustedProxies) contains(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoteIP 请求的直连地址，不读取任何可由客户端伪造的请求头
+func RemoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,45 @@
+package operator
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrustedProxies_ClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatalf("解析可信代理失败: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		remote string
+		xff    string
+		want   string
+	}{
+		{"无代理", "203.0.113.7:5000", "", "203.0.113.7"},
+		{"不可信直连伪造请求头", "203.0.113.7:5000", "1.2.3.4", "203.0.113.7"},
+		{"可信代理", "10.0.0.2:5000", "203.0.113.7", "203.0.113.7"},
+		{"多级可信代理", "10.0.0.2:5000", "203.0.113.7, 192.168.1.1", "203.0.113.7"},
+		{"客户端伪造最左侧地址", "10.0.0.2:5000", "1.2.3.4, 203.0.113.7", "203.0.113.7"},
+		{"全部可信", "10.0.0.2:5000", "10.0.0.3", "10.0.0.3"},
+		{"格式错误", "10.0.0.2:5000", "unknown", "10.0.0.2"},
+		{"IPv6", "[2001:db8::1]:5000", "", "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remote
+			if tt.xff != "" {
+				r.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if got := proxies.ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("期望解析错误")
+	}
+}
@@ -0,0 +1,119 @@
+package operator
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// 常量定义
+const (
+	// ReasonHeader 变更原因请求头，非 ASCII 字符需按 URL 编码
+	ReasonHeader = "X-Change-Reason"
+
+	// MaxReasonLength 变更原因最大长度（字符数）
+	MaxReasonLength = 255
+)
+
+type operatorKey struct{}
+
+type reasonKey struct{}
+
+type clientIPKey struct{}
+
+type requestKey struct{}
+
+type rolesKey struct{}
+
+// request 发起操作的 HTTP 请求
+type request struct {
+	method string
+	path   string
+}
+
+// WithOperator 将操作人ID写入上下文
+func WithOperator(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, operatorKey{}, id)
+}
+
+// FromContext 从上下文读取操作人ID
+func FromContext(ctx context.Context) (int64, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	id, ok := ctx.Value(operatorKey{}).(int64)
+	return id, ok && id > 0
+}
+
+// WithReason 将变更原因写入上下文，超长部分截断
+func WithReason(ctx context.Context, reason string) context.Context {
+	reason = strings.TrimSpace(reason)
+	if runes := []rune(reason); len(runes) > MaxReasonLength {
+		reason = string(runes[:MaxReasonLength])
+	}
+	return context.WithValue(ctx, reasonKey{}, reason)
+}
+
+// Reason 从上下文读取变更原因
+func Reason(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	reason, _ := ctx.Value(reasonKey{}).(string)
+	return reason
+}
+
+// WithClientIP 将客户端IP写入上下文
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIP 从上下文读取客户端IP
+func ClientIP(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// WithRequest 将发起操作的请求方法和路径写入上下文
+func WithRequest(ctx context.Context, method, path string) context.Context {
+	return context.WithValue(ctx, requestKey{}, request{method: method, path: path})
+}
+
+// Request 从上下文读取发起操作的请求方法和路径
+func Request(ctx context.Context) (method, path string) {
+	if ctx == nil {
+		return "", ""
+	}
+	req, _ := ctx.Value(requestKey{}).(request)
+	return req.method, req.path
+}
+
+// WithRoles 将操作人的角色写入上下文
+func WithRoles(ctx context.Context, roles []string) context.Context {
+	return context.WithValue(ctx, rolesKey{}, roles)
+}
+
+// HasRole 判断操作人是否具有指定角色
+func HasRole(ctx context.Context, role string) bool {
+	if ctx == nil || role == "" {
+		return false
+	}
+	roles, _ := ctx.Value(rolesKey{}).([]string)
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// DecodeReason 解码请求头中的变更原因，解码失败时按原文使用
+func DecodeReason(header string) string {
+	if decoded, err := url.QueryUnescape(header); err == nil {
+		return decoded
+	}
+	return header
+}
@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// sweepInterval 内存令牌桶清理间隔
+	sweepInterval = time.Minute
+
+	// DefaultMaxBuckets 未指定时内存令牌桶的数量上限
+	DefaultMaxBuckets = 100000
+)
+
+// Limit 令牌桶参数
+type Limit struct {
+	Rate  float64 // 每秒补充的令牌数
+	Burst int     // 令牌桶容量
+}
+
+// fillTime 令牌桶从空到满的时间
+func (l Limit) fillTime() time.Duration {
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
+
+// Store 令牌桶存储
+type Store interface {
+	// Take 从 key 对应的令牌桶取出一个令牌，返回取出后（拒绝时为当前）剩余的令牌数
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (tokens float64, allowed bool, err error)
+}
+
+// take 按经过的时间补充令牌后取出一个令牌，返回剩余令牌数
+func take(tokens float64, elapsed time.Duration, limit Limit) (float64, bool) {
+	if elapsed > 0 {
+		tokens = math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.Rate)
+	}
+	if tokens < 1 {
+		return tokens, false
+	}
+	return tokens - 1, true
+}
+
+// bucket 内存令牌桶
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// MemoryStore 进程内令牌桶存储，补满后闲置的令牌桶定期清理
+// 令牌桶数量有上限，大量不同主体（如大量客户端IP）的请求不会无限占用内存
+type MemoryStore struct {
+	mu         sync.Mutex
+	buckets    map[string]*bucket
+	maxBuckets int
+	lastSweep  time.Time
+}
+
+// NewMemoryStore 创建内存令牌桶存储，maxBuckets 为令牌桶数量上限，不大于 0 时使用 DefaultMaxBuckets
+func NewMemoryStore(maxBuckets int) *MemoryStore {
+	if maxBuckets <= 0 {
+		maxBuckets = DefaultMaxBuckets
+	}
+	return &MemoryStore{buckets: make(map[string]*bucket), maxBuckets: maxBuckets}
+}
+
+// Take 从令牌桶取出一个令牌
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (float64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok || b.limit != limit {
+		// 新令牌桶或规则已修改，按满桶开始计数
+		if !ok && len(s.buckets) >= s.maxBuckets {
+			s.evict()
+		}
+		b = &bucket{tokens: float64(limit.Burst), last: now, limit: limit}
+		s.buckets[key] = b
+	}
+
+	tokens, allowed := take(b.tokens, now.Sub(b.last), limit)
+	b.tokens, b.last = tokens, now
+	return tokens, allowed, nil
+}
+
+// sweep 清理已补满的令牌桶，补满的令牌桶与新建的令牌桶等价
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= b.limit.fillTime() {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// evict 达到数量上限时淘汰任意一个令牌桶，被淘汰的主体下次请求时按满桶重新计数
+func (s *MemoryStore) evict() {
+	for key := range s.buckets {
+		delete(s.buckets, key)
+		return
+	}
+}
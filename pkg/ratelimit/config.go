@@ -0,0 +1,38 @@
+package ratelimit
+
+// 存储后端
+const (
+	BackendMemory = "memory" // 进程内令牌桶，多实例部署时每个实例单独计数
+	BackendRedis  = "redis"  // Redis 令牌桶，多实例共享配额
+)
+
+// 限流维度
+const (
+	KeyUser   = "user"   // 按用户，未识别用户时按客户端IP
+	KeyTenant = "tenant" // 按租户，未识别租户时按客户端IP
+	KeyIP     = "ip"     // 按客户端IP
+)
+
+// Config 限流配置
+// 修改 Enabled 和 Rules 后热加载生效，Backend 和 Prefix 需重启生效
+type Config struct {
+	Enabled        bool   `json:",optional"`
+	Backend        string `json:",default=memory,options=memory|redis"` // redis 需配置 Redis
+	Prefix         string `json:",default=idrm:ratelimit"`              // Redis 键前缀
+	ReloadInterval int    `json:",default=10"`                          // 检查配置文件修改的间隔(秒)，0 表示不热加载
+	MaxBuckets     int    `json:",default=100000"`                      // 进程内令牌桶数量上限，达到上限时淘汰已有的令牌桶
+	Rules          []Rule `json:",optional"`
+}
+
+// Rule 路由类别的限流规则，请求按顺序匹配第一条规则，未匹配任何规则的请求不限流
+type Rule struct {
+	Name string // 路由类别，用于区分令牌桶和响应头
+
+	// 匹配的路由，格式为 "METHOD /path" 或 "/path"（匹配所有方法）
+	// 路径中 :name 匹配任意一段，末尾的 * 匹配剩余路径；为空时匹配所有路由
+	Routes []string `json:",optional"`
+
+	Key   string  `json:",default=user,options=user|tenant|ip"`
+	Rate  float64 // 每秒补充的令牌数
+	Burst int     // 令牌桶容量，即允许的突发请求数
+}
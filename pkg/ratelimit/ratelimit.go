@@ -0,0 +1,275 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"idrm/pkg/errorx"
+	"idrm/pkg/operator"
+	"idrm/pkg/response"
+	"idrm/pkg/telemetry/metrics"
+	"idrm/pkg/tenant"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 响应头
+const (
+	HeaderLimit      = "X-RateLimit-Limit"     // 令牌桶容量
+	HeaderRemaining  = "X-RateLimit-Remaining" // 剩余令牌数
+	HeaderReset      = "X-RateLimit-Reset"     // 令牌桶补满的等待秒数
+	HeaderRetryAfter = "Retry-After"           // 被拒绝时下一个令牌可用的等待秒数
+)
+
+// Result 限流结果
+type Result struct {
+	Rule       string
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // 被拒绝时下一个令牌可用的等待时间
+	Reset      time.Duration // 令牌桶补满的等待时间
+}
+
+// Limiter 按路由类别和主体限流
+type Limiter struct {
+	store Store
+	rules atomic.Pointer[[]rule] // 未启用时为 nil
+	now   func() time.Time
+}
+
+// New 创建限流器
+func New(c Config, store Store) (*Limiter, error) {
+	l := &Limiter{store: store, now: time.Now}
+	if err := l.Update(c); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Update 更新限流规则，规则无效时返回错误并保留原规则
+func (l *Limiter) Update(c Config) error {
+	if !c.Enabled {
+		l.rules.Store(nil)
+		return nil
+	}
+
+	rules, err := compile(c.Rules)
+	if err != nil {
+		return err
+	}
+	l.rules.Store(&rules)
+	return nil
+}
+
+// Allow 对请求限流，未匹配任何规则时 ok 为 false
+// 令牌桶存储出错时放行，避免限流故障导致接口不可用
+func (l *Limiter) Allow(r *http.Request) (res Result, ok bool) {
+	rules := l.rules.Load()
+	if rules == nil {
+		return Result{}, false
+	}
+
+	for _, rl := range *rules {
+		if !rl.match(r) {
+			continue
+		}
+
+		key := rl.Name + ":" + identity(r, rl.Key)
+		tokens, allowed, err := l.store.Take(r.Context(), key, rl.limit, l.now())
+		if err != nil {
+			logx.WithContext(r.Context()).Errorf("限流失败，放行请求: %v", err)
+			return Result{}, false
+		}
+		return newResult(rl, tokens, allowed), true
+	}
+	return Result{}, false
+}
+
+// Handle 限流中间件，需在认证中间件之后执行以识别用户和租户
+// 匹配规则的请求返回 X-RateLimit-* 响应头，超出配额时返回 429 和 errorx.ErrCodeRateLimited
+func (l *Limiter) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, ok := l.Allow(r)
+		if !ok {
+			next(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Set(HeaderLimit, strconv.Itoa(res.Limit))
+		h.Set(HeaderRemaining, strconv.Itoa(res.Remaining))
+		h.Set(HeaderReset, strconv.Itoa(seconds(res.Reset)))
+		if res.Allowed {
+			next(w, r)
+			return
+		}
+
+		h.Set(HeaderRetryAfter, strconv.Itoa(max(1, seconds(res.RetryAfter))))
+		err := errorx.NewWithCode(errorx.ErrCodeRateLimited).(*errorx.CodeError)
+		metrics.RecordError(r.Context(), err)
+		response.WriteJSON(w, http.StatusTooManyRequests, &response.HttpResponse{
+			Code: err.GetCode(),
+			Msg:  err.GetMsg(),
+		})
+	}
+}
+
+// newResult 根据剩余令牌数计算限流结果
+func newResult(rl rule, tokens float64, allowed bool) Result {
+	res := Result{
+		Rule:      rl.Name,
+		Allowed:   allowed,
+		Limit:     rl.limit.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     rateDuration(float64(rl.limit.Burst)-tokens, rl.limit.Rate),
+	}
+	if !allowed {
+		res.RetryAfter = rateDuration(1-tokens, rl.limit.Rate)
+	}
+	return res
+}
+
+// rateDuration 按补充速率补充 n 个令牌所需的时间
+func rateDuration(n, rate float64) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	return time.Duration(n / rate * float64(time.Second))
+}
+
+// seconds 向上取整的秒数
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// identity 返回请求主体，未识别用户或租户时按客户端IP
+func identity(r *http.Request, key string) string {
+	ctx := r.Context()
+	switch key {
+	case KeyUser, "":
+		if id, ok := operator.FromContext(ctx); ok {
+			return "user:" + strconv.FormatInt(id, 10)
+		}
+	case KeyTenant:
+		if id, ok := tenant.FromContext(ctx); ok {
+			return "tenant:" + id
+		}
+	}
+
+	ip := operator.ClientIP(ctx)
+	if ip == "" {
+		ip = operator.RemoteIP(r)
+	}
+	return "ip:" + ip
+}
+
+// rule 编译后的限流规则
+type rule struct {
+	Rule
+	routes []route
+	limit  Limit
+}
+
+// route 路由匹配模式
+type route struct {
+	method   string   // 为空时匹配所有方法
+	segments []string // 路径段，:name 匹配任意一段
+	prefix   bool     // 末尾为 *，匹配剩余路径
+}
+
+// compile 校验并编译限流规则
+func compile(rules []Rule) ([]rule, error) {
+	names := make(map[string]bool, len(rules))
+	compiled := make([]rule, 0, len(rules))
+	for _, r := range rules {
+		if r.Name == "" {
+			return nil, errors.New("限流规则缺少名称 Name")
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("限流规则 %s 重复", r.Name)
+		}
+		names[r.Name] = true
+
+		if r.Rate <= 0 || r.Burst < 1 {
+			return nil, fmt.Errorf("限流规则 %s 的 Rate 必须大于 0 且 Burst 不小于 1", r.Name)
+		}
+		switch r.Key {
+		case "", KeyUser, KeyTenant, KeyIP:
+		default:
+			return nil, fmt.Errorf("限流规则 %s 的限流维度 %s 无效", r.Name, r.Key)
+		}
+
+		c := rule{Rule: r, limit: Limit{Rate: r.Rate, Burst: r.Burst}}
+		for _, pattern := range r.Routes {
+			rt, err := parseRoute(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("限流规则 %s: %w", r.Name, err)
+			}
+			c.routes = append(c.routes, rt)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// parseRoute 解析 "METHOD /path" 或 "/path" 格式的路由
+func parseRoute(pattern string) (route, error) {
+	var rt route
+	path := strings.TrimSpace(pattern)
+	if method, rest, ok := strings.Cut(path, " "); ok {
+		rt.method = strings.ToUpper(method)
+		path = strings.TrimSpace(rest)
+	}
+	if !strings.HasPrefix(path, "/") {
+		return rt, fmt.Errorf("路由 %q 格式错误，应为 \"METHOD /path\" 或 \"/path\"", pattern)
+	}
+
+	rt.segments = splitPath(path)
+	if n := len(rt.segments); n > 0 && rt.segments[n-1] == "*" {
+		rt.segments = rt.segments[:n-1]
+		rt.prefix = true
+	}
+	return rt, nil
+}
+
+// match 请求是否匹配规则，规则未配置路由时匹配所有请求
+func (rl rule) match(r *http.Request) bool {
+	if len(rl.routes) == 0 {
+		return true
+	}
+	segments := splitPath(r.URL.Path)
+	for _, rt := range rl.routes {
+		if rt.match(r.Method, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// match 请求方法和路径段是否匹配路由
+func (rt route) match(method string, segments []string) bool {
+	if rt.method != "" && rt.method != method {
+		return false
+	}
+	if len(segments) < len(rt.segments) || (!rt.prefix && len(segments) != len(rt.segments)) {
+		return false
+	}
+	for i, seg := range rt.segments {
+		if !strings.HasPrefix(seg, ":") && seg != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// splitPath 将路径拆分为路径段
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+}
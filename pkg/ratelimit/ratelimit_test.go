@@ -0,0 +1,274 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"idrm/pkg/errorx"
+	"idrm/pkg/operator"
+	"idrm/pkg/tenant"
+)
+
+// clock 可控的时钟
+type clock struct{ t time.Time }
+
+func newClock() *clock {
+	return &clock{t: time.Unix(1700000000, 0)}
+}
+
+func (c *clock) now() time.Time { return c.t }
+
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// assignRule 限制标签关联接口的规则，每秒补充一个令牌
+func assignRule(burst int, key string) Rule {
+	return Rule{Name: "write", Routes: []string{"POST /api/v1/resources/tags/assign"}, Key: key, Rate: 1, Burst: burst}
+}
+
+// newLimiter 创建使用内存存储和可控时钟的限流器
+func newLimiter(t *testing.T, rules ...Rule) (*Limiter, *clock) {
+	t.Helper()
+	l, err := New(Config{Enabled: true, Rules: rules}, NewMemoryStore(0))
+	if err != nil {
+		t.Fatalf("创建限流器失败: %v", err)
+	}
+	c := newClock()
+	l.now = c.now
+	return l, c
+}
+
+// request 创建带用户、租户和客户端IP的请求
+func request(method, path string, userID int64, tenantID, ip string) *http.Request {
+	r := httptest.NewRequest(method, path, nil)
+	ctx := operator.WithClientIP(r.Context(), ip)
+	if userID > 0 {
+		ctx = operator.WithOperator(ctx, userID)
+	}
+	if tenantID != "" {
+		ctx = tenant.WithTenant(ctx, tenantID)
+	}
+	return r.WithContext(ctx)
+}
+
+func TestLimiter_TokenBucket(t *testing.T) {
+	l, c := newLimiter(t, assignRule(2, KeyUser))
+	r := request(http.MethodPost, "/api/v1/resources/tags/assign", 1, "t1", "10.0.0.1")
+
+	for i, want := range []bool{true, true, false} {
+		res, ok := l.Allow(r)
+		if !ok || res.Allowed != want {
+			t.Fatalf("第 %d 次请求 allowed = %v, want %v", i+1, res.Allowed, want)
+		}
+	}
+
+	res, _ := l.Allow(r)
+	if res.Remaining != 0 || res.RetryAfter != time.Second || res.Reset != 2*time.Second {
+		t.Errorf("拒绝结果 = %+v", res)
+	}
+
+	// 每秒补充一个令牌
+	c.advance(time.Second)
+	if res, _ := l.Allow(r); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("补充后结果 = %+v, want allowed", res)
+	}
+	c.advance(time.Hour)
+	if res, _ := l.Allow(r); !res.Allowed || res.Remaining != 1 {
+		t.Errorf("令牌数不应超过容量: %+v", res)
+	}
+}
+
+func TestLimiter_Keys(t *testing.T) {
+	tests := []struct {
+		key   string
+		other *http.Request // 与基准请求不共享配额的请求
+		same  *http.Request // 与基准请求共享配额的请求
+	}{
+		{KeyUser, request(http.MethodPost, "/api/v1/resources/tags/assign", 2, "t1", "10.0.0.1"), request(http.MethodPost, "/api/v1/resources/tags/assign", 1, "t2", "10.0.0.2")},
+		{KeyTenant, request(http.MethodPost, "/api/v1/resources/tags/assign", 1, "t2", "10.0.0.1"), request(http.MethodPost, "/api/v1/resources/tags/assign", 2, "t1", "10.0.0.2")},
+		{KeyIP, request(http.MethodPost, "/api/v1/resources/tags/assign", 1, "t1", "10.0.0.2"), request(http.MethodPost, "/api/v1/resources/tags/assign", 2, "t2", "10.0.0.1")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			l, _ := newLimiter(t, assignRule(1, tt.key))
+			base := request(http.MethodPost, "/api/v1/resources/tags/assign", 1, "t1", "10.0.0.1")
+			if res, _ := l.Allow(base); !res.Allowed {
+				t.Fatal("首次请求应放行")
+			}
+			if res, _ := l.Allow(tt.other); !res.Allowed {
+				t.Error("不同主体不应共享配额")
+			}
+			if res, _ := l.Allow(tt.same); res.Allowed {
+				t.Error("相同主体应共享配额")
+			}
+		})
+	}
+
+	// 未识别用户时按客户端IP限流
+	if got := identity(request(http.MethodGet, "/", 0, "", "10.0.0.9"), KeyUser); got != "ip:10.0.0.9" {
+		t.Errorf("identity() = %s, want ip:10.0.0.9", got)
+	}
+}
+
+func TestLimiter_Routes(t *testing.T) {
+	l, _ := newLimiter(t,
+		Rule{Name: "write", Routes: []string{"POST /api/v1/resources/tags/*", "patch /api/v1/tags/:id"}, Rate: 1, Burst: 1},
+		Rule{Name: "default", Rate: 100, Burst: 100},
+	)
+
+	tests := []struct {
+		method, path, rule string
+	}{
+		{http.MethodPost, "/api/v1/resources/tags/assign", "write"},
+		{http.MethodPost, "/api/v1/resources/tags/unassign", "write"},
+		{http.MethodPatch, "/api/v1/tags/5", "write"},
+		{http.MethodGet, "/api/v1/resources/tags", "default"},
+		{http.MethodPatch, "/api/v1/tags/5/history", "default"},
+	}
+	for _, tt := range tests {
+		res, ok := l.Allow(request(tt.method, tt.path, 1, "", "10.0.0.1"))
+		if !ok || res.Rule != tt.rule {
+			t.Errorf("%s %s 匹配规则 %q, want %q", tt.method, tt.path, res.Rule, tt.rule)
+		}
+	}
+
+	// 未匹配任何规则时不限流
+	l, _ = newLimiter(t, assignRule(1, KeyUser))
+	if _, ok := l.Allow(request(http.MethodGet, "/api/v1/tags", 1, "", "10.0.0.1")); ok {
+		t.Error("未匹配规则的请求不应限流")
+	}
+}
+
+func TestLimiter_Handle(t *testing.T) {
+	l, _ := newLimiter(t, assignRule(1, KeyUser))
+	h := l.Handle(func(w http.ResponseWriter, r *http.Request) {})
+	r := request(http.MethodPost, "/api/v1/resources/tags/assign", 1, "t1", "10.0.0.1")
+
+	rec := httptest.NewRecorder()
+	h(rec, r)
+	if rec.Code != http.StatusOK || rec.Header().Get(HeaderLimit) != "1" || rec.Header().Get(HeaderRemaining) != "0" {
+		t.Errorf("放行响应 = %d %v", rec.Code, rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	h(rec, r)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get(HeaderRetryAfter) != "1" || rec.Header().Get(HeaderReset) != "1" {
+		t.Errorf("拒绝响应 = %d %v", rec.Code, rec.Header())
+	}
+	var body struct {
+		Code int `json:"code"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Code != errorx.ErrCodeRateLimited {
+		t.Errorf("body = %s, want errorx code %d", rec.Body.String(), errorx.ErrCodeRateLimited)
+	}
+
+	// 未匹配规则的请求不返回限流响应头
+	rec = httptest.NewRecorder()
+	h(rec, request(http.MethodGet, "/api/v1/tags", 1, "t1", "10.0.0.1"))
+	if rec.Header().Get(HeaderLimit) != "" {
+		t.Errorf("未匹配规则的请求不应返回限流响应头: %v", rec.Header())
+	}
+}
+
+func TestLimiter_Update(t *testing.T) {
+	l, _ := newLimiter(t, assignRule(1, KeyUser))
+
+	invalid := []Rule{
+		{Routes: []string{"/a"}, Rate: 1, Burst: 1},
+		{Name: "a", Rate: 0, Burst: 1},
+		{Name: "a", Rate: 1, Burst: 1, Key: "session"},
+		{Name: "a", Routes: []string{"POST api/v1"}, Rate: 1, Burst: 1},
+	}
+	for _, rule := range invalid {
+		if err := l.Update(Config{Enabled: true, Rules: []Rule{rule}}); err == nil {
+			t.Errorf("Update(%+v) 应返回错误", rule)
+		}
+	}
+	if err := l.Update(Config{Enabled: true, Rules: []Rule{assignRule(1, KeyUser), assignRule(1, KeyUser)}}); err == nil {
+		t.Error("重复的规则名称应返回错误")
+	}
+
+	// 无效配置保留原规则
+	r := request(http.MethodPost, "/api/v1/resources/tags/assign", 1, "t1", "10.0.0.1")
+	if _, ok := l.Allow(r); !ok {
+		t.Error("更新失败后应保留原规则")
+	}
+
+	l.Update(Config{Enabled: false, Rules: []Rule{assignRule(1, KeyUser)}})
+	if _, ok := l.Allow(r); ok {
+		t.Error("关闭后不应限流")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("写入配置失败: %v", err)
+		}
+	}
+	write("Name: idrm-api\nRateLimit:\n  Enabled: false\n")
+
+	c, err := LoadFile(path)
+	if err != nil || c.Enabled || c.Backend != BackendMemory {
+		t.Fatalf("LoadFile() = %+v, %v", c, err)
+	}
+	l, err := New(c, NewMemoryStore(0))
+	if err != nil {
+		t.Fatalf("创建限流器失败: %v", err)
+	}
+
+	hook := Watch(l, path, 10*time.Millisecond)
+	if err := hook.Start(context.Background()); err != nil {
+		t.Fatalf("启动失败: %v", err)
+	}
+	defer hook.Stop(context.Background())
+
+	write(`Name: idrm-api
+RateLimit:
+  Enabled: true
+  Rules:
+    - Name: write
+      Routes: ["POST /api/v1/resources/tags/assign"]
+      Rate: 1
+      Burst: 1
+`)
+	r := request(http.MethodPost, "/api/v1/resources/tags/assign", 1, "t1", "10.0.0.1")
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if res, ok := l.Allow(r); ok {
+			if res.Limit != 1 {
+				t.Errorf("热加载规则 = %+v", res)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("配置修改后未热加载")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestMemoryStore_MaxBuckets 测试令牌桶数量不超过上限
+func TestMemoryStore_MaxBuckets(t *testing.T) {
+	s := NewMemoryStore(2)
+	limit := Limit{Rate: 1, Burst: 1}
+	now := time.Now()
+
+	for _, key := range []string{"ip:1", "ip:2", "ip:3"} {
+		if _, allowed, _ := s.Take(context.Background(), key, limit, now); !allowed {
+			t.Errorf("%s 首次请求应放行", key)
+		}
+	}
+	if n := len(s.buckets); n != 2 {
+		t.Errorf("期望保留2个令牌桶, 实际=%d", n)
+	}
+	if _, ok := s.buckets["ip:3"]; !ok {
+		t.Error("新主体的令牌桶应被保留")
+	}
+}
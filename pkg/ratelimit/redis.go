@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// redisRetryInterval Redis 不可用后改用内存令牌桶的时长，之后再次尝试 Redis
+const redisRetryInterval = 5 * time.Second
+
+// tokenScript 令牌桶脚本，令牌数和更新时间保存在同一个 hash 中，令牌数以字符串返回以保留小数
+// KEYS[1] 令牌桶键；ARGV: 每秒令牌数、容量、当前时间(毫秒)
+var tokenScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+    tokens = burst
+    ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local allowed = 0
+if tokens >= 1 then
+    tokens = tokens - 1
+    allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore Redis 令牌桶存储，多实例共享配额
+// Redis 不可用时改用进程内令牌桶，避免限流故障导致接口不可用
+type RedisStore struct {
+	rds        *redis.Redis
+	prefix     string
+	fallback   *MemoryStore
+	downUntil  atomic.Int64 // Redis 不可用时，改用内存令牌桶的截止时间(UnixNano)
+	retryAfter time.Duration
+}
+
+// NewRedisStore 创建 Redis 令牌桶存储，prefix 为键前缀
+func NewRedisStore(rds *redis.Redis, prefix string) *RedisStore {
+	return &RedisStore{
+		rds:        rds,
+		prefix:     prefix,
+		fallback:   NewMemoryStore(0),
+		retryAfter: redisRetryInterval,
+	}
+}
+
+// Take 从令牌桶取出一个令牌
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (float64, bool, error) {
+	if now.UnixNano() < s.downUntil.Load() {
+		return s.fallback.Take(ctx, key, limit, now)
+	}
+
+	tokens, allowed, err := s.take(ctx, key, limit, now)
+	if err == nil {
+		return tokens, allowed, nil
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false, err
+	}
+
+	logx.WithContext(ctx).Errorf("Redis 限流失败，%v 内改用进程内限流: %v", s.retryAfter, err)
+	s.downUntil.Store(now.Add(s.retryAfter).UnixNano())
+	return s.fallback.Take(ctx, key, limit, now)
+}
+
+// take 执行令牌桶脚本
+func (s *RedisStore) take(ctx context.Context, key string, limit Limit, now time.Time) (float64, bool, error) {
+	resp, err := s.rds.ScriptRunCtx(ctx, tokenScript, []string{s.prefix + ":" + key},
+		strconv.FormatFloat(limit.Rate, 'f', -1, 64),
+		strconv.Itoa(limit.Burst),
+		strconv.FormatInt(now.UnixMilli(), 10),
+	)
+	if err != nil {
+		return 0, false, err
+	}
+
+	values, ok := resp.([]any)
+	if !ok || len(values) != 2 {
+		return 0, false, fmt.Errorf("限流脚本返回值格式错误: %v", resp)
+	}
+	allowed, _ := values[0].(int64)
+	raw, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("限流脚本返回值格式错误: %w", err)
+	}
+	return math.Max(0, tokens), allowed == 1, nil
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+func TestRedisStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := NewRedisStore(redis.New(mr.Addr()), "test:ratelimit")
+	limit := Limit{Rate: 2, Burst: 2}
+	now := time.Unix(1700000000, 0)
+	ctx := context.Background()
+
+	for i, want := range []bool{true, true, false} {
+		_, allowed, err := store.Take(ctx, "write:user:1", limit, now)
+		if err != nil || allowed != want {
+			t.Fatalf("第 %d 次 Take() = %v, %v, want %v", i+1, allowed, err, want)
+		}
+	}
+
+	// 每秒补充两个令牌，保留小数部分
+	tokens, allowed, err := store.Take(ctx, "write:user:1", limit, now.Add(750*time.Millisecond))
+	if err != nil || !allowed || tokens != 0.5 {
+		t.Errorf("Take() = %v, %v, %v, want 0.5 tokens", tokens, allowed, err)
+	}
+	if ttl := mr.TTL("test:ratelimit:write:user:1"); ttl <= 0 {
+		t.Errorf("令牌桶键应设置过期时间, ttl=%v", ttl)
+	}
+
+	// 不同主体使用不同的令牌桶
+	if _, allowed, _ := store.Take(ctx, "write:user:2", limit, now); !allowed {
+		t.Error("不同主体不应共享配额")
+	}
+}
+
+func TestRedisStore_Fallback(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := NewRedisStore(redis.New(mr.Addr()), "test:ratelimit")
+	limit := Limit{Rate: 1, Burst: 1}
+	now := time.Unix(1700000000, 0)
+	ctx := context.Background()
+
+	mr.Close()
+	if _, allowed, err := store.Take(ctx, "write:user:1", limit, now); err != nil || !allowed {
+		t.Fatalf("Redis 不可用时应改用内存令牌桶: %v, %v", allowed, err)
+	}
+	if _, allowed, _ := store.Take(ctx, "write:user:1", limit, now); allowed {
+		t.Error("内存令牌桶应继续限流")
+	}
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"idrm/pkg/lifecycle"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// fileConfig 配置文件中的限流配置，其余配置项忽略
+type fileConfig struct {
+	RateLimit Config `json:",optional"`
+}
+
+// LoadFile 从配置文件读取 RateLimit 配置
+func LoadFile(path string) (Config, error) {
+	var c fileConfig
+	if err := conf.Load(path, &c); err != nil {
+		return Config{}, fmt.Errorf("读取限流配置失败: %w", err)
+	}
+	return c.RateLimit, nil
+}
+
+// Watch 返回限流配置热加载的生命周期钩子
+// 每 interval 检查一次配置文件，修改后重新加载 RateLimit 配置；配置无效时记录错误并保留原规则
+func Watch(l *Limiter, path string, interval time.Duration) lifecycle.Hook {
+	var (
+		done = make(chan struct{})
+		wg   sync.WaitGroup
+		once sync.Once
+	)
+
+	return lifecycle.Hook{
+		Name: "ratelimit-reload",
+		Start: func(ctx context.Context) error {
+			info, err := os.Stat(path)
+			if err != nil {
+				return fmt.Errorf("读取配置文件失败: %w", err)
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				modTime, size := info.ModTime(), info.Size()
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+					}
+
+					info, err := os.Stat(path)
+					if err != nil || (info.ModTime().Equal(modTime) && info.Size() == size) {
+						continue
+					}
+					modTime, size = info.ModTime(), info.Size()
+					reload(l, path)
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			once.Do(func() { close(done) })
+			wg.Wait()
+			return nil
+		},
+	}
+}
+
+// reload 重新加载限流配置
+func reload(l *Limiter, path string) {
+	c, err := LoadFile(path)
+	if err == nil {
+		err = l.Update(c)
+	}
+	if err != nil {
+		logx.Errorf("热加载限流配置失败，保留原规则: %v", err)
+		return
+	}
+	logx.Infof("限流配置已更新 [enabled=%t, rules=%d]", c.Enabled, len(c.Rules))
+}
@@ -1,14 +1,19 @@
 package audit
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
-	"net/http"
+	"fmt"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
+	"idrm/pkg/telemetry/delivery"
+
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -18,38 +23,156 @@ var (
 )
 
 // AuditLogger 审计日志记录器
+// 审计日志先落盘再批量写入存储目标，写入失败时重试，保证至少一次投递；
+// 配置多个存储目标时每个目标使用独立的落盘队列，某个目标故障不影响其他目标
 type AuditLogger struct {
-	serviceName string
-	url         string
-	client      *http.Client
-	buffer      []AuditLog
-	bufferSize  int
-	mu          sync.Mutex
-	closeChan   chan struct{}
+	serviceName  string
+	outputs      []*output
+	drainTimeout time.Duration
+	closeOnce    sync.Once
+}
+
+// output 存储目标及其落盘队列
+type output struct {
+	sink  Sink
+	queue *delivery.Queue
+}
+
+// Option 审计日志初始化选项
+type Option func(*options)
+
+type options struct {
+	db    *gorm.DB
+	sinks []Sink
+}
+
+// WithDB 指定 db 存储目标使用的数据库连接
+func WithDB(db *gorm.DB) Option {
+	return func(o *options) {
+		o.db = db
+	}
+}
+
+// WithSink 追加自定义存储目标
+func WithSink(sink Sink) Option {
+	return func(o *options) {
+		o.sinks = append(o.sinks, sink)
+	}
 }
 
 // Init 初始化审计日志
-func Init(config AuditConfig, serviceName string) {
+func Init(config AuditConfig, serviceName string, opts ...Option) error {
 	if !config.Enabled {
 		logx.Info("审计日志未启用")
-		return
+		return nil
+	}
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	sinks, err := buildSinks(config, o)
+	if err != nil {
+		closeSinks(sinks)
+		return fmt.Errorf("初始化审计日志失败: %w", err)
 	}
 
+	spoolDir := config.SpoolDir
+	if spoolDir == "" {
+		spoolDir = filepath.Join("spool", "audit")
+	}
+	outputs := make([]*output, 0, len(sinks))
+	names := make([]string, 0, len(sinks))
+	for _, sink := range sinks {
+		queue, err := delivery.New("audit_"+sink.Name(), delivery.Config{
+			Dir:           filepath.Join(spoolDir, sink.Name()),
+			MaxSpoolBytes: int64(config.SpoolMaxSize) << 20,
+			BatchSize:     config.Buffer,
+			FlushInterval: 10 * time.Second,
+			MaxBackoff:    time.Duration(config.MaxBackoff) * time.Second,
+		}, sinkSender(sink))
+		if err != nil {
+			for _, out := range outputs {
+				out.queue.Close(context.Background())
+			}
+			closeSinks(sinks)
+			return fmt.Errorf("初始化审计日志失败: %w", err)
+		}
+		outputs = append(outputs, &output{sink: sink, queue: queue})
+		names = append(names, sink.Name())
+	}
+
+	drainTimeout := time.Duration(config.DrainTimeout) * time.Second
+	if drainTimeout <= 0 {
+		drainTimeout = 10 * time.Second
+	}
 	auditLogger = &AuditLogger{
-		serviceName: serviceName,
-		url:         config.Url,
-		buffer:      make([]AuditLog, 0, config.Buffer),
-		bufferSize:  config.Buffer,
-		closeChan:   make(chan struct{}),
-		client: &http.Client{
-			Timeout: 5 * time.Second,
-		},
+		serviceName:  serviceName,
+		outputs:      outputs,
+		drainTimeout: drainTimeout,
+	}
+
+	logx.Infof("审计日志初始化完成 [sinks=%s, buffer=%d, spool=%s]", strings.Join(names, ","), config.Buffer, spoolDir)
+	return nil
+}
+
+// buildSinks 按配置创建存储目标，未配置时使用 http
+func buildSinks(config AuditConfig, o options) ([]Sink, error) {
+	kinds := config.Sinks
+	if len(kinds) == 0 && len(o.sinks) == 0 {
+		kinds = []string{SinkHTTP}
+	}
+
+	sinks := make([]Sink, 0, len(kinds)+len(o.sinks))
+	for _, kind := range kinds {
+		var sink Sink
+		switch strings.ToLower(strings.TrimSpace(kind)) {
+		case SinkHTTP:
+			if config.Url == "" {
+				return sinks, fmt.Errorf("%s 存储目标未配置 Url", SinkHTTP)
+			}
+			sink = NewHTTPSink(config.Url, 5*time.Second)
+		case SinkDB:
+			if o.db == nil {
+				return sinks, fmt.Errorf("%s 存储目标未提供数据库连接，请使用 audit.WithDB", SinkDB)
+			}
+			sink = NewDBSink(o.db)
+		case SinkFile:
+			s, err := NewFileSink(config.File)
+			if err != nil {
+				return sinks, err
+			}
+			sink = s
+		case SinkKafka:
+			s, err := NewKafkaSink(config.Kafka)
+			if err != nil {
+				return sinks, err
+			}
+			sink = s
+		default:
+			return sinks, fmt.Errorf("不支持的审计日志存储目标: %s", kind)
+		}
+		sinks = append(sinks, sink)
 	}
+	sinks = append(sinks, o.sinks...)
 
-	// 启动定时刷新
-	go auditLogger.flushLoop()
+	seen := make(map[string]bool, len(sinks))
+	for _, sink := range sinks {
+		if seen[sink.Name()] {
+			return sinks, fmt.Errorf("审计日志存储目标重复: %s", sink.Name())
+		}
+		seen[sink.Name()] = true
+	}
+	return sinks, nil
+}
 
-	logx.Infof("审计日志初始化完成 [url=%s, buffer=%d]", config.Url, config.Buffer)
+// closeSinks 关闭存储目标
+func closeSinks(sinks []Sink) {
+	for _, sink := range sinks {
+		if err := sink.Close(); err != nil {
+			logx.Errorf("关闭审计日志存储目标 %s 失败: %v", sink.Name(), err)
+		}
+	}
 }
 
 // Log 记录审计日志
@@ -59,6 +182,7 @@ func Log(ctx context.Context, log AuditLog) {
 	}
 
 	// 补充基础信息
+	log.ID = uuid.NewString()
 	log.Timestamp = time.Now()
 	log.ServiceName = auditLogger.serviceName
 
@@ -76,92 +200,70 @@ func LogWithDuration(ctx context.Context, log AuditLog, start time.Time) {
 	Log(ctx, log)
 }
 
-// add 添加审计日志到缓冲区
+// add 写入各存储目标的落盘队列
 func (a *AuditLogger) add(log AuditLog) {
-	a.mu.Lock()
-	a.buffer = append(a.buffer, log)
-	shouldFlush := len(a.buffer) >= a.bufferSize
-	a.mu.Unlock()
-
-	if shouldFlush {
-		a.flush()
-	}
-}
-
-// flush 发送审计日志
-func (a *AuditLogger) flush() {
-	a.mu.Lock()
-	if len(a.buffer) == 0 {
-		a.mu.Unlock()
-		return
-	}
-
-	logs := make([]AuditLog, len(a.buffer))
-	copy(logs, a.buffer)
-	a.buffer = a.buffer[:0]
-	a.mu.Unlock()
-
-	// 异步发送
-	go a.send(logs)
-}
-
-// send 发送到审计服务
-func (a *AuditLogger) send(logs []AuditLog) {
-	data, err := json.Marshal(map[string]interface{}{
-		"audit_logs": logs,
-	})
+	data, err := json.Marshal(log)
 	if err != nil {
-		logx.Errorf("marshal audit logs failed: %v", err)
+		logx.Errorf("marshal audit log failed: %v", err)
 		return
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "POST", a.url, bytes.NewReader(data))
-	if err != nil {
-		logx.Errorf("create audit request failed: %v", err)
-		return
+	for _, out := range a.outputs {
+		if err := out.queue.Enqueue(data); err != nil {
+			logx.Errorf("enqueue audit log failed [sink=%s, action=%s, resource=%s]: %v",
+				out.sink.Name(), log.Action, log.Resource, err)
+		}
 	}
-	req.Header.Set("Content-Type", "application/json")
+}
 
-	resp, err := a.client.Do(req)
-	if err != nil {
-		logx.Errorf("send audit logs failed: %v", err)
+// Close 关闭审计日志，等待已记录的审计日志写入完成，重复调用时只关闭一次
+// 超时未写入的审计日志保留在落盘目录，下次启动时继续写入
+// db 存储目标依赖数据库连接，需在关闭数据库之前调用
+func Close() {
+	if auditLogger == nil {
 		return
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		logx.Errorf("audit server returned status: %d", resp.StatusCode)
-	}
+	auditLogger.closeOnce.Do(auditLogger.close)
 }
 
-// flushLoop 定时刷新
-func (a *AuditLogger) flushLoop() {
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			a.flush()
-		case <-a.closeChan:
-			a.flush()
-			return
-		}
-	}
-}
+// close 并发关闭所有存储目标的落盘队列和存储目标
+// 各队列同时等待同一截止时间，故障目标的重试不会占用其他目标的等待时间
+func (a *AuditLogger) close() {
+	ctx, cancel := context.WithTimeout(context.Background(), a.drainTimeout)
+	defer cancel()
 
-// Close 关闭审计日志
-func Close() {
-	if auditLogger != nil {
-		close(auditLogger.closeChan)
-		time.Sleep(100 * time.Millisecond) // 等待最后一次flush
+	var wg sync.WaitGroup
+	for _, out := range a.outputs {
+		wg.Add(1)
+		go func(out *output) {
+			defer wg.Done()
+			if err := out.queue.Close(ctx); err != nil {
+				logx.Errorf("关闭审计日志: %v", err)
+			}
+			if err := out.sink.Close(); err != nil {
+				logx.Errorf("关闭审计日志存储目标 %s 失败: %v", out.sink.Name(), err)
+			}
+		}(out)
 	}
+	wg.Wait()
 }
 
 // IsEnabled 是否启用审计日志
 func IsEnabled() bool {
 	return auditLogger != nil
 }
+
+// Stats 返回审计日志投递统计，多个存储目标时累加
+func Stats() delivery.Stats {
+	var stats delivery.Stats
+	if auditLogger == nil {
+		return stats
+	}
+	for _, out := range auditLogger.outputs {
+		s := out.queue.Stats()
+		stats.Queued += s.Queued
+		stats.Sent += s.Sent
+		stats.Dropped += s.Dropped
+		stats.Retries += s.Retries
+	}
+	return stats
+}
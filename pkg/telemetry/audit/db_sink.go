@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AuditRecord 审计日志表记录，快照和扩展字段按 JSON 文本存储
+type AuditRecord struct {
+	Id          int64     `json:"id" gorm:"column:id;primaryKey"`
+	EventId     string    `json:"eventId" gorm:"column:event_id;type:varchar(36);not null;uniqueIndex:uk_audit_logs_event_id"`
+	OccurredAt  time.Time `json:"occurredAt" gorm:"column:occurred_at;not null;index:idx_audit_logs_occurred_at"`
+	ServiceName string    `json:"serviceName" gorm:"column:service_name;type:varchar(64);not null"`
+	TenantId    string    `json:"tenantId" gorm:"column:tenant_id;type:varchar(64);index:idx_audit_logs_resource,priority:1"`
+	Action      string    `json:"action" gorm:"column:action;type:varchar(20);not null"`
+	Resource    string    `json:"resource" gorm:"column:resource;type:varchar(64);not null;index:idx_audit_logs_resource,priority:2"`
+	UserId      string    `json:"userId" gorm:"column:user_id;type:varchar(64);index:idx_audit_logs_user"`
+	Username    string    `json:"username" gorm:"column:username;type:varchar(128)"`
+	Ip          string    `json:"ip" gorm:"column:ip;type:varchar(64)"`
+	Method      string    `json:"method" gorm:"column:method;type:varchar(16)"`
+	Path        string    `json:"path" gorm:"column:path;type:varchar(255)"`
+	TraceId     string    `json:"traceId" gorm:"column:trace_id;type:varchar(64)"`
+	BeforeData  string    `json:"beforeData" gorm:"column:before_data;type:text"`
+	AfterData   string    `json:"afterData" gorm:"column:after_data;type:text"`
+	Success     bool      `json:"success" gorm:"column:success;type:boolean;not null"`
+	ErrorMsg    string    `json:"errorMsg" gorm:"column:error_msg;type:text"`
+	Duration    int64     `json:"duration" gorm:"column:duration;not null;default:0"`
+	Extra       string    `json:"extra" gorm:"column:extra;type:text"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:created_at;autoCreateTime"`
+}
+
+// TableName 指定表名
+func (AuditRecord) TableName() string {
+	return "audit_logs"
+}
+
+// dbSink 写入主库 audit_logs 表
+type dbSink struct {
+	db *gorm.DB
+}
+
+// NewDBSink 创建数据库存储目标，按事件ID去重
+func NewDBSink(db *gorm.DB) Sink {
+	return &dbSink{db: db}
+}
+
+// Name 存储目标名称
+func (s *dbSink) Name() string {
+	return SinkDB
+}
+
+// Write 批量写入审计日志，已存在的事件忽略
+func (s *dbSink) Write(ctx context.Context, logs []AuditLog) error {
+	records := make([]*AuditRecord, 0, len(logs))
+	for _, log := range logs {
+		records = append(records, toRecord(log))
+	}
+
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "event_id"}}, DoNothing: true}).
+		Create(&records).Error
+	if err != nil {
+		return fmt.Errorf("写入审计日志表失败: %w", err)
+	}
+	return nil
+}
+
+// Close 释放资源，数据库连接由调用方管理
+func (s *dbSink) Close() error {
+	return nil
+}
+
+// toRecord 转换为审计日志表记录
+func toRecord(log AuditLog) *AuditRecord {
+	return &AuditRecord{
+		EventId:     log.ID,
+		OccurredAt:  log.Timestamp,
+		ServiceName: log.ServiceName,
+		TenantId:    log.TenantID,
+		Action:      log.Action,
+		Resource:    log.Resource,
+		UserId:      log.UserID,
+		Username:    log.Username,
+		Ip:          log.IP,
+		Method:      log.Method,
+		Path:        log.Path,
+		TraceId:     log.TraceID,
+		BeforeData:  marshalText(log.Before),
+		AfterData:   marshalText(log.After),
+		Success:     log.Success,
+		ErrorMsg:    log.Error,
+		Duration:    log.Duration,
+		Extra:       marshalText(log.Extra),
+	}
+}
+
+// marshalText 序列化为 JSON 文本，空值返回空字符串
+func marshalText(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if m, ok := v.(map[string]interface{}); ok && len(m) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"reflect"
+	"sort"
+)
+
+// 字段变更类型
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Change 快照字段变更，Path 为以 . 分隔的字段路径
+type Change struct {
+	Path   string      `json:"path"`
+	Op     string      `json:"op"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Diff 比较变更前后的 JSON 快照，按字段路径排序返回变更
+// 对象逐字段递归比较，数组和标量整体比较
+func Diff(before, after interface{}) []Change {
+	changes := make([]Change, 0)
+	diffValue("", before, after, &changes)
+	return changes
+}
+
+// diffValue 比较 path 处的取值
+func diffValue(path string, before, after interface{}, changes *[]Change) {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		diffMap(path, beforeMap, afterMap, changes)
+		return
+	}
+
+	switch {
+	case before == nil && after == nil:
+	case before == nil:
+		*changes = append(*changes, Change{Path: path, Op: ChangeAdded, After: after})
+	case after == nil:
+		*changes = append(*changes, Change{Path: path, Op: ChangeRemoved, Before: before})
+	case !reflect.DeepEqual(before, after):
+		*changes = append(*changes, Change{Path: path, Op: ChangeChanged, Before: before, After: after})
+	}
+}
+
+// diffMap 按字段名顺序逐字段比较
+func diffMap(path string, before, after map[string]interface{}, changes *[]Change) {
+	keys := make([]string, 0, len(before)+len(after))
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		child := k
+		if path != "" {
+			child = path + "." + k
+		}
+		diffValue(child, before[k], after[k], changes)
+	}
+}
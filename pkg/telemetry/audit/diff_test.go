@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestDiff 测试快照字段级比较
+func TestDiff(t *testing.T) {
+	parse := func(s string) interface{} {
+		if s == "" {
+			return nil
+		}
+		var v interface{}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			t.Fatalf("解析快照失败: %v", err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []Change
+	}{
+		{
+			name:   "字段变更",
+			before: `{"name":"财务","color":"#fff","meta":{"owner":1,"tags":[1,2]}}`,
+			after:  `{"name":"财务部","desc":"部门","meta":{"owner":1,"tags":[1,3]}}`,
+			want: []Change{
+				{Path: "color", Op: ChangeRemoved, Before: "#fff"},
+				{Path: "desc", Op: ChangeAdded, After: "部门"},
+				{Path: "meta.tags", Op: ChangeChanged, Before: []interface{}{1.0, 2.0}, After: []interface{}{1.0, 3.0}},
+				{Path: "name", Op: ChangeChanged, Before: "财务", After: "财务部"},
+			},
+		},
+		{
+			name:  "创建",
+			after: `{"name":"财务"}`,
+			want:  []Change{{Op: ChangeAdded, After: map[string]interface{}{"name": "财务"}}},
+		},
+		{
+			name:   "无变化",
+			before: `{"name":"财务"}`,
+			after:  `{"name":"财务"}`,
+			want:   []Change{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff(parse(tt.before), parse(tt.after))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("期望 %+v, 实际 %+v", tt.want, got)
+			}
+		})
+	}
+}
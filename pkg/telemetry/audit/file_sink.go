@@ -0,0 +1,154 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileSinkConfig 文件存储目标配置
+type FileSinkConfig struct {
+	Path       string // 日志文件路径，默认 logs/audit.log
+	MaxSize    int    // 单个文件最大大小(MB)，默认 100，超过后轮转
+	MaxBackups int    // 保留的历史文件数，默认 10，0 表示使用默认值
+}
+
+// fileSink 以 JSON Lines 格式写入本地文件，按大小轮转
+type fileSink struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink 创建文件存储目标
+func NewFileSink(cfg FileSinkConfig) (Sink, error) {
+	if cfg.Path == "" {
+		cfg.Path = filepath.Join("logs", "audit.log")
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = 100
+	}
+	if cfg.MaxBackups <= 0 {
+		cfg.MaxBackups = 10
+	}
+
+	s := &fileSink{
+		path:       cfg.Path,
+		maxBytes:   int64(cfg.MaxSize) << 20,
+		maxBackups: cfg.MaxBackups,
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("创建审计日志目录失败: %w", err)
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Name 存储目标名称
+func (s *fileSink) Name() string {
+	return SinkFile
+}
+
+// Write 追加写入一批审计日志，写满后轮转
+func (s *fileSink) Write(ctx context.Context, logs []AuditLog) error {
+	var buf []byte
+	for _, log := range logs {
+		data, err := json.Marshal(log)
+		if err != nil {
+			return fmt.Errorf("序列化审计日志失败: %w", err)
+		}
+		buf = append(buf, data...)
+		buf = append(buf, '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+	if s.size > 0 && s.size+int64(len(buf)) > s.maxBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(buf)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("写入审计日志文件失败: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("同步审计日志文件失败: %w", err)
+	}
+	return nil
+}
+
+// Close 关闭文件
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// open 以追加方式打开日志文件
+func (s *fileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("打开审计日志文件失败: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("读取审计日志文件失败: %w", err)
+	}
+	s.file = f
+	s.size = info.Size()
+	return nil
+}
+
+// rotate 将当前文件重命名为带时间戳的历史文件，并清理超出数量的历史文件
+func (s *fileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("关闭审计日志文件失败: %w", err)
+	}
+	s.file = nil
+
+	backup := s.path + "." + time.Now().Format("20060102-150405.000000")
+	if err := os.Rename(s.path, backup); err != nil {
+		return fmt.Errorf("轮转审计日志文件失败: %w", err)
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	backups, err := filepath.Glob(s.path + ".*")
+	if err != nil {
+		return nil
+	}
+	sort.Strings(backups)
+	for len(backups) > s.maxBackups {
+		os.Remove(backups[0])
+		backups = backups[1:]
+	}
+	return nil
+}
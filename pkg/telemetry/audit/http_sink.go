@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"idrm/pkg/telemetry/delivery"
+)
+
+// httpSink 以 JSON 批量 POST 到审计服务
+type httpSink struct {
+	send delivery.Sender
+}
+
+// NewHTTPSink 创建 HTTP 存储目标，请求体为 {"audit_logs": [...]}
+func NewHTTPSink(url string, timeout time.Duration) Sink {
+	client := &http.Client{
+		Timeout: timeout,
+	}
+	return &httpSink{send: delivery.NewHTTPSender(client, url, "audit_logs")}
+}
+
+// Name 存储目标名称
+func (s *httpSink) Name() string {
+	return SinkHTTP
+}
+
+// Write 发送一批审计日志
+func (s *httpSink) Write(ctx context.Context, logs []AuditLog) error {
+	records := make([][]byte, 0, len(logs))
+	for _, log := range logs {
+		data, err := json.Marshal(log)
+		if err != nil {
+			return delivery.Permanent(err)
+		}
+		records = append(records, data)
+	}
+	return s.send(ctx, records)
+}
+
+// Close 释放资源
+func (s *httpSink) Close() error {
+	return nil
+}
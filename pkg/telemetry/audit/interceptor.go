@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"idrm/pkg/operator"
+	"idrm/pkg/tenant"
+)
+
+// Operation 被审计的操作
+type Operation struct {
+	Action   string
+	Resource string
+
+	// Before 操作执行前采集快照（可选）
+	Before func(ctx context.Context) interface{}
+
+	// After 操作成功后采集快照（可选）
+	After func(ctx context.Context) interface{}
+
+	// Extra 扩展字段，如资源ID
+	Extra map[string]interface{}
+}
+
+// Intercept 执行操作并记录审计日志
+// 操作人、客户端IP、请求方法和路径、租户和变更原因取自上下文，执行时长只统计操作本身；
+// 审计日志在 fn 返回后写入，fn 需自行完成事务提交；未启用审计日志时直接执行操作，不采集快照
+func Intercept(ctx context.Context, op Operation, fn func(ctx context.Context) error) error {
+	if !IsEnabled() {
+		return fn(ctx)
+	}
+	log, err := Capture(ctx, op, fn)
+	Log(ctx, log)
+	return err
+}
+
+// Capture 执行操作并生成审计日志，但不写入
+// 用于事务内的操作：由调用方在事务提交后调用 Log 写入，回滚时改为失败记录
+func Capture(ctx context.Context, op Operation, fn func(ctx context.Context) error) (AuditLog, error) {
+	log := AuditLog{
+		Action:   op.Action,
+		Resource: op.Resource,
+		IP:       operator.ClientIP(ctx),
+	}
+	log.TenantID, _ = tenant.FromContext(ctx)
+	log.Method, log.Path = operator.Request(ctx)
+	if userID, ok := operator.FromContext(ctx); ok {
+		log.UserID = strconv.FormatInt(userID, 10)
+	}
+	if op.Before != nil {
+		log.Before = op.Before(ctx)
+	}
+
+	start := time.Now()
+	err := fn(ctx)
+	log.Duration = time.Since(start).Milliseconds()
+
+	log.Success = err == nil
+	if err != nil {
+		log.Error = err.Error()
+	} else if op.After != nil {
+		log.After = op.After(ctx)
+	}
+
+	log.Extra = make(map[string]interface{}, len(op.Extra)+1)
+	for k, v := range op.Extra {
+		log.Extra[k] = v
+	}
+	if reason := operator.Reason(ctx); reason != "" {
+		log.Extra["reason"] = reason
+	}
+	return log, err
+}
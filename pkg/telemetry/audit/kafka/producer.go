@@ -0,0 +1,64 @@
+// Package kafka 基于 kafka-go 的审计日志 Kafka 生产者
+// 导入该包即向审计包注册生产者，启用 kafka 存储目标的服务以空白导入方式引入
+package kafka
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"idrm/pkg/telemetry/audit"
+)
+
+const (
+	// batchTimeout 客户端攒批的等待时间，审计日志由落盘队列整批投递，无需在客户端等待
+	batchTimeout = 10 * time.Millisecond
+
+	// writeTimeout 单次写入超时，一批消息共用
+	writeTimeout = 10 * time.Second
+)
+
+func init() {
+	audit.RegisterKafkaProducer(NewProducer)
+}
+
+// Producer Kafka 生产者，同一消息键写入同一分区
+type Producer struct {
+	writer *kafkago.Writer
+}
+
+// NewProducer 创建 Kafka 生产者，首次发送时才建立连接
+func NewProducer(brokers []string, topic string) (audit.KafkaProducer, error) {
+	if len(brokers) == 0 {
+		return nil, errors.New("未配置 Kafka Brokers")
+	}
+	return &Producer{
+		writer: &kafkago.Writer{
+			Addr:         kafkago.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafkago.Hash{},
+			RequiredAcks: kafkago.RequireAll,
+			BatchTimeout: batchTimeout,
+			WriteTimeout: writeTimeout,
+		},
+	}, nil
+}
+
+// PushBatch 一次写入整批消息，broker 全部确认后返回
+func (p *Producer) PushBatch(ctx context.Context, messages []audit.KafkaMessage) error {
+	batch := make([]kafkago.Message, len(messages))
+	for i, message := range messages {
+		batch[i] = kafkago.Message{
+			Key:   []byte(message.Key),
+			Value: []byte(message.Value),
+		}
+	}
+	return p.writer.WriteMessages(ctx, batch...)
+}
+
+// Close 关闭生产者，等待未完成的发送
+func (p *Producer) Close() error {
+	return p.writer.Close()
+}
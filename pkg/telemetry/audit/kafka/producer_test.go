@@ -0,0 +1,46 @@
+package kafka
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"idrm/pkg/telemetry/audit"
+)
+
+// TestRegister 测试导入后 kafka 存储目标可用
+func TestRegister(t *testing.T) {
+	sink, err := audit.NewKafkaSink(audit.KafkaSinkConfig{Brokers: []string{"127.0.0.1:9092"}})
+	if err != nil {
+		t.Fatalf("创建 kafka 存储目标失败: %v", err)
+	}
+	if sink.Name() != audit.SinkKafka {
+		t.Errorf("期望名称=%s, 实际=%s", audit.SinkKafka, sink.Name())
+	}
+	if err := sink.Close(); err != nil {
+		t.Errorf("关闭失败: %v", err)
+	}
+}
+
+// TestProducer_Unreachable 测试 broker 不可用时发送返回错误，由落盘队列重试
+func TestProducer_Unreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	producer, err := NewProducer([]string{addr}, "audit-logs")
+	if err != nil {
+		t.Fatalf("创建生产者失败: %v", err)
+	}
+	defer producer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := producer.PushBatch(ctx, []audit.KafkaMessage{{Key: "id", Value: "{}"}}); err == nil {
+		t.Error("broker 不可用时期望返回错误")
+	}
+}
@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"idrm/pkg/telemetry/delivery"
+)
+
+// KafkaSinkConfig Kafka 存储目标配置
+type KafkaSinkConfig struct {
+	Brokers []string
+	Topic   string // 默认 audit-logs
+}
+
+// KafkaMessage 待发送的 Kafka 消息
+type KafkaMessage struct {
+	Key   string
+	Value string
+}
+
+// KafkaProducer Kafka 生产者
+type KafkaProducer interface {
+	// PushBatch 同步发送一批消息，全部确认后返回
+	PushBatch(ctx context.Context, messages []KafkaMessage) error
+	Close() error
+}
+
+var (
+	kafkaProducerFactory func(brokers []string, topic string) (KafkaProducer, error)
+
+	// ErrNoKafkaProducer 未注册 Kafka 生产者
+	ErrNoKafkaProducer = errors.New("未注册 Kafka 生产者，请先调用 audit.RegisterKafkaProducer")
+)
+
+// RegisterKafkaProducer 注册 Kafka 生产者工厂函数
+// 审计包不直接依赖 Kafka 客户端，由使用 kafka 存储目标的服务按所用客户端注册
+func RegisterKafkaProducer(fn func(brokers []string, topic string) (KafkaProducer, error)) {
+	kafkaProducerFactory = fn
+}
+
+// kafkaSink 按批发送到 Kafka，以事件ID作为消息键
+type kafkaSink struct {
+	producer KafkaProducer
+}
+
+// NewKafkaSink 使用已注册的生产者工厂创建 Kafka 存储目标
+func NewKafkaSink(cfg KafkaSinkConfig) (Sink, error) {
+	if kafkaProducerFactory == nil {
+		return nil, ErrNoKafkaProducer
+	}
+	if len(cfg.Brokers) == 0 {
+		return nil, errors.New("未配置 Kafka Brokers")
+	}
+	if cfg.Topic == "" {
+		cfg.Topic = "audit-logs"
+	}
+
+	producer, err := kafkaProducerFactory(cfg.Brokers, cfg.Topic)
+	if err != nil {
+		return nil, fmt.Errorf("创建 Kafka 生产者失败: %w", err)
+	}
+	return &kafkaSink{producer: producer}, nil
+}
+
+// Name 存储目标名称
+func (s *kafkaSink) Name() string {
+	return SinkKafka
+}
+
+// Write 一次发送整批审计日志，失败时整批重试，消费端按事件ID去重
+func (s *kafkaSink) Write(ctx context.Context, logs []AuditLog) error {
+	messages := make([]KafkaMessage, 0, len(logs))
+	for _, log := range logs {
+		data, err := json.Marshal(log)
+		if err != nil {
+			return delivery.Permanent(fmt.Errorf("序列化审计日志失败: %w", err))
+		}
+		messages = append(messages, KafkaMessage{Key: log.ID, Value: string(data)})
+	}
+	if err := s.producer.PushBatch(ctx, messages); err != nil {
+		return fmt.Errorf("发送审计日志到 Kafka 失败: %w", err)
+	}
+	return nil
+}
+
+// Close 关闭生产者
+func (s *kafkaSink) Close() error {
+	return s.producer.Close()
+}
@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"idrm/pkg/telemetry/delivery"
+
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Sink 审计日志存储目标
+type Sink interface {
+	// Name 存储目标名称，用于区分落盘目录和投递指标
+	Name() string
+
+	// Write 写入一批审计日志，返回错误时整批重试，返回 delivery.Permanent 包装的错误时丢弃该批日志
+	// 同一条日志可能重复写入，存储目标可按 AuditLog.ID 去重
+	Write(ctx context.Context, logs []AuditLog) error
+
+	// Close 释放资源
+	Close() error
+}
+
+// sinkSender 将落盘队列中的记录解析为审计日志后写入存储目标
+func sinkSender(sink Sink) delivery.Sender {
+	return func(ctx context.Context, records [][]byte) error {
+		logs := make([]AuditLog, 0, len(records))
+		for _, record := range records {
+			var log AuditLog
+			if err := json.Unmarshal(record, &log); err != nil {
+				logx.Errorf("[audit_%s] 丢弃无法解析的审计日志: %v", sink.Name(), err)
+				continue
+			}
+			if log.ID == "" {
+				log.ID = uuid.NewString()
+			}
+			logs = append(logs, log)
+		}
+		if len(logs) == 0 {
+			return nil
+		}
+		return sink.Write(ctx, logs)
+	}
+}
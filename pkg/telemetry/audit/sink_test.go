@@ -0,0 +1,229 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// memorySink 记录写入结果的存储目标，fail 为 true 时写入失败
+type memorySink struct {
+	name string
+	fail bool
+
+	mu     sync.Mutex
+	logs   []AuditLog
+	closed int
+}
+
+func (s *memorySink) Name() string { return s.name }
+
+func (s *memorySink) Write(ctx context.Context, logs []AuditLog) error {
+	if s.fail {
+		return errors.New("存储不可用")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs = append(s.logs, logs...)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed++
+	return nil
+}
+
+func (s *memorySink) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.logs)
+}
+
+// TestInit_Fanout 测试多个存储目标独立投递，某个目标故障不影响其他目标
+func TestInit_Fanout(t *testing.T) {
+	good := &memorySink{name: "good"}
+	bad := &memorySink{name: "bad", fail: true}
+	err := Init(AuditConfig{Enabled: true, Buffer: 1, SpoolDir: t.TempDir(), DrainTimeout: 1}, "test",
+		WithSink(good), WithSink(bad))
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	defer func() { auditLogger = nil }()
+
+	Log(context.Background(), AuditLog{Action: ActionCreate, Resource: ResourceTag, Success: true})
+
+	deadline := time.Now().Add(3 * time.Second)
+	for good.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if good.count() != 1 {
+		t.Fatalf("期望正常目标写入1条, 实际=%d", good.count())
+	}
+	if good.logs[0].ID == "" {
+		t.Error("审计日志应带有事件ID")
+	}
+
+	// 故障目标的日志保留在落盘队列中
+	if stats := Stats(); stats.Sent != 1 || stats.Queued != 1 {
+		t.Errorf("统计不符合预期: %+v", stats)
+	}
+
+	// 停止流程中先单独关闭审计日志，关闭遥测时再次调用不会重复关闭存储目标
+	Close()
+	Close()
+	if good.closed != 1 {
+		t.Errorf("期望存储目标关闭1次, 实际=%d", good.closed)
+	}
+}
+
+// TestClose_Concurrent 测试关闭时故障目标的重试不占用其他目标的等待时间
+func TestClose_Concurrent(t *testing.T) {
+	bad := &memorySink{name: "bad", fail: true}
+	good := &memorySink{name: "good"}
+	// 未攒满一批且未到刷新间隔，日志在关闭时才发送
+	err := Init(AuditConfig{Enabled: true, Buffer: 10, SpoolDir: t.TempDir(), DrainTimeout: 1}, "test",
+		WithSink(bad), WithSink(good))
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	defer func() { auditLogger = nil }()
+
+	Log(context.Background(), AuditLog{Action: ActionCreate, Resource: ResourceTag, Success: true})
+	start := time.Now()
+	Close()
+
+	if good.count() != 1 {
+		t.Errorf("期望正常目标在关闭时写入1条, 实际=%d", good.count())
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("关闭耗时超过等待时间: %v", elapsed)
+	}
+}
+
+// TestInit_InvalidSink 测试存储目标配置错误
+func TestInit_InvalidSink(t *testing.T) {
+	tests := []struct {
+		name  string
+		sinks []string
+	}{
+		{"未知目标", []string{"s3"}},
+		{"http 未配置地址", []string{SinkHTTP}},
+		{"db 未提供连接", []string{SinkDB}},
+		{"kafka 未注册生产者", []string{SinkKafka}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Init(AuditConfig{Enabled: true, Sinks: tt.sinks, SpoolDir: t.TempDir()}, "test")
+			if err == nil {
+				t.Error("期望初始化失败")
+			}
+		})
+	}
+}
+
+// TestDBSink_Dedup 测试数据库存储目标按事件ID去重
+func TestDBSink_Dedup(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法创建测试数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&AuditRecord{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	sink := NewDBSink(db)
+	logs := []AuditLog{
+		{ID: "e1", Action: ActionUpdate, Resource: ResourceTag, TenantID: "t1", Success: true,
+			Before: map[string]interface{}{"name": "旧"}, After: map[string]interface{}{"name": "新"}},
+		{ID: "e2", Action: ActionDelete, Resource: ResourceTag, Error: "标签不存在"},
+	}
+	for i := 0; i < 2; i++ {
+		if err := sink.Write(context.Background(), logs); err != nil {
+			t.Fatalf("写入失败: %v", err)
+		}
+	}
+
+	var records []AuditRecord
+	db.Order("id").Find(&records)
+	if len(records) != 2 {
+		t.Fatalf("期望去重后2条, 实际=%d", len(records))
+	}
+	if records[0].BeforeData != `{"name":"旧"}` || records[0].TenantId != "t1" || !records[0].Success {
+		t.Errorf("记录不符合预期: %+v", records[0])
+	}
+	if records[1].AfterData != "" || records[1].ErrorMsg != "标签不存在" {
+		t.Errorf("记录不符合预期: %+v", records[1])
+	}
+}
+
+// batchProducer 记录每次批量发送的 Kafka 生产者
+type batchProducer struct {
+	batches [][]KafkaMessage
+}
+
+func (p *batchProducer) PushBatch(ctx context.Context, messages []KafkaMessage) error {
+	p.batches = append(p.batches, messages)
+	return nil
+}
+
+func (p *batchProducer) Close() error { return nil }
+
+// TestKafkaSink_Batch 测试 Kafka 存储目标整批发送，而不是逐条等待确认
+func TestKafkaSink_Batch(t *testing.T) {
+	producer := &batchProducer{}
+	RegisterKafkaProducer(func([]string, string) (KafkaProducer, error) { return producer, nil })
+	defer RegisterKafkaProducer(nil)
+
+	sink, err := NewKafkaSink(KafkaSinkConfig{Brokers: []string{"127.0.0.1:9092"}})
+	if err != nil {
+		t.Fatalf("创建 kafka 存储目标失败: %v", err)
+	}
+	logs := []AuditLog{{ID: "e1", Action: ActionCreate}, {ID: "e2", Action: ActionDelete}}
+	if err := sink.Write(context.Background(), logs); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	if len(producer.batches) != 1 || len(producer.batches[0]) != 2 {
+		t.Fatalf("期望发送1批2条, 实际=%v", producer.batches)
+	}
+	if producer.batches[0][1].Key != "e2" || !strings.Contains(producer.batches[0][1].Value, `"e2"`) {
+		t.Errorf("消息不符合预期: %+v", producer.batches[0][1])
+	}
+}
+
+// TestFileSink_Rotate 测试文件存储目标按大小轮转并清理历史文件
+func TestFileSink_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(FileSinkConfig{Path: path, MaxSize: 1, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("创建文件存储目标失败: %v", err)
+	}
+	defer sink.Close()
+
+	// 每批约 600KB，写满 1MB 后轮转
+	big := AuditLog{Action: ActionUpdate, Extra: map[string]interface{}{"data": strings.Repeat("x", 600<<10)}}
+	for i := 0; i < 5; i++ {
+		if err := sink.Write(context.Background(), []AuditLog{big}); err != nil {
+			t.Fatalf("写入失败: %v", err)
+		}
+	}
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 2 {
+		t.Errorf("期望保留2个历史文件, 实际=%v", backups)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() == 0 || info.Size() > 1<<20 {
+		t.Errorf("当前文件大小不符合预期: %v, %v", info, err)
+	}
+}
@@ -5,6 +5,7 @@ import "time"
 // AuditLog 审计日志结构
 type AuditLog struct {
 	// 基础信息
+	ID          string    `json:"id"` // 事件ID，重复投递时用于去重
 	Timestamp   time.Time `json:"timestamp"`
 	ServiceName string    `json:"service_name"`
 
@@ -16,6 +17,7 @@ type AuditLog struct {
 	UserID   string `json:"user_id,omitempty"`
 	Username string `json:"username,omitempty"`
 	IP       string `json:"ip,omitempty"`
+	TenantID string `json:"tenant_id,omitempty"`
 
 	// 请求信息
 	Method  string `json:"method,omitempty"`   // HTTP Method
@@ -40,8 +42,26 @@ type AuditConfig struct {
 	Enabled bool
 	Url     string
 	Buffer  int
+
+	SpoolDir     string // 落盘目录，每个存储目标使用独立的子目录
+	SpoolMaxSize int    // 落盘上限(MB)
+	MaxBackoff   int    // 最大重试间隔(秒)
+	DrainTimeout int    // 关闭时等待发送完成的超时时间(秒)
+
+	// Sinks 存储目标：http/db/file/kafka，为空时为 http；配置多个时同时写入
+	Sinks []string
+	File  FileSinkConfig
+	Kafka KafkaSinkConfig
 }
 
+// 存储目标
+const (
+	SinkHTTP  = "http"
+	SinkDB    = "db"
+	SinkFile  = "file"
+	SinkKafka = "kafka"
+)
+
 // 常用操作类型
 const (
 	ActionCreate = "create"
@@ -52,6 +72,10 @@ const (
 	ActionLogout = "logout"
 	ActionExport = "export"
 	ActionImport = "import"
+
+	ActionStatus   = "status"   // 启用/禁用
+	ActionAssign   = "assign"   // 建立关联
+	ActionUnassign = "unassign" // 解除关联
 )
 
 // 常用资源类型
@@ -60,4 +84,7 @@ const (
 	ResourceUser     = "user"
 	ResourceRole     = "role"
 	ResourceConfig   = "config"
+
+	ResourceTag            = "tag"             // 标签
+	ResourceTagAssociation = "tag_association" // 资源与标签的关联
 )
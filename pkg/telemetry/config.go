@@ -15,6 +15,9 @@ type Config struct {
 
 	// 审计日志配置
 	Audit AuditConfig
+
+	// 指标配置
+	Metrics MetricsConfig
 }
 
 // LogConfig 日志配置
@@ -29,6 +32,16 @@ type LogConfig struct {
 	RemoteUrl     string `json:",optional"`    // 远程日志接收地址
 	RemoteBatch   int    `json:",default=100"` // 批量发送数量
 	RemoteTimeout int    `json:",default=5"`   // 超时时间(秒)
+
+	// 远程日志可靠投递
+	RemoteSpoolDir     string `json:",default=spool/log"` // 落盘目录
+	RemoteSpoolMaxSize int    `json:",default=256"`       // 落盘上限(MB)，超过后丢弃新日志
+	RemoteDrainTimeout int    `json:",default=5"`         // 关闭时等待发送完成的超时时间(秒)
+
+	// 远程日志协议：json 为自定义接口，otlp 为 OTLP/HTTP 日志接口，loki 为 Loki push 接口
+	RemoteProtocol     string `json:",default=json,options=json|otlp|loki"`
+	RemoteCompress     bool   `json:",default=false"` // 使用 gzip 压缩请求体
+	RemoteMaxBatchSize int    `json:",default=1024"`  // 单批请求最大大小(KB)，限制发送时占用的内存
 }
 
 // TraceConfig 链路追踪配置
@@ -36,7 +49,10 @@ type TraceConfig struct {
 	Enabled  bool    `json:",default=true"`
 	Endpoint string  `json:",default=http://localhost:4318"` // OTLP endpoint
 	Sampler  float64 `json:",default=1.0"`                   // 采样率 0.0-1.0
-	Batcher  string  `json:",default=otlp"`                  // jaeger/zipkin/otlp
+	// otlp 按 Endpoint 选择协议：带 http(s):// 前缀时为 OTLP/HTTP，否则为 OTLP/gRPC；
+	// 也可指定 otlpgrpc/otlphttp/jaeger/zipkin，本地调试使用 stdout/file（Endpoint 为文件路径），测试使用 memory
+	Batcher string            `json:",default=otlp,options=otlp|otlpgrpc|otlphttp|jaeger|zipkin|stdout|file|memory"`
+	Headers map[string]string `json:",optional"` // OTLP 请求头，如鉴权信息
 }
 
 // AuditConfig 审计日志配置
@@ -44,4 +60,35 @@ type AuditConfig struct {
 	Enabled bool   `json:",default=false"`
 	Url     string `json:",optional"`    // 审计日志上报地址
 	Buffer  int    `json:",default=100"` // 缓冲区大小
+
+	// 可靠投递：审计日志先落盘再发送，失败重试，关闭时等待发送完成
+	SpoolDir     string `json:",default=spool/audit"` // 落盘目录
+	SpoolMaxSize int    `json:",default=512"`         // 落盘上限(MB)，超过后丢弃新日志
+	MaxBackoff   int    `json:",default=60"`          // 最大重试间隔(秒)
+	DrainTimeout int    `json:",default=10"`          // 关闭时等待发送完成的超时时间(秒)
+
+	// 存储目标：http/db/file/kafka，默认 http；配置多个时同时写入，各目标独立落盘重试
+	Sinks []string         `json:",optional"`
+	File  AuditFileConfig  `json:",optional"`
+	Kafka AuditKafkaConfig `json:",optional"`
+}
+
+// AuditFileConfig 审计日志文件存储配置（JSON Lines，按大小轮转）
+type AuditFileConfig struct {
+	Path       string `json:",default=logs/audit.log"`
+	MaxSize    int    `json:",default=100"` // 单个文件最大大小(MB)
+	MaxBackups int    `json:",default=10"`  // 保留的历史文件数
+}
+
+// AuditKafkaConfig 审计日志 Kafka 存储配置，需由服务注册 Kafka 生产者
+type AuditKafkaConfig struct {
+	Brokers []string `json:",optional"`
+	Topic   string   `json:",default=audit-logs"`
+}
+
+// MetricsConfig 指标配置，指标通过业务服务端口的 Path 暴露给 Prometheus 抓取
+type MetricsConfig struct {
+	Enabled         bool   `json:",default=true"`
+	Path            string `json:",default=/metrics"`
+	CollectInterval int    `json:",default=30"` // 标签领域指标采集间隔(秒)，采集需查询数据库
 }
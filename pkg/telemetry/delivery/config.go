@@ -0,0 +1,65 @@
+package delivery
+
+import (
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Logger 队列自身的日志输出
+type Logger interface {
+	Errorf(format string, v ...interface{})
+	Infof(format string, v ...interface{})
+}
+
+// logxLogger 默认通过 logx 输出
+type logxLogger struct{}
+
+func (logxLogger) Errorf(format string, v ...interface{}) { logx.Errorf(format, v...) }
+
+func (logxLogger) Infof(format string, v ...interface{}) { logx.Infof(format, v...) }
+
+// Config 投递队列配置，未设置的项使用默认值
+type Config struct {
+	Dir             string        // 落盘目录，必填
+	MaxSegmentBytes int64         // 单个段文件最大字节数
+	MaxSpoolBytes   int64         // 落盘总字节数上限，超过后丢弃新记录
+	BatchSize       int           // 单次发送的最大记录数
+	FlushInterval   time.Duration // 定时封存并发送的间隔
+	MinBackoff      time.Duration // 首次重试等待时间
+	MaxBackoff      time.Duration // 最大重试等待时间
+	SendTimeout     time.Duration // 单次发送超时时间
+	Logger          Logger        // 队列自身的日志输出，默认 logx；投递 logx 日志的队列需另行指定，避免自身日志再次入队
+}
+
+// withDefaults 补全默认值
+func (c Config) withDefaults() Config {
+	if c.MaxSegmentBytes <= 0 {
+		c.MaxSegmentBytes = 4 << 20
+	}
+	if c.MaxSpoolBytes <= 0 {
+		c.MaxSpoolBytes = 256 << 20
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = 3 * time.Second
+	}
+	if c.MinBackoff <= 0 {
+		c.MinBackoff = time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 60 * time.Second
+	}
+	if c.MaxBackoff < c.MinBackoff {
+		c.MaxBackoff = c.MinBackoff
+	}
+	if c.SendTimeout <= 0 {
+		c.SendTimeout = 5 * time.Second
+	}
+	if c.Logger == nil {
+		c.Logger = logxLogger{}
+	}
+	return c
+}
@@ -0,0 +1,136 @@
+package delivery
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Encoder 将一批记录编码为请求体
+type Encoder func(records [][]byte) ([]byte, error)
+
+// HTTPOption HTTP 发送选项
+type HTTPOption func(*httpOptions)
+
+type httpOptions struct {
+	contentType string
+	gzip        bool
+	headers     map[string]string
+}
+
+// WithContentType 指定请求体类型，默认 application/json
+func WithContentType(contentType string) HTTPOption {
+	return func(o *httpOptions) {
+		o.contentType = contentType
+	}
+}
+
+// WithGzip 使用 gzip 压缩请求体
+func WithGzip() HTTPOption {
+	return func(o *httpOptions) {
+		o.gzip = true
+	}
+}
+
+// WithHeader 添加请求头，如鉴权或多租户标识
+func WithHeader(key, value string) HTTPOption {
+	return func(o *httpOptions) {
+		if o.headers == nil {
+			o.headers = make(map[string]string)
+		}
+		o.headers[key] = value
+	}
+}
+
+// gzipWriters 复用 gzip 压缩器，避免每批重新分配压缩窗口
+var gzipWriters = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(io.Discard) },
+}
+
+// JSONEncoder 将记录编码为 {field: [记录...]}
+func JSONEncoder(field string) Encoder {
+	return func(records [][]byte) ([]byte, error) {
+		batch := make([]json.RawMessage, len(records))
+		for i, r := range records {
+			batch[i] = r
+		}
+		return json.Marshal(map[string]interface{}{field: batch})
+	}
+}
+
+// NewHTTPSender 创建以 JSON 批量 POST 的发送函数，请求体为 {field: [记录...]}
+// 2xx 视为成功；除 408、429 外的 4xx 视为不可重试，其余错误重试
+func NewHTTPSender(client *http.Client, url, field string, opts ...HTTPOption) Sender {
+	return NewEncodedHTTPSender(client, url, JSONEncoder(field), opts...)
+}
+
+// NewEncodedHTTPSender 创建按 encode 编码请求体的批量 POST 发送函数，响应处理同 NewHTTPSender
+func NewEncodedHTTPSender(client *http.Client, url string, encode Encoder, opts ...HTTPOption) Sender {
+	o := httpOptions{contentType: "application/json"}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(ctx context.Context, records [][]byte) error {
+		data, err := encode(records)
+		if err != nil {
+			return Permanent(fmt.Errorf("序列化记录失败: %w", err))
+		}
+		if o.gzip {
+			if data, err = compress(data); err != nil {
+				return Permanent(fmt.Errorf("压缩请求体失败: %w", err))
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+		if err != nil {
+			return Permanent(fmt.Errorf("创建请求失败: %w", err))
+		}
+		req.Header.Set("Content-Type", o.contentType)
+		if o.gzip {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+		for k, v := range o.headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("发送请求失败: %w", err)
+		}
+		defer resp.Body.Close()
+		// 读取少量响应体以便复用连接，避免异常响应占用内存
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+
+		switch code := resp.StatusCode; {
+		case code >= 200 && code < 300:
+			return nil
+		case code >= 400 && code < 500 && code != http.StatusRequestTimeout && code != http.StatusTooManyRequests:
+			return Permanent(fmt.Errorf("服务端拒绝接收，状态码: %d", code))
+		default:
+			return fmt.Errorf("服务端返回状态码: %d", code)
+		}
+	}
+}
+
+// compress gzip 压缩
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(len(data) / 4)
+
+	zw := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(zw)
+	zw.Reset(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
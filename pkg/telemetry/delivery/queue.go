@@ -0,0 +1,423 @@
+package delivery
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// segmentExt 段文件扩展名
+const segmentExt = ".seg"
+
+// Sender 发送一批记录，返回错误时整批重试；返回 Permanent 包装的错误时丢弃该批记录
+type Sender func(ctx context.Context, records [][]byte) error
+
+// Stats 投递统计
+type Stats struct {
+	Queued  int64 // 待发送的记录数（含落盘未发送的记录）
+	Sent    int64 // 已发送的记录数
+	Dropped int64 // 丢弃的记录数（落盘已满、队列已关闭或不可重试的错误）
+	Retries int64 // 重试次数
+}
+
+// segment 已封存、等待发送的段文件
+type segment struct {
+	path  string
+	size  int64
+	count int
+}
+
+// Queue 可靠投递队列
+// 记录先追加写入本地段文件，段文件写满、达到批量大小或定时刷新时封存，
+// 由后台协程按顺序发送，失败时按指数退避重试，发送成功后删除段文件。
+// 进程退出时未发送的段文件保留在磁盘上，下次启动时继续发送，保证至少一次投递
+type Queue struct {
+	name string
+	cfg  Config
+	send Sender
+
+	mu           sync.Mutex
+	current      *os.File
+	currentPath  string
+	currentSize  int64
+	currentCount int
+	sealed       []segment
+	totalBytes   int64
+	nextSeq      uint64
+	closed       bool
+
+	queued  atomic.Int64
+	sent    atomic.Int64
+	dropped atomic.Int64
+	retries atomic.Int64
+
+	notify  chan struct{}
+	closing chan struct{}
+	done    chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// New 创建投递队列，并恢复目录中上次未发送完的段文件
+func New(name string, cfg Config, send Sender) (*Queue, error) {
+	cfg = cfg.withDefaults()
+	if cfg.Dir == "" {
+		return nil, ErrNoSpoolDir
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建落盘目录失败: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		name:    name,
+		cfg:     cfg,
+		send:    send,
+		notify:  make(chan struct{}, 1),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	if err := q.recover(); err != nil {
+		cancel()
+		return nil, err
+	}
+	q.updateQueued()
+
+	go q.run()
+	return q, nil
+}
+
+// Enqueue 写入一条记录，记录中不能包含换行符
+func (q *Queue) Enqueue(record []byte) error {
+	if bytes.IndexByte(record, '\n') >= 0 {
+		q.drop(1)
+		return ErrInvalidRecord
+	}
+	size := int64(len(record)) + 1
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		q.drop(1)
+		return ErrClosed
+	}
+	if q.totalBytes+size > q.cfg.MaxSpoolBytes {
+		q.drop(1)
+		return ErrSpoolFull
+	}
+	if q.current == nil {
+		if err := q.openSegment(); err != nil {
+			q.drop(1)
+			return err
+		}
+	}
+	if _, err := q.current.Write(append(record[:len(record):len(record)], '\n')); err != nil {
+		q.drop(1)
+		return fmt.Errorf("写入段文件失败: %w", err)
+	}
+
+	q.currentSize += size
+	q.currentCount++
+	q.totalBytes += size
+	q.queued.Add(1)
+	q.updateQueued()
+
+	if q.currentCount >= q.cfg.BatchSize || q.currentSize >= q.cfg.MaxSegmentBytes {
+		q.sealLocked()
+		q.wake()
+	}
+	return nil
+}
+
+// Flush 封存当前段文件并唤醒发送协程
+func (q *Queue) Flush() {
+	q.mu.Lock()
+	q.sealLocked()
+	q.mu.Unlock()
+	q.wake()
+}
+
+// Stats 返回投递统计
+func (q *Queue) Stats() Stats {
+	return Stats{
+		Queued:  q.queued.Load(),
+		Sent:    q.sent.Load(),
+		Dropped: q.dropped.Load(),
+		Retries: q.retries.Load(),
+	}
+}
+
+// Close 停止接收新记录并等待已有记录发送完成
+// ctx 到期后中止发送，未发送的记录保留在磁盘上，下次启动时继续发送
+func (q *Queue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	q.mu.Unlock()
+
+	close(q.closing)
+	select {
+	case <-q.done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-q.done
+		return fmt.Errorf("[%s] 等待投递完成超时，%d 条记录保留在 %s: %w",
+			q.name, q.queued.Load(), q.cfg.Dir, ctx.Err())
+	}
+}
+
+// run 后台发送协程
+func (q *Queue) run() {
+	defer close(q.done)
+
+	ticker := time.NewTicker(q.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		q.drain()
+
+		select {
+		case <-q.notify:
+		case <-ticker.C:
+			q.Flush()
+		case <-q.closing:
+			q.mu.Lock()
+			q.sealLocked()
+			q.mu.Unlock()
+			q.drain()
+			return
+		}
+	}
+}
+
+// drain 按顺序发送所有已封存的段文件，中止时返回
+func (q *Queue) drain() {
+	for q.ctx.Err() == nil {
+		q.mu.Lock()
+		if len(q.sealed) == 0 {
+			q.mu.Unlock()
+			return
+		}
+		seg := q.sealed[0]
+		q.mu.Unlock()
+
+		if !q.deliver(seg) {
+			return
+		}
+
+		if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+			q.cfg.Logger.Errorf("[%s] 删除段文件失败: %v", q.name, err)
+		}
+		q.mu.Lock()
+		q.sealed = q.sealed[1:]
+		q.totalBytes -= seg.size
+		q.mu.Unlock()
+	}
+}
+
+// deliver 分批发送段文件中的记录，返回 false 表示发送被中止
+func (q *Queue) deliver(seg segment) bool {
+	records, err := readSegment(seg.path)
+	if err != nil {
+		q.cfg.Logger.Errorf("[%s] 读取段文件 %s 失败，丢弃 %d 条记录: %v", q.name, seg.path, seg.count, err)
+		q.settle(seg.count, false)
+		return true
+	}
+
+	for start := 0; start < len(records); start += q.cfg.BatchSize {
+		end := start + q.cfg.BatchSize
+		if end > len(records) {
+			end = len(records)
+		}
+		if !q.deliverBatch(records[start:end]) {
+			return false
+		}
+	}
+	return true
+}
+
+// deliverBatch 发送一批记录，失败时按指数退避重试直到成功、遇到不可重试的错误或被中止
+func (q *Queue) deliverBatch(batch [][]byte) bool {
+	backoff := q.cfg.MinBackoff
+	for {
+		ctx, cancel := context.WithTimeout(q.ctx, q.cfg.SendTimeout)
+		err := q.send(ctx, batch)
+		cancel()
+
+		if err == nil {
+			q.settle(len(batch), true)
+			return true
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			q.cfg.Logger.Errorf("[%s] 发送失败且不可重试，丢弃 %d 条记录: %v", q.name, len(batch), err)
+			q.settle(len(batch), false)
+			return true
+		}
+
+		q.retries.Add(1)
+		metricRetries.Inc(q.name)
+		q.cfg.Logger.Errorf("[%s] 发送失败，%s 后重试: %v", q.name, backoff, err)
+
+		select {
+		case <-q.ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > q.cfg.MaxBackoff {
+			backoff = q.cfg.MaxBackoff
+		}
+	}
+}
+
+// settle 记录一批记录的发送结果
+func (q *Queue) settle(n int, sent bool) {
+	q.queued.Add(-int64(n))
+	q.updateQueued()
+	if sent {
+		q.sent.Add(int64(n))
+		metricSent.Add(float64(n), q.name)
+		return
+	}
+	q.dropped.Add(int64(n))
+	metricDropped.Add(float64(n), q.name)
+}
+
+// drop 记录未能写入队列的记录
+func (q *Queue) drop(n int) {
+	q.dropped.Add(int64(n))
+	metricDropped.Add(float64(n), q.name)
+}
+
+// updateQueued 同步待发送记录数指标
+func (q *Queue) updateQueued() {
+	metricQueued.Set(float64(q.queued.Load()), q.name)
+}
+
+// wake 唤醒发送协程
+func (q *Queue) wake() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// openSegment 打开新的段文件，调用方需持有锁
+func (q *Queue) openSegment() error {
+	path := filepath.Join(q.cfg.Dir, fmt.Sprintf("%020d%s", q.nextSeq, segmentExt))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("创建段文件失败: %w", err)
+	}
+	q.nextSeq++
+	q.current = f
+	q.currentPath = path
+	q.currentSize = 0
+	q.currentCount = 0
+	return nil
+}
+
+// sealLocked 封存当前段文件，调用方需持有锁
+func (q *Queue) sealLocked() {
+	if q.current == nil {
+		return
+	}
+	if err := q.current.Sync(); err != nil {
+		q.cfg.Logger.Errorf("[%s] 同步段文件失败: %v", q.name, err)
+	}
+	if err := q.current.Close(); err != nil {
+		q.cfg.Logger.Errorf("[%s] 关闭段文件失败: %v", q.name, err)
+	}
+	if q.currentCount > 0 {
+		q.sealed = append(q.sealed, segment{path: q.currentPath, size: q.currentSize, count: q.currentCount})
+	} else {
+		os.Remove(q.currentPath)
+	}
+	q.current = nil
+	q.currentPath = ""
+}
+
+// recover 恢复目录中上次未发送完的段文件
+func (q *Queue) recover() error {
+	paths, err := filepath.Glob(filepath.Join(q.cfg.Dir, "*"+segmentExt))
+	if err != nil {
+		return fmt.Errorf("扫描落盘目录失败: %w", err)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		seq, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(path), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		if seq >= q.nextSeq {
+			q.nextSeq = seq + 1
+		}
+
+		records, err := readSegment(path)
+		if err != nil {
+			return fmt.Errorf("读取段文件 %s 失败: %w", path, err)
+		}
+		if len(records) == 0 {
+			os.Remove(path)
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("读取段文件 %s 失败: %w", path, err)
+		}
+
+		q.sealed = append(q.sealed, segment{path: path, size: info.Size(), count: len(records)})
+		q.totalBytes += info.Size()
+		q.queued.Add(int64(len(records)))
+	}
+
+	if len(q.sealed) > 0 {
+		q.cfg.Logger.Infof("[%s] 恢复 %d 个未发送的段文件，共 %d 条记录", q.name, len(q.sealed), q.queued.Load())
+	}
+	return nil
+}
+
+// readSegment 读取段文件中的所有记录，忽略末尾未写完整的记录
+func readSegment(path string) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records [][]byte
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(line) > 1 {
+			records = append(records, line[:len(line)-1])
+		}
+	}
+}
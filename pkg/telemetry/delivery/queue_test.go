@@ -0,0 +1,150 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordingSender 记录发送结果的发送函数，前 failures 次发送失败
+type recordingSender struct {
+	mu       sync.Mutex
+	records  []string
+	failures atomic.Int32
+	err      error
+}
+
+func (s *recordingSender) send(ctx context.Context, records [][]byte) error {
+	if s.failures.Add(-1) >= 0 {
+		return s.err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range records {
+		s.records = append(s.records, string(r))
+	}
+	return nil
+}
+
+func (s *recordingSender) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.records...)
+}
+
+// testConfig 测试用配置，缩短刷新和重试间隔
+func testConfig(t *testing.T) Config {
+	return Config{
+		Dir:           t.TempDir(),
+		BatchSize:     2,
+		FlushInterval: 20 * time.Millisecond,
+		MinBackoff:    time.Millisecond,
+		MaxBackoff:    5 * time.Millisecond,
+	}
+}
+
+// waitFor 等待条件成立
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("等待超时")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestQueue_RetryUntilSent 测试发送失败后重试直到成功，且保持顺序
+func TestQueue_RetryUntilSent(t *testing.T) {
+	sender := &recordingSender{err: errors.New("服务不可用")}
+	sender.failures.Store(3)
+
+	q, err := New("test", testConfig(t), sender.send)
+	if err != nil {
+		t.Fatalf("创建队列失败: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := q.Enqueue([]byte(fmt.Sprintf(`{"n":%d}`, i))); err != nil {
+			t.Fatalf("写入失败: %v", err)
+		}
+	}
+	if err := q.Close(context.Background()); err != nil {
+		t.Fatalf("关闭失败: %v", err)
+	}
+
+	got := sender.received()
+	if len(got) != 5 {
+		t.Fatalf("期望发送5条, 实际=%v", got)
+	}
+	for i, r := range got {
+		if r != fmt.Sprintf(`{"n":%d}`, i) {
+			t.Errorf("第%d条记录顺序错误: %s", i, r)
+		}
+	}
+	stats := q.Stats()
+	if stats.Sent != 5 || stats.Queued != 0 || stats.Dropped != 0 || stats.Retries != 3 {
+		t.Errorf("统计不符合预期: %+v", stats)
+	}
+}
+
+// TestQueue_RecoverAfterDeadline 测试关闭超时后记录保留在磁盘，下次启动时继续发送
+func TestQueue_RecoverAfterDeadline(t *testing.T) {
+	cfg := testConfig(t)
+	down := &recordingSender{err: errors.New("服务不可用")}
+	down.failures.Store(1 << 30)
+
+	q, _ := New("test", cfg, down.send)
+	q.Enqueue([]byte(`"a"`))
+	q.Enqueue([]byte(`"b"`))
+	q.Enqueue([]byte(`"c"`))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := q.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("期望关闭超时, 实际=%v", err)
+	}
+	if err := q.Enqueue([]byte(`"d"`)); err != ErrClosed {
+		t.Errorf("期望 ErrClosed, 实际=%v", err)
+	}
+
+	up := &recordingSender{}
+	q, err := New("test", cfg, up.send)
+	if err != nil {
+		t.Fatalf("恢复队列失败: %v", err)
+	}
+	if queued := q.Stats().Queued; queued != 3 {
+		t.Errorf("期望恢复3条记录, 实际=%d", queued)
+	}
+	waitFor(t, func() bool { return len(up.received()) == 3 })
+	q.Close(context.Background())
+}
+
+// TestQueue_Drop 测试落盘已满和不可重试的错误丢弃记录
+func TestQueue_Drop(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.MaxSpoolBytes = 10
+
+	sender := &recordingSender{err: Permanent(errors.New("格式错误"))}
+	sender.failures.Store(1)
+
+	q, _ := New("test", cfg, sender.send)
+	if err := q.Enqueue([]byte(`"12345678"`)); err != ErrSpoolFull {
+		t.Errorf("期望 ErrSpoolFull, 实际=%v", err)
+	}
+	if err := q.Enqueue([]byte("a\nb")); err != ErrInvalidRecord {
+		t.Errorf("期望 ErrInvalidRecord, 实际=%v", err)
+	}
+	q.Enqueue([]byte(`"x"`))
+	q.Enqueue([]byte(`"y"`))
+	q.Close(context.Background())
+
+	stats := q.Stats()
+	if stats.Dropped != 4 || stats.Sent != 0 || stats.Retries != 0 {
+		t.Errorf("统计不符合预期: %+v", stats)
+	}
+}
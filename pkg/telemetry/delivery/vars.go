@@ -0,0 +1,68 @@
+package delivery
+
+import (
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/metric"
+)
+
+// 错误定义
+var (
+	ErrNoSpoolDir    = errors.New("未配置落盘目录")
+	ErrClosed        = errors.New("投递队列已关闭")
+	ErrSpoolFull     = errors.New("落盘空间已满")
+	ErrInvalidRecord = errors.New("记录中不能包含换行符")
+)
+
+// 投递指标，按队列名称区分
+var (
+	metricQueued = metric.NewGaugeVec(&metric.GaugeVecOpts{
+		Namespace: "telemetry",
+		Subsystem: "delivery",
+		Name:      "queued",
+		Help:      "待发送的记录数",
+		Labels:    []string{"queue"},
+	})
+	metricSent = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "telemetry",
+		Subsystem: "delivery",
+		Name:      "sent_total",
+		Help:      "已发送的记录数",
+		Labels:    []string{"queue"},
+	})
+	metricDropped = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "telemetry",
+		Subsystem: "delivery",
+		Name:      "dropped_total",
+		Help:      "丢弃的记录数",
+		Labels:    []string{"queue"},
+	})
+	metricRetries = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "telemetry",
+		Subsystem: "delivery",
+		Name:      "retries_total",
+		Help:      "发送重试次数",
+		Labels:    []string{"queue"},
+	})
+)
+
+// permanentError 不可重试的发送错误
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent 将发送错误标记为不可重试，该批记录会被丢弃并计入丢弃数
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
@@ -0,0 +1,64 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"idrm/pkg/telemetry/delivery"
+)
+
+// 远程日志导出协议
+const (
+	ProtocolJSON = "json" // 自定义 JSON 接口，请求体为 {"logs": [...]}
+	ProtocolOTLP = "otlp" // OTLP/HTTP 日志接口（JSON 编码），地址如 http://collector:4318/v1/logs
+	ProtocolLoki = "loki" // Loki push 接口，地址如 http://loki:3100/loki/api/v1/push
+)
+
+// Resource 日志来源信息，作为 OTLP 资源属性和 Loki 标签
+type Resource struct {
+	ServiceName string
+	Environment string
+}
+
+// newEncoder 按协议创建请求体编码函数，返回编码函数和请求体类型
+func newEncoder(protocol string, resource Resource) (delivery.Encoder, string, error) {
+	switch strings.ToLower(strings.TrimSpace(protocol)) {
+	case "", ProtocolJSON:
+		return delivery.JSONEncoder("logs"), "application/json", nil
+	case ProtocolOTLP:
+		return func(records [][]byte) ([]byte, error) {
+			return encodeOTLP(resource, decodeEntries(records))
+		}, "application/json", nil
+	case ProtocolLoki:
+		return func(records [][]byte) ([]byte, error) {
+			return encodeLoki(resource, decodeEntries(records))
+		}, "application/json", nil
+	default:
+		return nil, "", fmt.Errorf("不支持的远程日志协议: %s", protocol)
+	}
+}
+
+// decodeEntries 解析落盘的日志条目，跳过无法解析的记录；数值字段保留原始精度
+func decodeEntries(records [][]byte) []LogEntry {
+	entries := make([]LogEntry, 0, len(records))
+	for _, r := range records {
+		var entry LogEntry
+		decoder := json.NewDecoder(bytes.NewReader(r))
+		decoder.UseNumber()
+		if err := decoder.Decode(&entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// unixNano 日志时间（纳秒），兼容只有秒级时间的记录
+func (e LogEntry) unixNano() int64 {
+	if e.TimeUnixNano > 0 {
+		return e.TimeUnixNano
+	}
+	return e.Timestamp * 1e9
+}
@@ -0,0 +1,138 @@
+package log
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"idrm/pkg/telemetry/delivery"
+)
+
+// shipLine 通过指定协议发送一行 logx 日志，返回服务端收到的请求体
+func shipLine(t *testing.T, protocol string, compress bool, line string) map[string]interface{} {
+	bodies := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reader io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("解压请求体失败: %v", err)
+				return
+			}
+			reader = zr
+		} else if compress {
+			t.Error("期望请求体使用 gzip 压缩")
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(reader).Decode(&body); err != nil {
+			t.Errorf("解析请求体失败: %v", err)
+		}
+		bodies <- body
+	}))
+	defer server.Close()
+
+	writer, err := NewRemoteWriter(RemoteConfig{
+		Url:      server.URL,
+		Timeout:  time.Second,
+		Protocol: protocol,
+		Compress: compress,
+		Resource: Resource{ServiceName: "idrm-api", Environment: "prod"},
+	}, delivery.Config{Dir: t.TempDir(), BatchSize: 1})
+	if err != nil {
+		t.Fatalf("创建远程日志写入器失败: %v", err)
+	}
+	writer.Write([]byte(line))
+	if err := writer.Close(); err != nil {
+		t.Fatalf("关闭失败: %v", err)
+	}
+
+	select {
+	case body := <-bodies:
+		return body
+	case <-time.After(3 * time.Second):
+		t.Fatal("未收到请求")
+		return nil
+	}
+}
+
+const testLine = `{"@timestamp":"2026-01-10T09:00:00.123+08:00","caller":"logic/tag.go:42","content":"更新标签","level":"error","span":"b7ad6b7169203331","trace":"0af7651916cd43dd8448eb211c80319c","tagId":7}`
+
+// TestRemoteWriter_OTLP 测试 OTLP 日志协议
+func TestRemoteWriter_OTLP(t *testing.T) {
+	body := shipLine(t, ProtocolOTLP, true, testLine)
+
+	var data otlpLogsData
+	raw, _ := json.Marshal(body)
+	json.Unmarshal(raw, &data)
+
+	resource := data.ResourceLogs[0].Resource.Attributes
+	if *resource[0].Value.StringValue != "idrm-api" || *resource[1].Value.StringValue != "prod" {
+		t.Errorf("资源属性不符合预期: %s", raw)
+	}
+	record := data.ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	if record.TimeUnixNano != "1768006800123000000" || record.SeverityNumber != 17 ||
+		record.TraceId != "0af7651916cd43dd8448eb211c80319c" || record.SpanId != "b7ad6b7169203331" ||
+		*record.Body.StringValue != "更新标签" {
+		t.Errorf("日志记录不符合预期: %s", raw)
+	}
+	attrs := make(map[string]otlpAnyValue)
+	for _, kv := range record.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if v := attrs["tagId"]; v.IntValue == nil || *v.IntValue != "7" {
+		t.Errorf("自定义字段应为整数属性: %s", raw)
+	}
+}
+
+// TestRemoteWriter_Loki 测试 Loki push 协议
+func TestRemoteWriter_Loki(t *testing.T) {
+	body := shipLine(t, ProtocolLoki, false, testLine)
+
+	var push lokiPush
+	raw, _ := json.Marshal(body)
+	json.Unmarshal(raw, &push)
+
+	if len(push.Streams) != 1 {
+		t.Fatalf("期望1个日志流: %s", raw)
+	}
+	stream := push.Streams[0]
+	want := map[string]string{"service_name": "idrm-api", "environment": "prod", "level": "error"}
+	for k, v := range want {
+		if stream.Stream[k] != v {
+			t.Errorf("标签 %s 期望 %s, 实际 %s", k, v, stream.Stream[k])
+		}
+	}
+	if stream.Values[0][0] != "1768006800123000000" {
+		t.Errorf("时间戳不符合预期: %s", stream.Values[0][0])
+	}
+	var entry LogEntry
+	if err := json.Unmarshal([]byte(stream.Values[0][1]), &entry); err != nil || entry.TraceID != "0af7651916cd43dd8448eb211c80319c" {
+		t.Errorf("日志行不符合预期: %s, %v", stream.Values[0][1], err)
+	}
+}
+
+// TestRemoteWriter_JSON 测试自定义 JSON 协议保持原有格式
+func TestRemoteWriter_JSON(t *testing.T) {
+	body := shipLine(t, ProtocolJSON, false, testLine)
+
+	logs, ok := body["logs"].([]interface{})
+	if !ok || len(logs) != 1 {
+		t.Fatalf("请求体不符合预期: %v", body)
+	}
+	entry := logs[0].(map[string]interface{})
+	if entry["message"] != "更新标签" || entry["service_name"] != "idrm-api" {
+		t.Errorf("日志条目不符合预期: %v", entry)
+	}
+}
+
+// TestNewRemoteWriter_InvalidProtocol 测试不支持的协议
+func TestNewRemoteWriter_InvalidProtocol(t *testing.T) {
+	_, err := NewRemoteWriter(RemoteConfig{Url: "http://localhost", Protocol: "syslog"}, delivery.Config{Dir: t.TempDir()})
+	if err == nil {
+		t.Error("期望创建失败")
+	}
+}
@@ -2,8 +2,11 @@ package log
 
 import (
 	"io"
+	"path/filepath"
 	"time"
 
+	"idrm/pkg/telemetry/delivery"
+
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
@@ -22,53 +25,81 @@ type LogConfig struct {
 	RemoteUrl     string
 	RemoteBatch   int
 	RemoteTimeout int
+
+	RemoteSpoolDir     string // 落盘目录
+	RemoteSpoolMaxSize int    // 落盘上限(MB)
+	RemoteDrainTimeout int    // 关闭时等待发送完成的超时时间(秒)
+
+	RemoteProtocol     string // 发送协议 json/otlp/loki，默认 json
+	RemoteCompress     bool   // 使用 gzip 压缩请求体
+	RemoteMaxBatchSize int    // 单批请求最大大小(KB)，默认 1024
+
+	Environment string // 运行环境，作为 OTLP 资源属性和 Loki 标签
+}
+
+// ApplyLogConf 将日志配置写入 go-zero logx 配置，其余字段保持原值
+// 固定使用 JSON 编码，远程写入器按 JSON 解析可以保留 trace、caller 等字段
+func ApplyLogConf(conf *logx.LogConf, config LogConfig, serviceName string) {
+	conf.ServiceName = serviceName
+	conf.Mode = config.Mode
+	conf.Encoding = "json"
+	conf.Level = config.Level
+	conf.Path = config.Path
+	conf.KeepDays = config.KeepDays
+	conf.Compress = true
 }
 
 // Init 初始化日志系统
+// logx.SetUp 只有第一次调用生效，rest.MustNewServer 会先按 RestConf.Log 初始化 logx，
+// 与 rest 服务一起使用时需在创建服务前用 ApplyLogConf 写入 RestConf.Log，此处的 SetUp 不再生效
 func Init(config LogConfig, serviceName string) {
 	// 1. 配置 go-zero logx
-	logConf := logx.LogConf{
-		ServiceName: serviceName,
-		Mode:        config.Mode,
-		Level:       config.Level,
-		Path:        config.Path,
-		KeepDays:    config.KeepDays,
-		Compress:    true,
-	}
-
+	var logConf logx.LogConf
+	ApplyLogConf(&logConf, config, serviceName)
 	if err := logx.SetUp(logConf); err != nil {
 		panic(err)
 	}
 
 	// 2. 如果启用远程日志，添加远程 Writer
 	if config.RemoteEnabled && config.RemoteUrl != "" {
-		timeout := time.Duration(config.RemoteTimeout) * time.Second
-		remoteWriter = NewRemoteWriter(
-			serviceName,
-			config.RemoteUrl,
-			config.RemoteBatch,
-			timeout,
-		)
-
-		// 添加远程 Writer 到 logx
-		// 注意：go-zero logx 需要通过 writer 方式添加
-		// 这里我们使用 logx.AddWriter 的替代方案
-		setupRemoteWriter(remoteWriter)
+		spoolDir := config.RemoteSpoolDir
+		if spoolDir == "" {
+			spoolDir = filepath.Join("spool", "log")
+		}
+		maxBatchSize := config.RemoteMaxBatchSize
+		if maxBatchSize <= 0 {
+			maxBatchSize = 1024
+		}
+		writer, err := NewRemoteWriter(RemoteConfig{
+			Url:      config.RemoteUrl,
+			Timeout:  time.Duration(config.RemoteTimeout) * time.Second,
+			Protocol: config.RemoteProtocol,
+			Compress: config.RemoteCompress,
+			Resource: Resource{ServiceName: serviceName, Environment: config.Environment},
+		}, delivery.Config{
+			Dir:             spoolDir,
+			MaxSegmentBytes: int64(maxBatchSize) << 10,
+			MaxSpoolBytes:   int64(config.RemoteSpoolMaxSize) << 20,
+			BatchSize:       config.RemoteBatch,
+		})
+		if err != nil {
+			logx.Errorf("远程日志初始化失败: %v", err)
+		} else {
+			if config.RemoteDrainTimeout > 0 {
+				writer.drainTimeout = time.Duration(config.RemoteDrainTimeout) * time.Second
+			}
+			remoteWriter = writer
+			setupRemoteWriter(remoteWriter)
+		}
 	}
 
-	logx.Infof("日志系统初始化完成 [mode=%s, level=%s, remote=%v]",
-		config.Mode, config.Level, config.RemoteEnabled)
+	logx.Infof("日志系统初始化完成 [mode=%s, level=%s, remote=%v, protocol=%s]",
+		config.Mode, config.Level, config.RemoteEnabled, config.RemoteProtocol)
 }
 
-// setupRemoteWriter 设置远程日志写入器
-// 由于 go-zero logx 的限制，我们需要通过其他方式集成
+// setupRemoteWriter 将远程写入器追加到 logx，本地输出保持不变
 func setupRemoteWriter(writer io.Writer) {
-	// go-zero 1.9.x 支持通过环境变量或直接修改内部writer
-	// 这里我们简化处理，在业务代码中可以手动调用 remoteWriter
-	// 或者通过 logx 的 stat/slow log 功能扩展
-
-	// 临时方案：保存 writer 供外部使用
-	// 实际使用时可以包装 logx 的方法
+	logx.AddWriter(logx.NewWriter(writer))
 }
 
 // Close 关闭日志系统
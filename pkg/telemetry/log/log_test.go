@@ -0,0 +1,88 @@
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// TestInit_RemoteWriter 测试 logx 输出写入远程，且发送失败的日志不会再次入队
+func TestInit_RemoteWriter(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		messages []string
+		requests atomic.Int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 首次请求失败，触发队列的重试日志
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var body struct {
+			Logs []LogEntry `json:"logs"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("解析请求体失败: %v", err)
+			return
+		}
+		mu.Lock()
+		for _, entry := range body.Logs {
+			messages = append(messages, entry.Message)
+		}
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	Init(LogConfig{
+		Mode:           "console",
+		Level:          "info",
+		RemoteEnabled:  true,
+		RemoteUrl:      server.URL,
+		RemoteBatch:    1,
+		RemoteTimeout:  1,
+		RemoteSpoolDir: t.TempDir(),
+	}, "idrm-api")
+	logx.Info("远程日志")
+	Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	var found bool
+	for _, message := range messages {
+		if message == "远程日志" {
+			found = true
+		}
+		if strings.Contains(message, "发送失败") {
+			t.Errorf("队列自身的日志不应写入远程: %s", message)
+		}
+	}
+	if !found {
+		t.Errorf("期望收到业务日志, 实际=%v", messages)
+	}
+	if requests.Load() < 2 {
+		t.Errorf("期望发送失败后重试, 实际请求次数=%d", requests.Load())
+	}
+}
+
+// TestApplyLogConf 测试日志配置写入 rest 服务的 logx 配置时保留其他字段
+func TestApplyLogConf(t *testing.T) {
+	conf := logx.LogConf{Mode: "console", Encoding: "plain", Stat: true, StackCooldownMillis: 100}
+	ApplyLogConf(&conf, LogConfig{Mode: "file", Level: "error", Path: "logs", KeepDays: 7}, "idrm-api")
+
+	if conf.Mode != "file" || conf.Level != "error" || conf.Path != "logs" || conf.KeepDays != 7 {
+		t.Errorf("日志配置未写入: %+v", conf)
+	}
+	if conf.Encoding != "json" || conf.ServiceName != "idrm-api" {
+		t.Errorf("期望 JSON 编码和服务名, 实际=%+v", conf)
+	}
+	if !conf.Stat || conf.StackCooldownMillis != 100 {
+		t.Errorf("其他字段应保持原值, 实际=%+v", conf)
+	}
+}
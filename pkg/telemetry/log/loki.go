@@ -0,0 +1,68 @@
+package log
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// lokiPush Loki push 接口请求体
+type lokiPush struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+// lokiStream 相同标签的日志流，values 为 [纳秒时间戳, 日志行]
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// encodeLoki 编码为 Loki push 请求体
+// 标签只使用服务名、环境和级别，避免高基数；日志行为完整的 JSON 条目，可用 LogQL 的 json 解析器提取字段
+func encodeLoki(resource Resource, entries []LogEntry) ([]byte, error) {
+	streams := make(map[string]*lokiStream)
+	for _, e := range entries {
+		level := strings.ToLower(e.Level)
+		stream, ok := streams[level]
+		if !ok {
+			labels := map[string]string{
+				"service_name": resource.ServiceName,
+				"level":        level,
+			}
+			if resource.Environment != "" {
+				labels["environment"] = resource.Environment
+			}
+			stream = &lokiStream{Stream: labels}
+			streams[level] = stream
+		}
+
+		line, err := json.Marshal(e)
+		if err != nil {
+			continue
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(e.unixNano(), 10), string(line)})
+	}
+
+	push := lokiPush{Streams: make([]lokiStream, 0, len(streams))}
+	for _, level := range sortedKeys(streams) {
+		stream := streams[level]
+		sort.SliceStable(stream.Values, func(i, j int) bool {
+			a, _ := strconv.ParseInt(stream.Values[i][0], 10, 64)
+			b, _ := strconv.ParseInt(stream.Values[j][0], 10, 64)
+			return a < b
+		})
+		push.Streams = append(push.Streams, *stream)
+	}
+	return json.Marshal(push)
+}
+
+// sortedKeys 按字母顺序返回 map 的键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
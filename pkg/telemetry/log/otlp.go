@@ -0,0 +1,141 @@
+package log
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// OTLP 日志数据结构（OTLP/HTTP JSON 编码），只包含用到的字段
+type (
+	otlpLogsData struct {
+		ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+	}
+	otlpResourceLogs struct {
+		Resource  otlpResource    `json:"resource"`
+		ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeLogs struct {
+		Scope      otlpScope       `json:"scope"`
+		LogRecords []otlpLogRecord `json:"logRecords"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpLogRecord struct {
+		TimeUnixNano   string         `json:"timeUnixNano"`
+		SeverityNumber int            `json:"severityNumber"`
+		SeverityText   string         `json:"severityText"`
+		Body           otlpAnyValue   `json:"body"`
+		Attributes     []otlpKeyValue `json:"attributes,omitempty"`
+		TraceId        string         `json:"traceId,omitempty"`
+		SpanId         string         `json:"spanId,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+	otlpAnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// otlpSeverity logx 级别对应的 OTLP 日志级别
+var otlpSeverity = map[string]int{
+	"debug":  5,
+	"info":   9,
+	"stat":   9,
+	"slow":   13,
+	"warn":   13,
+	"error":  17,
+	"severe": 17,
+	"alert":  17,
+	"fatal":  21,
+}
+
+// encodeOTLP 编码为 OTLP 日志请求体
+func encodeOTLP(resource Resource, entries []LogEntry) ([]byte, error) {
+	records := make([]otlpLogRecord, 0, len(entries))
+	for _, e := range entries {
+		record := otlpLogRecord{
+			TimeUnixNano:   strconv.FormatInt(e.unixNano(), 10),
+			SeverityNumber: otlpSeverity[strings.ToLower(e.Level)],
+			SeverityText:   strings.ToUpper(e.Level),
+			Body:           stringValue(e.Message),
+		}
+		if isHexID(e.TraceID, 32) {
+			record.TraceId = e.TraceID
+		}
+		if isHexID(e.SpanID, 16) {
+			record.SpanId = e.SpanID
+		}
+		if e.Caller != "" {
+			record.Attributes = append(record.Attributes, otlpKeyValue{Key: "code.caller", Value: stringValue(e.Caller)})
+		}
+		if e.Duration != "" {
+			record.Attributes = append(record.Attributes, otlpKeyValue{Key: "duration", Value: stringValue(e.Duration)})
+		}
+		for _, k := range sortedKeys(e.Fields) {
+			record.Attributes = append(record.Attributes, otlpKeyValue{Key: k, Value: anyValue(e.Fields[k])})
+		}
+		records = append(records, record)
+	}
+
+	attributes := []otlpKeyValue{{Key: "service.name", Value: stringValue(resource.ServiceName)}}
+	if resource.Environment != "" {
+		attributes = append(attributes, otlpKeyValue{Key: "deployment.environment", Value: stringValue(resource.Environment)})
+	}
+	return json.Marshal(otlpLogsData{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: otlpResource{Attributes: attributes},
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: "idrm/pkg/telemetry/log"},
+				LogRecords: records,
+			}},
+		}},
+	})
+}
+
+// stringValue 字符串属性值
+func stringValue(s string) otlpAnyValue {
+	return otlpAnyValue{StringValue: &s}
+}
+
+// anyValue 按类型转换属性值，对象和数组按 JSON 文本输出
+func anyValue(v interface{}) otlpAnyValue {
+	switch val := v.(type) {
+	case string:
+		return stringValue(val)
+	case bool:
+		return otlpAnyValue{BoolValue: &val}
+	case json.Number:
+		if _, err := strconv.ParseInt(string(val), 10, 64); err == nil {
+			s := string(val)
+			return otlpAnyValue{IntValue: &s}
+		}
+		if f, err := val.Float64(); err == nil {
+			return otlpAnyValue{DoubleValue: &f}
+		}
+		return stringValue(string(val))
+	case float64:
+		return otlpAnyValue{DoubleValue: &val}
+	default:
+		return stringValue(toString(val))
+	}
+}
+
+// isHexID 是否为指定长度的非零十六进制ID
+func isHexID(id string, length int) bool {
+	if len(id) != length || strings.Trim(id, "0") == "" {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
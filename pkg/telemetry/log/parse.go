@@ -0,0 +1,167 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// logx 输出的字段名和时间格式
+const (
+	keyTimestamp = "@timestamp"
+	keyLevel     = "level"
+	keyContent   = "content"
+	keyCaller    = "caller"
+	keyTrace     = "trace"
+	keySpan      = "span"
+	keyDuration  = "duration"
+
+	logxTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+	// plainSep plain 编码下各部分的分隔符
+	plainSep = "\t"
+)
+
+// ansiPattern plain 编码在终端下为级别添加的颜色控制符
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// parseLogEntry 解析 logx 输出的一行日志
+// json 编码按字段提取；plain 编码按 "时间\t级别\t内容\tkey=value..." 提取；
+// 无法识别的格式整行作为内容，级别为 info
+func (w *RemoteWriter) parseLogEntry(p []byte) LogEntry {
+	line := bytes.TrimRight(p, "\r\n")
+
+	entry, ok := parseJSONEntry(line)
+	if !ok {
+		entry, ok = parsePlainEntry(string(line))
+	}
+	if !ok {
+		entry = LogEntry{Message: string(line)}
+	}
+
+	entry.ServiceName = w.serviceName
+	if entry.TimeUnixNano == 0 {
+		entry.setTime(time.Now())
+	}
+	if entry.Level == "" {
+		entry.Level = "info"
+	}
+	return entry
+}
+
+// parseJSONEntry 解析 json 编码的日志，除固定字段外的字段放入 Fields
+func parseJSONEntry(line []byte) (LogEntry, bool) {
+	var entry LogEntry
+	if len(line) == 0 || line[0] != '{' {
+		return entry, false
+	}
+
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return entry, false
+	}
+
+	for key, value := range raw {
+		switch key {
+		case keyTimestamp:
+			if t, ok := parseTimestamp(toString(value)); ok {
+				entry.setTime(t)
+			}
+		case keyLevel:
+			entry.Level = toString(value)
+		case keyContent:
+			entry.Message = toString(value)
+		case keyCaller:
+			entry.Caller = toString(value)
+		case keyTrace:
+			entry.TraceID = toString(value)
+		case keySpan:
+			entry.SpanID = toString(value)
+		case keyDuration:
+			entry.Duration = toString(value)
+		default:
+			if entry.Fields == nil {
+				entry.Fields = make(map[string]interface{})
+			}
+			entry.Fields[key] = value
+		}
+	}
+	return entry, true
+}
+
+// parsePlainEntry 解析 plain 编码的日志，时间无法识别时视为未知格式
+func parsePlainEntry(line string) (LogEntry, bool) {
+	var entry LogEntry
+	parts := strings.Split(line, plainSep)
+	if len(parts) < 3 {
+		return entry, false
+	}
+	t, ok := parseTimestamp(parts[0])
+	if !ok {
+		return entry, false
+	}
+	entry.setTime(t)
+	entry.Level = strings.TrimSpace(ansiPattern.ReplaceAllString(parts[1], ""))
+	entry.Message = parts[2]
+
+	for _, item := range parts[3:] {
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			// 内容本身包含分隔符
+			entry.Message += plainSep + item
+			continue
+		}
+		switch key {
+		case keyCaller:
+			entry.Caller = value
+		case keyTrace:
+			entry.TraceID = value
+		case keySpan:
+			entry.SpanID = value
+		case keyDuration:
+			entry.Duration = value
+		default:
+			if entry.Fields == nil {
+				entry.Fields = make(map[string]interface{})
+			}
+			entry.Fields[key] = value
+		}
+	}
+	return entry, true
+}
+
+// parseTimestamp 解析 logx 时间
+func parseTimestamp(value string) (time.Time, bool) {
+	for _, layout := range []string{logxTimeFormat, time.RFC3339Nano} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// setTime 设置日志时间
+func (e *LogEntry) setTime(t time.Time) {
+	e.Timestamp = t.Unix()
+	e.TimeUnixNano = t.UnixNano()
+}
+
+// toString 将字段值转换为字符串，非字符串值按 JSON 输出
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+}
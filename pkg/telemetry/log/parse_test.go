@@ -0,0 +1,73 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// TestParseLogEntry 测试解析 logx 的 json 和 plain 编码
+func TestParseLogEntry(t *testing.T) {
+	w := &RemoteWriter{serviceName: "idrm-api"}
+
+	tests := []struct {
+		name string
+		line string
+		want LogEntry
+	}{
+		{
+			name: "json",
+			line: `{"@timestamp":"2026-01-10T09:00:00.123+08:00","caller":"logic/tag.go:42","content":"更新标签","duration":"1.2ms","level":"error","span":"b7ad6b7169203331","trace":"0af7651916cd43dd8448eb211c80319c","tagId":7}` + "\n",
+			want: LogEntry{
+				Timestamp: 1768006800, Level: "error", Message: "更新标签", Caller: "logic/tag.go:42",
+				TraceID: "0af7651916cd43dd8448eb211c80319c", SpanID: "b7ad6b7169203331", Duration: "1.2ms",
+			},
+		},
+		{
+			name: "plain 带颜色",
+			line: "2026-01-10T09:00:00.123+08:00\t\x1b[34m info \x1b[0m\t启动完成\tcaller=svc/ctx.go:10\ttrace=abc\tport=8888\n",
+			want: LogEntry{Timestamp: 1768006800, Level: "info", Message: "启动完成", Caller: "svc/ctx.go:10", TraceID: "abc"},
+		},
+		{
+			name: "未知格式",
+			line: "panic: WARNING something\n",
+			want: LogEntry{Level: "info", Message: "panic: WARNING something"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := w.parseLogEntry([]byte(tt.line))
+			if tt.want.Timestamp == 0 && got.Timestamp == 0 {
+				t.Error("未知格式应使用当前时间")
+			}
+			if tt.want.Timestamp != 0 && got.Timestamp != tt.want.Timestamp {
+				t.Errorf("Timestamp 期望 %d, 实际 %d", tt.want.Timestamp, got.Timestamp)
+			}
+			if got.Level != tt.want.Level || got.Message != tt.want.Message || got.Caller != tt.want.Caller ||
+				got.TraceID != tt.want.TraceID || got.SpanID != tt.want.SpanID || got.Duration != tt.want.Duration {
+				t.Errorf("期望 %+v, 实际 %+v", tt.want, got)
+			}
+			if got.ServiceName != "idrm-api" {
+				t.Errorf("ServiceName 期望 idrm-api, 实际 %s", got.ServiceName)
+			}
+		})
+	}
+}
+
+// TestParseLogEntry_LogxOutput 测试解析 logx 实际输出的自定义字段
+func TestParseLogEntry_LogxOutput(t *testing.T) {
+	var buf bytes.Buffer
+	logx.SetWriter(logx.NewWriter(&buf))
+	defer logx.Reset()
+
+	logx.Infow("导出完成", logx.Field("rows", 12), logx.Field("format", "csv"))
+
+	got := (&RemoteWriter{}).parseLogEntry(buf.Bytes())
+	if got.Level != "info" || got.Message != "导出完成" || got.Caller == "" {
+		t.Errorf("解析结果不符合预期: %+v", got)
+	}
+	if got.Fields["format"] != "csv" || got.Fields["rows"] == nil {
+		t.Errorf("自定义字段不符合预期: %+v", got.Fields)
+	}
+}
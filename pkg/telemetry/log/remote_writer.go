@@ -1,177 +1,129 @@
 package log
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"sync"
+	"os"
 	"time"
+	"unicode/utf8"
 
-	"github.com/zeromicro/go-zero/core/logx"
+	"idrm/pkg/telemetry/delivery"
 )
 
+// maxMessageBytes 单条日志内容的最大字节数，超出部分截断，避免单条日志占满一批
+const maxMessageBytes = 64 << 10
+
 // RemoteWriter 远程日志写入器
+// 日志先落盘再按协议批量发送，发送失败时重试，关闭时等待发送完成
 type RemoteWriter struct {
-	serviceName string
-	url         string
-	client      *http.Client
-	batchSize   int
-	buffer      []LogEntry
-	mu          sync.Mutex
-	closeChan   chan struct{}
+	serviceName  string
+	queue        *delivery.Queue
+	drainTimeout time.Duration
 }
 
-// LogEntry 日志条目
-type LogEntry struct {
-	Timestamp   int64                  `json:"timestamp"`
-	Level       string                 `json:"level"`
-	Message     string                 `json:"message"`
-	ServiceName string                 `json:"service_name"`
-	TraceID     string                 `json:"trace_id,omitempty"`
-	SpanID      string                 `json:"span_id,omitempty"`
-	Fields      map[string]interface{} `json:"fields,omitempty"`
+// RemoteConfig 远程日志发送配置
+type RemoteConfig struct {
+	Url      string
+	Timeout  time.Duration
+	Protocol string // json/otlp/loki，默认 json
+	Compress bool   // 使用 gzip 压缩请求体
+	Resource Resource
 }
 
-// NewRemoteWriter 创建远程日志写入器
-func NewRemoteWriter(serviceName, url string, batchSize int, timeout time.Duration) *RemoteWriter {
-	rw := &RemoteWriter{
-		serviceName: serviceName,
-		url:         url,
-		batchSize:   batchSize,
-		buffer:      make([]LogEntry, 0, batchSize),
-		closeChan:   make(chan struct{}),
-		client: &http.Client{
-			Timeout: timeout,
-		},
-	}
-
-	// 启动定时发送
-	go rw.flushLoop()
+// stderrLogger 远程日志队列自身的日志直接输出到标准错误
+// 不能经过 logx，否则发送失败的日志会再次写入同一队列，远端不可用时越积越多
+type stderrLogger struct{}
 
-	return rw
+func (stderrLogger) Errorf(format string, v ...interface{}) {
+	fmt.Fprintf(os.Stderr, "remote log: "+format+"\n", v...)
 }
 
-// Write 实现 io.Writer 接口
-func (w *RemoteWriter) Write(p []byte) (n int, err error) {
-	// 解析日志内容并添加到缓冲区
-	entry := w.parseLogEntry(p)
-
-	w.mu.Lock()
-	w.buffer = append(w.buffer, entry)
-	shouldFlush := len(w.buffer) >= w.batchSize
-	w.mu.Unlock()
-
-	if shouldFlush {
-		w.flush()
-	}
-
-	return len(p), nil
+func (stderrLogger) Infof(format string, v ...interface{}) {
+	fmt.Fprintf(os.Stderr, "remote log: "+format+"\n", v...)
 }
 
-// flush 发送日志到远程服务器
-func (w *RemoteWriter) flush() {
-	w.mu.Lock()
-	if len(w.buffer) == 0 {
-		w.mu.Unlock()
-		return
-	}
-
-	logs := make([]LogEntry, len(w.buffer))
-	copy(logs, w.buffer)
-	w.buffer = w.buffer[:0]
-	w.mu.Unlock()
-
-	// 异步发送到远程
-	go w.send(logs)
+// LogEntry 日志条目
+type LogEntry struct {
+	Timestamp    int64                  `json:"timestamp"`
+	TimeUnixNano int64                  `json:"time_unix_nano,omitempty"`
+	Level        string                 `json:"level"`
+	Message      string                 `json:"message"`
+	ServiceName  string                 `json:"service_name"`
+	Caller       string                 `json:"caller,omitempty"`
+	TraceID      string                 `json:"trace_id,omitempty"`
+	SpanID       string                 `json:"span_id,omitempty"`
+	Duration     string                 `json:"duration,omitempty"`
+	Fields       map[string]interface{} `json:"fields,omitempty"`
 }
 
-// send 发送日志（异步）
-func (w *RemoteWriter) send(logs []LogEntry) {
-	data, err := json.Marshal(map[string]interface{}{
-		"logs": logs,
-	})
+// NewRemoteWriter 创建远程日志写入器
+// spool 控制落盘和批量发送，其中 MaxSegmentBytes 同时限制单批请求的大小
+func NewRemoteWriter(cfg RemoteConfig, spool delivery.Config) (*RemoteWriter, error) {
+	encode, contentType, err := newEncoder(cfg.Protocol, cfg.Resource)
 	if err != nil {
-		logx.Errorf("marshal remote logs failed: %v", err)
-		return
+		return nil, err
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "POST", w.url, bytes.NewReader(data))
-	if err != nil {
-		logx.Errorf("create remote log request failed: %v", err)
-		return
+	opts := []delivery.HTTPOption{delivery.WithContentType(contentType)}
+	if cfg.Compress {
+		opts = append(opts, delivery.WithGzip())
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := w.client.Do(req)
+	client := &http.Client{
+		Timeout: cfg.Timeout,
+	}
+	if spool.Logger == nil {
+		spool.Logger = stderrLogger{}
+	}
+	queue, err := delivery.New("log", spool, delivery.NewEncodedHTTPSender(client, cfg.Url, encode, opts...))
 	if err != nil {
-		logx.Errorf("send remote logs failed: %v", err)
-		return
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		logx.Errorf("remote log server returned status: %d", resp.StatusCode)
-	}
+	return &RemoteWriter{
+		serviceName:  cfg.Resource.ServiceName,
+		queue:        queue,
+		drainTimeout: 5 * time.Second,
+	}, nil
 }
 
-// flushLoop 定时刷新
-func (w *RemoteWriter) flushLoop() {
-	ticker := time.NewTicker(3 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			w.flush()
-		case <-w.closeChan:
-			w.flush()
-			return
+// Write 实现 io.Writer 接口
+// 写入失败（如落盘已满）的日志计入丢弃数，不向调用方返回错误，避免影响业务日志输出
+func (w *RemoteWriter) Write(p []byte) (n int, err error) {
+	// 解析日志内容并写入落盘队列
+	entry := w.parseLogEntry(p)
+	if len(entry.Message) > maxMessageBytes {
+		// 在字符边界截断
+		cut := maxMessageBytes
+		for cut > 0 && !utf8.RuneStart(entry.Message[cut]) {
+			cut--
 		}
+		entry.Message = entry.Message[:cut]
+		if entry.Fields == nil {
+			entry.Fields = make(map[string]interface{})
+		}
+		entry.Fields["truncated"] = true
 	}
-}
 
-// Close 关闭
-func (w *RemoteWriter) Close() error {
-	close(w.closeChan)
-	time.Sleep(100 * time.Millisecond) // 等待最后一次flush
-	return nil
-}
-
-// parseLogEntry 解析日志条目
-func (w *RemoteWriter) parseLogEntry(p []byte) LogEntry {
-	// 简单解析，实际可以更复杂
-	entry := LogEntry{
-		Timestamp:   time.Now().Unix(),
-		Message:     string(p),
-		ServiceName: w.serviceName,
-		Fields:      make(map[string]interface{}),
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return len(p), nil
 	}
+	w.queue.Enqueue(data)
 
-	// 尝试从消息中提取level
-	msg := string(p)
-	if len(msg) > 0 {
-		// 简单判断（实际应该解析JSON格式的logx输出）
-		switch {
-		case contains(msg, "ERROR"):
-			entry.Level = "error"
-		case contains(msg, "WARN"):
-			entry.Level = "warn"
-		case contains(msg, "INFO"):
-			entry.Level = "info"
-		case contains(msg, "DEBUG"):
-			entry.Level = "debug"
-		default:
-			entry.Level = "info"
-		}
-	}
+	return len(p), nil
+}
 
-	return entry
+// Stats 返回远程日志投递统计
+func (w *RemoteWriter) Stats() delivery.Stats {
+	return w.queue.Stats()
 }
 
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr))
+// Close 关闭，等待已写入的日志发送完成
+// 超时未发送的日志保留在落盘目录，下次启动时继续发送
+func (w *RemoteWriter) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.drainTimeout)
+	defer cancel()
+	return w.queue.Close(ctx)
 }
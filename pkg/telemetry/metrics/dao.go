@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/zeromicro/go-zero/core/metric"
+)
+
+// DAO 调用结果标签值
+const (
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+var (
+	metricDAODuration = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: namespace,
+		Subsystem: "dao",
+		Name:      "call_duration_ms",
+		Help:      "dao method call duration(ms).",
+		Labels:    []string{"model", "method", "result"},
+		Buckets:   []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000},
+	})
+)
+
+// ObserveDAO 记录一次 DAO 方法调用，err 非空时按失败记录
+// 记录不存在等预期内的错误应由调用方转换为 nil 后传入
+func ObserveDAO(model, method string, start time.Time, err error) {
+	result := ResultOK
+	if err != nil {
+		result = ResultError
+	}
+	metricDAODuration.Observe(time.Since(start).Milliseconds(), model, method, result)
+}
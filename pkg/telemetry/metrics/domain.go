@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/metric"
+	"github.com/zeromicro/go-zero/core/threading"
+)
+
+// 标签关联变更操作标签值
+const (
+	OpAssign   = "assign"
+	OpUnassign = "unassign"
+)
+
+var (
+	metricTags = metric.NewGaugeVec(&metric.GaugeVecOpts{
+		Namespace: namespace,
+		Subsystem: "tag",
+		Name:      "tags",
+		Help:      "tag count by status(total/enabled).",
+		Labels:    []string{"status"},
+	})
+
+	metricAssociations = metric.NewGaugeVec(&metric.GaugeVecOpts{
+		Namespace: namespace,
+		Subsystem: "tag",
+		Name:      "associations",
+		Help:      "resource tag association count by resource type.",
+		Labels:    []string{"resource_type"},
+	})
+
+	metricAssignments = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: namespace,
+		Subsystem: "tag",
+		Name:      "assignments_total",
+		Help:      "resource tag assign/unassign count, use rate() for assign rate.",
+		Labels:    []string{"op"},
+	})
+
+	// 上一次快照中的资源类型
+	lastTypes     map[string]struct{}
+	lastTypesLock sync.Mutex
+)
+
+// TagStats 标签领域统计快照，覆盖所有租户
+type TagStats struct {
+	Total        int64
+	Enabled      int64
+	Associations map[string]int64 // 资源类型 -> 关联数
+}
+
+// SetTagStats 更新标签领域指标
+// 上一次快照中存在而本次不存在的资源类型置零，避免已清空的资源类型停留在旧值
+func SetTagStats(stats TagStats) {
+	metricTags.Set(float64(stats.Total), "total")
+	metricTags.Set(float64(stats.Enabled), "enabled")
+
+	lastTypesLock.Lock()
+	defer lastTypesLock.Unlock()
+	for resourceType := range lastTypes {
+		if _, ok := stats.Associations[resourceType]; !ok {
+			metricAssociations.Set(0, resourceType)
+		}
+	}
+	lastTypes = make(map[string]struct{}, len(stats.Associations))
+	for resourceType, count := range stats.Associations {
+		metricAssociations.Set(float64(count), resourceType)
+		lastTypes[resourceType] = struct{}{}
+	}
+}
+
+// AddAssignments 累加标签关联变更数，op 为 OpAssign 或 OpUnassign
+func AddAssignments(op string, n int) {
+	if n <= 0 {
+		return
+	}
+	metricAssignments.Add(float64(n), op)
+}
+
+// StartCollector 启动领域指标采集，每隔 interval 调用一次 collect 并更新指标
+// 未启用指标采集时不启动；返回的函数用于停止采集并等待正在进行的采集结束
+func StartCollector(interval time.Duration, collect func(ctx context.Context) (TagStats, error)) (stop func()) {
+	if !Enabled() || interval <= 0 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	threading.GoSafe(func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			collectOnce(ctx, collect)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+}
+
+// collectOnce 执行一次采集，失败时保留上一次的指标值
+func collectOnce(ctx context.Context, collect func(ctx context.Context) (TagStats, error)) {
+	stats, err := collect(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logx.WithContext(ctx).Errorf("采集标签领域指标失败: %v", err)
+		}
+		return
+	}
+	SetTagStats(stats)
+}
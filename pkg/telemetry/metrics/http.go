@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"idrm/pkg/errorx"
+
+	"github.com/zeromicro/go-zero/core/metric"
+)
+
+// CodeOK 请求成功时的业务错误码标签值
+const CodeOK = "0"
+
+var (
+	metricRequests = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "http requests count by route, status and errorx code.",
+		Labels:    []string{"method", "route", "status", "code"},
+	})
+
+	metricRequestDuration = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_ms",
+		Help:      "http requests duration(ms) by route, status and errorx code.",
+		Labels:    []string{"method", "route", "status", "code"},
+		Buckets:   []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000},
+	})
+)
+
+type requestKey struct{}
+
+// Request 单次请求的指标状态，由 HTTP 中间件创建，错误处理时写入业务错误码
+type Request struct {
+	code string
+}
+
+// NewRequestContext 创建请求指标状态并注入 Context
+func NewRequestContext(ctx context.Context) (context.Context, *Request) {
+	req := &Request{code: CodeOK}
+	return context.WithValue(ctx, requestKey{}, req), req
+}
+
+// Code 返回请求的业务错误码标签值，未记录错误时为 CodeOK
+func (r *Request) Code() string {
+	return r.code
+}
+
+// RecordError 记录请求的业务错误码，Context 中没有请求指标状态时忽略
+func RecordError(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	if req, ok := ctx.Value(requestKey{}).(*Request); ok {
+		req.code = strconv.Itoa(ErrorCode(err))
+	}
+}
+
+// ErrorCode 提取错误的 errorx 错误码，非 errorx 错误视为系统错误
+func ErrorCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var codeErr *errorx.CodeError
+	if errors.As(err, &codeErr) {
+		return codeErr.GetCode()
+	}
+	return errorx.ErrCodeSystem
+}
+
+// ErrorHandler 包装 httpx 错误处理函数，在写出错误响应前记录业务错误码
+// next 为空时保持 httpx 的默认行为：以 400 状态码输出错误消息
+func ErrorHandler(next func(context.Context, error) (int, any)) func(context.Context, error) (int, any) {
+	return func(ctx context.Context, err error) (int, any) {
+		RecordError(ctx, err)
+		if next != nil {
+			return next(ctx, err)
+		}
+		return http.StatusBadRequest, err
+	}
+}
+
+// ObserveRequest 记录一次 HTTP 请求，route 应为路由模板而非原始路径，避免标签基数膨胀
+func ObserveRequest(method, route string, status int, code string, duration time.Duration) {
+	statusLabel := strconv.Itoa(status)
+	metricRequests.Inc(method, route, statusLabel, code)
+	metricRequestDuration.Observe(duration.Milliseconds(), method, route, statusLabel, code)
+}
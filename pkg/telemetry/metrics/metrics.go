@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/prometheus"
+)
+
+const namespace = "idrm"
+
+// MetricsConfig 指标配置
+type MetricsConfig struct {
+	Enabled bool
+	Path    string // 指标暴露路径，挂载在业务服务端口上
+}
+
+// Init 初始化指标采集
+// go-zero 的 core/metric 仅在 prometheus 启用后才记录数据，未启用时所有指标调用均为空操作
+func Init(config MetricsConfig) {
+	if !config.Enabled {
+		logx.Info("指标采集未启用")
+		return
+	}
+
+	prometheus.Enable()
+	logx.Infof("指标采集已启用: %s", config.Path)
+}
+
+// Enabled 是否已启用指标采集
+func Enabled() bool {
+	return prometheus.Enabled()
+}
+
+// Handler 返回 Prometheus 指标抓取接口
+// 包含本包的业务指标、pkg/db 的数据库指标以及 Go 运行时指标
+func Handler() http.HandlerFunc {
+	h := promhttp.Handler()
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !Enabled() {
+			http.NotFound(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,162 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"idrm/pkg/errorx"
+)
+
+// scrape 启用指标采集并抓取当前指标
+func scrape(t *testing.T) string {
+	t.Helper()
+	Init(MetricsConfig{Enabled: true, Path: "/metrics"})
+
+	rec := httptest.NewRecorder()
+	Handler()(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("抓取指标失败: %d", rec.Code)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+// assertMetric 断言指标输出包含指定行
+func assertMetric(t *testing.T, body, line string) {
+	t.Helper()
+	if !strings.Contains(body, line+"\n") {
+		t.Errorf("指标输出缺少 %q", line)
+	}
+}
+
+// metricValue 读取指标值，不存在时返回0
+func metricValue(body, series string) float64 {
+	for _, line := range strings.Split(body, "\n") {
+		if value, ok := strings.CutPrefix(line, series+" "); ok {
+			v, _ := strconv.ParseFloat(value, 64)
+			return v
+		}
+	}
+	return 0
+}
+
+// assertDelta 断言两次抓取之间指标的增量
+func assertDelta(t *testing.T, before, after, series string, want float64) {
+	t.Helper()
+	if got := metricValue(after, series) - metricValue(before, series); got != want {
+		t.Errorf("%s 增量 = %v, want %v", series, got, want)
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	scrape(t)
+
+	tests := []struct {
+		name string
+		err  error
+		code string
+	}{
+		{"errorx错误", errorx.NewWithCode(errorx.ErrCodeNotFound), "30001"},
+		{"包装的errorx错误", fmt.Errorf("查询失败: %w", errorx.NewWithCode(errorx.ErrCodeParamInvalid)), "20002"},
+		{"普通错误", errors.New("boom"), "10000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, req := NewRequestContext(context.Background())
+			status, body := ErrorHandler(nil)(ctx, tt.err)
+
+			// 未设置下游处理函数时保持 httpx 默认响应
+			if status != http.StatusBadRequest || body != tt.err {
+				t.Errorf("响应 = %d %v, want 400 %v", status, body, tt.err)
+			}
+			if req.Code() != tt.code {
+				t.Errorf("Code() = %s, want %s", req.Code(), tt.code)
+			}
+		})
+	}
+
+	// 下游处理函数的响应原样返回
+	next := func(ctx context.Context, err error) (int, any) { return http.StatusOK, "handled" }
+	ctx, req := NewRequestContext(context.Background())
+	if status, body := ErrorHandler(next)(ctx, errors.New("boom")); status != http.StatusOK || body != "handled" {
+		t.Errorf("响应 = %d %v, want 200 handled", status, body)
+	}
+	if req.Code() != "10000" {
+		t.Errorf("Code() = %s, want 10000", req.Code())
+	}
+
+	// Context 中没有请求状态时忽略
+	RecordError(context.Background(), errors.New("boom"))
+}
+
+func TestObserveRequest(t *testing.T) {
+	before := scrape(t)
+	ObserveRequest(http.MethodGet, "/api/v1/tags/:id", http.StatusBadRequest, "30001", 30*time.Millisecond)
+	ObserveDAO("tag", "FindOne", time.Now(), errors.New("boom"))
+
+	after := scrape(t)
+	assertDelta(t, before, after, `idrm_http_requests_total{code="30001",method="GET",route="/api/v1/tags/:id",status="400"}`, 1)
+	assertDelta(t, before, after, `idrm_http_request_duration_ms_bucket{code="30001",method="GET",route="/api/v1/tags/:id",status="400",le="25"}`, 0)
+	assertDelta(t, before, after, `idrm_http_request_duration_ms_bucket{code="30001",method="GET",route="/api/v1/tags/:id",status="400",le="50"}`, 1)
+	assertDelta(t, before, after, `idrm_dao_call_duration_ms_count{method="FindOne",model="tag",result="error"}`, 1)
+}
+
+func TestSetTagStats(t *testing.T) {
+	before := scrape(t)
+	SetTagStats(TagStats{Total: 5, Enabled: 3, Associations: map[string]int64{"data_view": 7, "catalog_dataset": 2}})
+	SetTagStats(TagStats{Total: 6, Enabled: 4, Associations: map[string]int64{"data_view": 8}})
+	AddAssignments(OpAssign, 3)
+	AddAssignments(OpUnassign, 0)
+
+	body := scrape(t)
+	assertMetric(t, body, `idrm_tag_tags{status="total"} 6`)
+	assertMetric(t, body, `idrm_tag_tags{status="enabled"} 4`)
+	assertMetric(t, body, `idrm_tag_associations{resource_type="data_view"} 8`)
+	// 本次快照中不存在的资源类型置零
+	assertMetric(t, body, `idrm_tag_associations{resource_type="catalog_dataset"} 0`)
+	assertDelta(t, before, body, `idrm_tag_assignments_total{op="assign"}`, 3)
+	// 变更数为0时不记录
+	assertDelta(t, before, body, `idrm_tag_assignments_total{op="unassign"}`, 0)
+}
+
+func TestStartCollector(t *testing.T) {
+	scrape(t)
+
+	var n atomic.Int32
+	calls := make(chan struct{}, 8)
+	stop := StartCollector(10*time.Millisecond, func(ctx context.Context) (TagStats, error) {
+		defer func() {
+			select {
+			case calls <- struct{}{}:
+			default:
+			}
+		}()
+		if n.Add(1) > 1 {
+			return TagStats{}, errors.New("db down")
+		}
+		return TagStats{Total: 9, Enabled: 9}, nil
+	})
+
+	// 启动后立即采集一次，之后按间隔采集
+	for i := 0; i < 2; i++ {
+		select {
+		case <-calls:
+		case <-time.After(time.Second):
+			t.Fatal("未按间隔采集")
+		}
+	}
+	stop()
+	stop()
+
+	// 采集失败时保留上一次的值
+	assertMetric(t, scrape(t), `idrm_tag_tags{status="total"} 9`)
+}
@@ -5,25 +5,17 @@ import (
 
 	"idrm/pkg/telemetry/audit"
 	"idrm/pkg/telemetry/log"
+	"idrm/pkg/telemetry/metrics"
 	"idrm/pkg/telemetry/trace"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
 // Init 初始化 Telemetry 系统（一站式初始化）
-func Init(config Config) error {
+// opts 传递给审计日志，如使用 db 存储目标时通过 audit.WithDB 提供数据库连接
+func Init(config Config, opts ...audit.Option) error {
 	// 1. 初始化日志系统
-	logConfig := log.LogConfig{
-		Level:         config.Log.Level,
-		Mode:          config.Log.Mode,
-		Path:          config.Log.Path,
-		KeepDays:      config.Log.KeepDays,
-		RemoteEnabled: config.Log.RemoteEnabled,
-		RemoteUrl:     config.Log.RemoteUrl,
-		RemoteBatch:   config.Log.RemoteBatch,
-		RemoteTimeout: config.Log.RemoteTimeout,
-	}
-	log.Init(logConfig, config.ServiceName)
+	log.Init(logConfig(config), config.ServiceName)
 	logx.Infof("Telemetry 初始化: %s v%s (%s)",
 		config.ServiceName, config.ServiceVersion, config.Environment)
 
@@ -33,6 +25,7 @@ func Init(config Config) error {
 		Endpoint: config.Trace.Endpoint,
 		Sampler:  config.Trace.Sampler,
 		Batcher:  config.Trace.Batcher,
+		Headers:  config.Trace.Headers,
 	}
 	if err := trace.Init(traceConfig, config.ServiceName, config.ServiceVersion, config.Environment); err != nil {
 		logx.Errorf("链路追踪初始化失败: %v", err)
@@ -44,8 +37,33 @@ func Init(config Config) error {
 		Enabled: config.Audit.Enabled,
 		Url:     config.Audit.Url,
 		Buffer:  config.Audit.Buffer,
+
+		SpoolDir:     config.Audit.SpoolDir,
+		SpoolMaxSize: config.Audit.SpoolMaxSize,
+		MaxBackoff:   config.Audit.MaxBackoff,
+		DrainTimeout: config.Audit.DrainTimeout,
+
+		Sinks: config.Audit.Sinks,
+		File: audit.FileSinkConfig{
+			Path:       config.Audit.File.Path,
+			MaxSize:    config.Audit.File.MaxSize,
+			MaxBackups: config.Audit.File.MaxBackups,
+		},
+		Kafka: audit.KafkaSinkConfig{
+			Brokers: config.Audit.Kafka.Brokers,
+			Topic:   config.Audit.Kafka.Topic,
+		},
 	}
-	audit.Init(auditConfig, config.ServiceName)
+	if err := audit.Init(auditConfig, config.ServiceName, opts...); err != nil {
+		logx.Errorf("审计日志初始化失败: %v", err)
+		return err
+	}
+
+	// 4. 初始化指标采集
+	metrics.Init(metrics.MetricsConfig{
+		Enabled: config.Metrics.Enabled,
+		Path:    config.Metrics.Path,
+	})
 
 	logx.Info("Telemetry 系统初始化完成")
 	return nil
@@ -64,3 +82,33 @@ func Close(ctx context.Context) {
 	// 关闭日志系统（最后关闭）
 	log.Close()
 }
+
+// ApplyLogConf 将日志配置写入 go-zero logx 配置
+// logx 只按第一次 SetUp 的配置初始化，rest 服务需在创建前写入 RestConf.Log
+func ApplyLogConf(conf *logx.LogConf, config Config) {
+	log.ApplyLogConf(conf, logConfig(config), config.ServiceName)
+}
+
+// logConfig 转换为日志系统配置
+func logConfig(config Config) log.LogConfig {
+	return log.LogConfig{
+		Level:         config.Log.Level,
+		Mode:          config.Log.Mode,
+		Path:          config.Log.Path,
+		KeepDays:      config.Log.KeepDays,
+		RemoteEnabled: config.Log.RemoteEnabled,
+		RemoteUrl:     config.Log.RemoteUrl,
+		RemoteBatch:   config.Log.RemoteBatch,
+		RemoteTimeout: config.Log.RemoteTimeout,
+
+		RemoteSpoolDir:     config.Log.RemoteSpoolDir,
+		RemoteSpoolMaxSize: config.Log.RemoteSpoolMaxSize,
+		RemoteDrainTimeout: config.Log.RemoteDrainTimeout,
+
+		RemoteProtocol:     config.Log.RemoteProtocol,
+		RemoteCompress:     config.Log.RemoteCompress,
+		RemoteMaxBatchSize: config.Log.RemoteMaxBatchSize,
+
+		Environment: config.Environment,
+	}
+}
@@ -0,0 +1,144 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/exporters/zipkin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// 导出器类型（TraceConfig.Batcher）
+const (
+	BatcherOTLP     = "otlp"     // 按 Endpoint 自动选择：带 http(s):// 前缀时使用 OTLP/HTTP，否则使用 OTLP/gRPC
+	BatcherOTLPGRPC = "otlpgrpc" // OTLP/gRPC，Endpoint 如 localhost:4317
+	BatcherOTLPHTTP = "otlphttp" // OTLP/HTTP，Endpoint 如 http://localhost:4318，未指定路径时为 /v1/traces
+	BatcherJaeger   = "jaeger"   // Jaeger collector，Endpoint 如 http://localhost:14268/api/traces
+	BatcherZipkin   = "zipkin"   // Zipkin，Endpoint 如 http://localhost:9411/api/v2/spans
+	BatcherStdout   = "stdout"   // 输出到标准输出，用于本地调试
+	BatcherFile     = "file"     // 输出到 Endpoint 指定的文件，用于本地调试
+	BatcherMemory   = "memory"   // 保存在内存中，用于测试，通过 MemoryExporter 读取
+)
+
+// ErrUnsupportedBatcher 不支持的导出器类型
+var ErrUnsupportedBatcher = errors.New("不支持的链路追踪导出器")
+
+// newExporter 按配置创建导出器
+// 网络导出器均不在创建时连接，连接失败只影响数据上报，不影响服务启动
+func newExporter(ctx context.Context, config TraceConfig) (sdktrace.SpanExporter, error) {
+	batcher := strings.ToLower(strings.TrimSpace(config.Batcher))
+	if batcher == "" || batcher == BatcherOTLP {
+		batcher = BatcherOTLPGRPC
+		if strings.HasPrefix(config.Endpoint, "http://") || strings.HasPrefix(config.Endpoint, "https://") {
+			batcher = BatcherOTLPHTTP
+		}
+	}
+
+	switch batcher {
+	case BatcherOTLPGRPC:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(trimScheme(config.Endpoint))}
+		if !strings.HasPrefix(config.Endpoint, "https://") {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if len(config.Headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(config.Headers))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case BatcherOTLPHTTP:
+		u, err := parseEndpoint(config.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(u.Host)}
+		if u.Scheme != "https" {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if u.Path != "" && u.Path != "/" {
+			opts = append(opts, otlptracehttp.WithURLPath(u.Path))
+		}
+		if len(config.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(config.Headers))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	case BatcherJaeger:
+		return jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(config.Endpoint)))
+	case BatcherZipkin:
+		return zipkin.New(config.Endpoint)
+	case BatcherStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case BatcherFile:
+		f, err := openFile(config.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &fileExporter{SpanExporter: exporter, file: f}, nil
+	case BatcherMemory:
+		return tracetest.NewInMemoryExporter(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedBatcher, config.Batcher)
+	}
+}
+
+// trimScheme 去掉地址中的协议前缀，gRPC 只需要 host:port
+func trimScheme(endpoint string) string {
+	endpoint = strings.TrimPrefix(endpoint, "http://")
+	endpoint = strings.TrimPrefix(endpoint, "https://")
+	return strings.TrimSuffix(endpoint, "/")
+}
+
+// parseEndpoint 解析 HTTP 导出地址，未带协议前缀时按 http 处理
+func parseEndpoint(endpoint string) (*url.URL, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("链路追踪地址无效: %s", endpoint)
+	}
+	return u, nil
+}
+
+// fileExporter 关闭时同时关闭导出文件
+type fileExporter struct {
+	sdktrace.SpanExporter
+	file *os.File
+}
+
+// Shutdown 关闭导出器和文件
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	err := e.SpanExporter.Shutdown(ctx)
+	if cerr := e.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// openFile 以追加方式打开导出文件
+func openFile(path string) (*os.File, error) {
+	if path == "" {
+		path = filepath.Join("logs", "trace.log")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("创建链路追踪文件目录失败: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("打开链路追踪文件失败: %w", err)
+	}
+	return f, nil
+}
@@ -2,18 +2,18 @@ package trace
 
 import (
 	"context"
+	"errors"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -21,17 +21,30 @@ import (
 var (
 	tracerProvider *sdktrace.TracerProvider
 	tracer         trace.Tracer
+	memoryExporter *tracetest.InMemoryExporter
+
+	// lastExportErr 最近一次导出失败，供健康检查判断采集端是否可用
+	lastExportErr atomic.Pointer[exportFailure]
 )
 
+// exportFailure 导出失败记录
+type exportFailure struct {
+	err error
+	at  time.Time
+}
+
 // TraceConfig 链路追踪配置
 type TraceConfig struct {
 	Enabled  bool
 	Endpoint string
 	Sampler  float64
-	Batcher  string
+	Batcher  string            // otlp/otlpgrpc/otlphttp/jaeger/zipkin/stdout/file/memory，默认 otlp
+	Headers  map[string]string // OTLP 请求头，如鉴权信息
 }
 
 // Init 初始化链路追踪
+// 导出器不在启动时连接采集端，采集端不可用时只丢弃链路数据并记录错误日志；
+// 导出器创建失败时降级为不上报，不影响服务启动；只有导出器类型配置错误时返回错误
 func Init(config TraceConfig, serviceName, version, environment string) error {
 	if !config.Enabled {
 		logx.Info("链路追踪未启用")
@@ -40,21 +53,14 @@ func Init(config TraceConfig, serviceName, version, environment string) error {
 
 	ctx := context.Background()
 
-	// 1. 创建 OTLP gRPC Exporter
-	conn, err := grpc.DialContext(ctx, config.Endpoint,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
-		grpc.WithTimeout(5*time.Second),
-	)
+	// 1. 创建 Exporter
+	exporter, err := newExporter(ctx, config)
 	if err != nil {
-		logx.Errorf("连接 OTLP endpoint 失败: %v", err)
-		return err
-	}
-
-	exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithGRPCConn(conn))
-	if err != nil {
-		logx.Errorf("创建 OTLP exporter 失败: %v", err)
-		return err
+		if errors.Is(err, ErrUnsupportedBatcher) {
+			return err
+		}
+		logx.Errorf("创建链路追踪导出器失败，链路数据将不会上报: %v", err)
+		return nil
 	}
 
 	// 2. 创建 Resource
@@ -70,33 +76,49 @@ func Init(config TraceConfig, serviceName, version, environment string) error {
 		return err
 	}
 
-	// 3. 创建 TracerProvider
-	tracerProvider = sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter,
+	// 3. 创建 TracerProvider，内存导出器同步导出以便测试立即读取
+	var processor sdktrace.TracerProviderOption
+	if mem, ok := exporter.(*tracetest.InMemoryExporter); ok {
+		memoryExporter = mem
+		processor = sdktrace.WithSyncer(exporter)
+	} else {
+		processor = sdktrace.WithBatcher(exporter,
 			sdktrace.WithMaxQueueSize(1000),
 			sdktrace.WithMaxExportBatchSize(100),
 			sdktrace.WithBatchTimeout(5*time.Second),
-		),
+		)
+	}
+	tracerProvider = sdktrace.NewTracerProvider(
+		processor,
 		sdktrace.WithResource(res),
 		sdktrace.WithSampler(sdktrace.TraceIDRatioBased(config.Sampler)),
 	)
 
-	// 4. 设置全局 TracerProvider
+	// 4. 设置全局 TracerProvider，导出失败通过日志输出
 	otel.SetTracerProvider(tracerProvider)
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
 		propagation.TraceContext{},
 		propagation.Baggage{},
 	))
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		lastExportErr.Store(&exportFailure{err: err, at: time.Now()})
+		logx.Errorf("链路追踪导出失败: %v", err)
+	}))
 
 	// 5. 创建 Tracer
 	tracer = tracerProvider.Tracer(serviceName)
 
-	logx.Infof("链路追踪初始化完成 [endpoint=%s, sampler=%.2f]",
-		config.Endpoint, config.Sampler)
+	logx.Infof("链路追踪初始化完成 [batcher=%s, endpoint=%s, sampler=%.2f]",
+		config.Batcher, config.Endpoint, config.Sampler)
 
 	return nil
 }
 
+// MemoryExporter 返回内存导出器，仅在 Batcher 为 memory 时有效
+func MemoryExporter() *tracetest.InMemoryExporter {
+	return memoryExporter
+}
+
 // Start 开始一个 Span
 func Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
 	if tracer == nil {
@@ -111,6 +133,19 @@ func Tracer() trace.Tracer {
 	return tracer
 }
 
+// Enabled 是否已启用链路追踪
+func Enabled() bool {
+	return tracerProvider != nil
+}
+
+// LastExportError 返回最近一次导出失败的时间和错误，从未失败时 err 为 nil
+func LastExportError() (at time.Time, err error) {
+	if f := lastExportErr.Load(); f != nil {
+		return f.at, f.err
+	}
+	return time.Time{}, nil
+}
+
 // Close 关闭链路追踪
 func Close(ctx context.Context) error {
 	if tracerProvider != nil {
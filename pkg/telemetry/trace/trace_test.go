@@ -0,0 +1,108 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+)
+
+// TestInit_Memory 测试内存导出器
+func TestInit_Memory(t *testing.T) {
+	if err := Init(TraceConfig{Enabled: true, Batcher: BatcherMemory, Sampler: 1}, "test", "1.0.0", "test"); err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	defer Close(context.Background())
+
+	_, span := Start(context.Background(), "create-tag")
+	span.End()
+
+	spans := MemoryExporter().GetSpans()
+	if len(spans) != 1 || spans[0].Name != "create-tag" {
+		t.Errorf("期望记录1个 span, 实际 %+v", spans)
+	}
+}
+
+// TestLastExportError 测试记录导出失败
+func TestLastExportError(t *testing.T) {
+	if err := Init(TraceConfig{Enabled: true, Batcher: BatcherMemory, Sampler: 1}, "test", "1.0.0", "test"); err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	defer Close(context.Background())
+
+	boom := errors.New("collector unavailable")
+	otel.Handle(boom)
+
+	at, err := LastExportError()
+	if !errors.Is(err, boom) || time.Since(at) > time.Second {
+		t.Errorf("LastExportError() = %v, %v, want %v", at, err, boom)
+	}
+	if !Enabled() {
+		t.Error("初始化后应为启用状态")
+	}
+}
+
+// TestInit_Unreachable 测试采集端不可用时不阻塞启动
+func TestInit_Unreachable(t *testing.T) {
+	// 占用一个端口后立即释放，确保无服务监听
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("获取端口失败: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	for _, batcher := range []string{BatcherOTLPGRPC, BatcherOTLPHTTP, BatcherZipkin} {
+		t.Run(batcher, func(t *testing.T) {
+			endpoint := addr
+			if batcher == BatcherZipkin {
+				endpoint = "http://" + addr + "/api/v2/spans"
+			}
+
+			start := time.Now()
+			err := Init(TraceConfig{Enabled: true, Batcher: batcher, Endpoint: endpoint, Sampler: 1}, "test", "1.0.0", "test")
+			if err != nil {
+				t.Fatalf("初始化失败: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("初始化不应等待连接, 耗时 %v", elapsed)
+			}
+
+			_, span := Start(context.Background(), "op")
+			span.End()
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			Close(ctx)
+		})
+	}
+}
+
+// TestInit_File 测试文件导出器
+func TestInit_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.log")
+	if err := Init(TraceConfig{Enabled: true, Batcher: BatcherFile, Endpoint: path, Sampler: 1}, "test", "1.0.0", "test"); err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	_, span := Start(context.Background(), "op")
+	span.End()
+	if err := Close(context.Background()); err != nil {
+		t.Errorf("关闭失败: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || !strings.Contains(string(data), `"Name":"op"`) {
+		t.Errorf("文件中应包含 span: %s, %v", data, err)
+	}
+}
+
+// TestInit_UnsupportedBatcher 测试导出器类型配置错误
+func TestInit_UnsupportedBatcher(t *testing.T) {
+	err := Init(TraceConfig{Enabled: true, Batcher: "skywalking"}, "test", "1.0.0", "test")
+	if !errors.Is(err, ErrUnsupportedBatcher) {
+		t.Errorf("期望 ErrUnsupportedBatcher, 实际 %v", err)
+	}
+}
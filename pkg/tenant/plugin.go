@@ -0,0 +1,181 @@
+package tenant
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	pluginName = "idrm:tenant"
+
+	// skipKey 跳过租户过滤的 Statement 设置键
+	skipKey = "idrm:tenant_skip"
+)
+
+// Visibility 租户表的读可见性
+type Visibility int
+
+const (
+	// Private 仅当前租户可读写
+	Private Visibility = iota
+	// Shareable 当前租户可读写自己的数据，并可读取共享租户的数据
+	Shareable
+)
+
+var (
+	tablesMu sync.RWMutex
+	tables   = map[string]Visibility{}
+)
+
+// RegisterTable 登记按租户隔离的表，由模型包在 init 中调用
+// 登记后该表的查询、更新、删除由插件自动追加租户条件，插入时自动填充租户
+func RegisterTable(table string, v Visibility) {
+	tablesMu.Lock()
+	defer tablesMu.Unlock()
+	tables[table] = v
+}
+
+func lookupTable(table string) (Visibility, bool) {
+	tablesMu.RLock()
+	defer tablesMu.RUnlock()
+	v, ok := tables[table]
+	return v, ok
+}
+
+// AllTenants 返回跳过租户过滤的会话，仅用于平台级统计等跨租户场景
+func AllTenants(db *gorm.DB) *gorm.DB {
+	return db.Set(skipKey, true).Session(&gorm.Session{})
+}
+
+// Plugin 租户隔离插件，对已登记的表强制追加租户条件
+type Plugin struct{}
+
+// NewPlugin 创建租户隔离插件
+func NewPlugin() *Plugin {
+	return &Plugin{}
+}
+
+// Name 插件名称
+func (p *Plugin) Name() string {
+	return pluginName
+}
+
+// Initialize 注册回调
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Query().Before("gorm:query").Register(pluginName+":query", p.read); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register(pluginName+":row", p.read); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register(pluginName+":update", p.write); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register(pluginName+":delete", p.write); err != nil {
+		return err
+	}
+	return cb.Create().Before("gorm:create").Register(pluginName+":create", p.create)
+}
+
+// read 读操作：私有表仅当前租户，可共享表为当前租户及共享数据
+func (p *Plugin) read(tx *gorm.DB) {
+	v, id, ok := p.resolve(tx)
+	if !ok {
+		return
+	}
+	column := clause.Column{Table: tx.Statement.Table, Name: Column}
+	if v == Shareable && id != Shared {
+		tx.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+			clause.IN{Column: column, Values: []interface{}{id, Shared}},
+		}})
+		return
+	}
+	tx.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.Eq{Column: column, Value: id}}})
+}
+
+// write 更新和删除：仅当前租户自己的数据
+func (p *Plugin) write(tx *gorm.DB) {
+	if _, id, ok := p.resolve(tx); ok {
+		column := clause.Column{Table: tx.Statement.Table, Name: Column}
+		tx.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.Eq{Column: column, Value: id}}})
+	}
+}
+
+// create 插入：未指定租户时填充当前租户，指定了其他租户时拒绝
+func (p *Plugin) create(tx *gorm.DB) {
+	_, id, ok := p.resolve(tx)
+	if !ok || tx.Statement.Schema == nil {
+		return
+	}
+	field := tx.Statement.Schema.LookUpField(Column)
+	if field == nil {
+		return
+	}
+
+	rv := tx.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := assign(tx, field, rv.Index(i), id); err != nil {
+				tx.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := assign(tx, field, rv, id); err != nil {
+			tx.AddError(err)
+		}
+	}
+}
+
+// assign 填充或校验单行的租户
+func assign(tx *gorm.DB, field *schema.Field, rv reflect.Value, id string) error {
+	ctx := tx.Statement.Context
+	value, zero := field.ValueOf(ctx, rv)
+	if zero {
+		return field.Set(ctx, rv, id)
+	}
+	if value != id {
+		return ErrInvalidTenant
+	}
+	return nil
+}
+
+// resolve 判断语句是否需要租户过滤，返回表的可见性和当前租户
+// 上下文缺少租户时语句直接失败，避免漏加条件读写到其他租户的数据
+func (p *Plugin) resolve(tx *gorm.DB) (Visibility, string, bool) {
+	stmt := tx.Statement
+	if tx.Error != nil || stmt.SQL.Len() > 0 {
+		return 0, "", false
+	}
+	if skip, ok := tx.Get(skipKey); ok && skip == true {
+		return 0, "", false
+	}
+
+	v, ok := lookupTable(tableName(stmt))
+	if !ok {
+		return 0, "", false
+	}
+	id, ok := FromContext(stmt.Context)
+	if !ok {
+		tx.AddError(ErrMissingTenant)
+		return 0, "", false
+	}
+	return v, id, true
+}
+
+// tableName 语句操作的实际表名，Table("resource_tags AS rt") 时 Statement.Table 为别名
+func tableName(stmt *gorm.Statement) string {
+	if stmt.TableExpr != nil {
+		if fields := strings.Fields(stmt.TableExpr.SQL); len(fields) > 0 {
+			return strings.Trim(fields[0], "`\"")
+		}
+	}
+	return stmt.Table
+}
@@ -0,0 +1,151 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type privateRow struct {
+	Id       int64
+	TenantId string
+	Name     string
+}
+
+func (privateRow) TableName() string { return "plugin_private_rows" }
+
+type sharedRow struct {
+	Id       int64
+	TenantId string
+	Name     string
+}
+
+func (sharedRow) TableName() string { return "plugin_shared_rows" }
+
+func init() {
+	RegisterTable(privateRow{}.TableName(), Private)
+	RegisterTable(sharedRow{}.TableName(), Shareable)
+}
+
+// setupPluginDB 创建注册了租户插件的测试数据库，并写入 t1、t2 和共享租户的数据
+func setupPluginDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法创建测试数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&privateRow{}, &sharedRow{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+	if err := db.Use(NewPlugin()); err != nil {
+		t.Fatalf("注册租户插件失败: %v", err)
+	}
+
+	for _, id := range []string{"t1", "t2", Shared} {
+		ctx := WithTenant(context.Background(), id)
+		if err := db.WithContext(ctx).Create(&privateRow{Name: id}).Error; err != nil {
+			t.Fatalf("写入测试数据失败: %v", err)
+		}
+		if err := db.WithContext(ctx).Create(&sharedRow{Name: id}).Error; err != nil {
+			t.Fatalf("写入测试数据失败: %v", err)
+		}
+	}
+	return db
+}
+
+// TestPlugin_Query 测试未手写租户条件的查询被自动过滤
+func TestPlugin_Query(t *testing.T) {
+	db := setupPluginDB(t)
+
+	tests := []struct {
+		tenant  string
+		private int64
+		shared  int64
+	}{
+		{"t1", 1, 2},
+		{"t2", 1, 2},
+		{Shared, 1, 1},
+	}
+	for _, tt := range tests {
+		ctx := WithTenant(context.Background(), tt.tenant)
+		var private, shared int64
+		db.WithContext(ctx).Model(&privateRow{}).Count(&private)
+		db.WithContext(ctx).Model(&sharedRow{}).Count(&shared)
+		if private != tt.private || shared != tt.shared {
+			t.Errorf("租户%s 期望=%d/%d, 实际=%d/%d", tt.tenant, tt.private, tt.shared, private, shared)
+		}
+	}
+
+	// 别名联表时按实际表名识别
+	var names []string
+	ctx := WithTenant(context.Background(), "t1")
+	err := db.WithContext(ctx).
+		Table("plugin_private_rows AS p").
+		Joins("JOIN plugin_shared_rows s ON s.name = p.name").
+		Pluck("p.name", &names).Error
+	if err != nil || len(names) != 1 || names[0] != "t1" {
+		t.Errorf("期望=[t1], 实际=%v, err=%v", names, err)
+	}
+}
+
+// TestPlugin_Write 测试更新和删除仅作用于当前租户自己的数据
+func TestPlugin_Write(t *testing.T) {
+	db := setupPluginDB(t)
+	ctx := WithTenant(context.Background(), "t1")
+
+	result := db.WithContext(ctx).Model(&sharedRow{}).Where("1 = 1").Update("name", "改名")
+	if result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("期望更新1行, 实际=%d, err=%v", result.RowsAffected, result.Error)
+	}
+	result = db.WithContext(ctx).Where("1 = 1").Delete(&sharedRow{})
+	if result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("期望删除1行, 实际=%d, err=%v", result.RowsAffected, result.Error)
+	}
+
+	var total int64
+	AllTenants(db).Model(&sharedRow{}).Count(&total)
+	if total != 2 {
+		t.Errorf("其他租户的数据不应被删除, 剩余=%d", total)
+	}
+}
+
+// TestPlugin_Create 测试插入时填充并校验租户
+func TestPlugin_Create(t *testing.T) {
+	db := setupPluginDB(t)
+	ctx := WithTenant(context.Background(), "t1")
+
+	rows := []*privateRow{{Name: "a"}, {Name: "b", TenantId: "t1"}}
+	if err := db.WithContext(ctx).Create(&rows).Error; err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+	for _, row := range rows {
+		if row.TenantId != "t1" {
+			t.Errorf("期望租户=t1, 实际=%s", row.TenantId)
+		}
+	}
+
+	err := db.WithContext(ctx).Create(&privateRow{Name: "c", TenantId: "t2"}).Error
+	if !errors.Is(err, ErrInvalidTenant) {
+		t.Errorf("期望ErrInvalidTenant, 实际=%v", err)
+	}
+}
+
+// TestPlugin_MissingTenant 测试上下文缺少租户时语句失败
+func TestPlugin_MissingTenant(t *testing.T) {
+	db := setupPluginDB(t)
+
+	var rows []*privateRow
+	if err := db.Find(&rows).Error; !errors.Is(err, ErrMissingTenant) {
+		t.Errorf("期望ErrMissingTenant, 实际=%v", err)
+	}
+	if err := db.Create(&privateRow{Name: "x"}).Error; !errors.Is(err, ErrMissingTenant) {
+		t.Errorf("期望ErrMissingTenant, 实际=%v", err)
+	}
+
+	// 显式跳过时可以跨租户读取
+	if err := AllTenants(db).Find(&rows).Error; err != nil || len(rows) != 3 {
+		t.Errorf("期望3条记录, 实际=%d, err=%v", len(rows), err)
+	}
+}
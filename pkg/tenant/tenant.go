@@ -0,0 +1,112 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// 常量定义
+const (
+	// Column 租户列名
+	Column = "tenant_id"
+
+	// Shared 共享租户，该租户下的数据对所有租户可见，仅平台管理员可维护
+	Shared = "*"
+
+	// MaxLength 租户ID最大长度
+	MaxLength = 64
+)
+
+// 错误定义
+var (
+	ErrMissingTenant = errors.New("缺少租户信息")
+	ErrInvalidTenant = errors.New("租户ID无效")
+)
+
+type tenantKey struct{}
+
+// WithTenant 将租户ID写入上下文
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// FromContext 从上下文读取租户ID
+func FromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	id, ok := ctx.Value(tenantKey{}).(string)
+	return id, ok && id != ""
+}
+
+// Require 读取租户ID，缺失时返回 ErrMissingTenant
+func Require(ctx context.Context) (string, error) {
+	id, ok := FromContext(ctx)
+	if !ok {
+		return "", ErrMissingTenant
+	}
+	return id, nil
+}
+
+// Validate 校验租户ID
+func Validate(id string) error {
+	if id == "" || len(id) > MaxLength || strings.ContainsAny(id, " \t\r\n") {
+		return ErrInvalidTenant
+	}
+	return nil
+}
+
+// Visible 当前租户是否可以读取属于 owner 的数据
+func Visible(ctx context.Context, owner string) bool {
+	id, ok := FromContext(ctx)
+	if !ok {
+		return false
+	}
+	return owner == id || owner == Shared
+}
+
+// Owns 当前租户是否可以修改属于 owner 的数据
+func Owns(ctx context.Context, owner string) bool {
+	id, ok := FromContext(ctx)
+	return ok && owner == id
+}
+
+// ReadScope 读操作范围：当前租户及共享数据
+func ReadScope(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return ReadScopeOn(ctx, Column)
+}
+
+// ReadScopeOn 指定列名（如联表时的 "t.tenant_id"）的读操作范围
+func ReadScopeOn(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		id, ok := FromContext(ctx)
+		if !ok {
+			tx.AddError(ErrMissingTenant)
+			return tx
+		}
+		if id == Shared {
+			return tx.Where(column+" = ?", Shared)
+		}
+		return tx.Where(column+" IN ?", []string{id, Shared})
+	}
+}
+
+// WriteScope 写操作范围：仅当前租户自己的数据
+func WriteScope(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return WriteScopeOn(ctx, Column)
+}
+
+// WriteScopeOn 指定列名的写操作范围
+func WriteScopeOn(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		id, ok := FromContext(ctx)
+		if !ok {
+			tx.AddError(ErrMissingTenant)
+			return tx
+		}
+		return tx.Where(column+" = ?", id)
+	}
+}
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"time"
+
+	"idrm/pkg/errorx"
+)
+
+// timeLayouts 时间参数支持的格式，不带时区的按服务器本地时间解析
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"}
+
+// ParseTime 解析请求中的时间参数，格式错误时返回参数格式错误码
+func ParseTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errorx.NewWithMsg(errorx.ErrCodeParamFormat, "时间格式错误，应为 RFC3339 或 2006-01-02 15:04:05")
+}
@@ -0,0 +1,47 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"idrm/model/tag_management/history"
+	"idrm/model/tag_management/resource_tag"
+	"idrm/model/tag_management/tag"
+	"idrm/pkg/db"
+	"idrm/pkg/idempotency"
+	"idrm/pkg/migrate"
+	"idrm/pkg/telemetry/audit"
+)
+
+// TestSchemaDrift 测试迁移脚本与 GORM 模型结构一致
+// 仅允许数据库中存在模型未声明的索引（如 uk_name），列缺失和类型不一致视为失败
+func TestSchemaDrift(t *testing.T) {
+	gormDB, err := db.InitGorm(db.Config{
+		Driver:   db.DriverSQLite,
+		Database: filepath.Join(t.TempDir(), "drift.db"),
+	})
+	if err != nil {
+		t.Fatalf("初始化数据库失败: %v", err)
+	}
+	defer db.Close(gormDB)
+
+	m := migrate.New(gormDB, os.DirFS(filepath.Join("..", "..", "migrations")))
+	ctx := context.Background()
+	if _, err := m.Up(ctx, 0); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+
+	drifts, err := m.CheckDrift(ctx, &tag.Tag{}, &resource_tag.ResourceTag{}, &history.TagHistory{}, &history.ResourceTagHistory{}, &audit.AuditRecord{}, &idempotency.Record{})
+	if err != nil {
+		t.Fatalf("检测结构漂移失败: %v", err)
+	}
+	for _, d := range drifts {
+		if d.Kind == migrate.DriftUndeclaredIndex {
+			t.Logf("模型未声明的索引: %s", d)
+			continue
+		}
+		t.Errorf("结构漂移: %s", d)
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"testing"
 	"time"
 
+	"idrm/model/tag_management/history"
 	"idrm/model/tag_management/resource_tag"
 	"idrm/model/tag_management/tag"
+	"idrm/pkg/tenant"
 
 	"github.com/stretchr/testify/suite"
 	"gorm.io/driver/sqlite"
@@ -29,17 +31,19 @@ func (suite *TagManagementTestSuite) SetupSuite() {
 	// 创建内存数据库
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	suite.Require().NoError(err)
+	suite.Require().NoError(db.Use(tenant.NewPlugin()))
 
 	suite.db = db
 }
 
 // SetupTest 每个测试前运行 - 重置数据库
 func (suite *TagManagementTestSuite) SetupTest() {
-	// 删除所有表
-	suite.db.Migrator().DropTable(&resource_tag.ResourceTag{}, &tag.Tag{})
+	// 删除所有表，表结构操作不属于任何租户
+	db := tenant.AllTenants(suite.db)
+	db.Migrator().DropTable(&resource_tag.ResourceTag{}, &tag.Tag{})
 
 	// 重新创建表
-	err := suite.db.AutoMigrate(&tag.Tag{}, &resource_tag.ResourceTag{})
+	err := db.AutoMigrate(&tag.Tag{}, &resource_tag.ResourceTag{}, &history.TagHistory{}, &history.ResourceTagHistory{})
 	suite.Require().NoError(err)
 }
 
@@ -56,7 +60,7 @@ func (suite *TagManagementTestSuite) getModels() (tag.TagModel, resource_tag.Res
 
 // TestTagManagementCRUD 测试标签CRUD完整流程
 func (suite *TagManagementTestSuite) TestTagManagementCRUD() {
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	tagModel, resourceTagModel := suite.getModels()
 
 	// 1. 创建标签
@@ -145,7 +149,7 @@ func (suite *TagManagementTestSuite) TestTagManagementCRUD() {
 
 // TestTagManagement_Validation 测试业务规则
 func (suite *TagManagementTestSuite) TestTagManagement_Validation() {
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	tagModel, resourceTagModel := suite.getModels()
 
 	// 1. 测试名称唯一性检查
@@ -153,11 +157,14 @@ func (suite *TagManagementTestSuite) TestTagManagement_Validation() {
 	_, err := tagModel.Insert(ctx, tag1)
 	suite.NoError(err)
 
-	// 注意: GORM不会自动检查唯一性，这应该在Logic层实现
-	// 这里我们验证同名标签可以被插入（数据库层面）
+	// 名称在租户内唯一：同租户重复插入失败，其他租户可使用相同名称
 	tag2 := &tag.Tag{Name: "唯一名称", Status: tag.StatusEnabled, CreatedBy: 1}
 	_, err = tagModel.Insert(ctx, tag2)
-	// GORM会成功插入，没有错误
+	suite.Error(err)
+
+	otherCtx := tenant.WithTenant(context.Background(), "t2")
+	tag2 = &tag.Tag{Name: "唯一名称", Status: tag.StatusEnabled, CreatedBy: 1}
+	_, err = tagModel.Insert(otherCtx, tag2)
 	suite.NoError(err)
 
 	// 2. 测试删除正在使用的标签
@@ -174,7 +181,7 @@ func (suite *TagManagementTestSuite) TestTagManagement_Validation() {
 
 // TestTagManagement_Transaction 测试事务处理
 func (suite *TagManagementTestSuite) TestTagManagement_Transaction() {
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	tagModel, _ := suite.getModels()
 
 	// 测试事务回滚
@@ -217,7 +224,7 @@ func (suite *TagManagementTestSuite) TestTagManagement_Transaction() {
 
 // TestTagManagement_Search 测试搜索功能
 func (suite *TagManagementTestSuite) TestTagManagement_Search() {
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	tagModel, _ := suite.getModels()
 
 	// 创建多个标签
@@ -244,7 +251,7 @@ func (suite *TagManagementTestSuite) TestTagManagement_Search() {
 
 // TestTagManagement_MultiTagSearch 测试多标签AND查询
 func (suite *TagManagementTestSuite) TestTagManagement_MultiTagSearch() {
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	tagModel, resourceTagModel := suite.getModels()
 
 	// 创建标签
@@ -275,7 +282,7 @@ func (suite *TagManagementTestSuite) TestTagManagement_MultiTagSearch() {
 
 // TestTagManagement_Performance 测试性能
 func (suite *TagManagementTestSuite) TestTagManagement_Performance() {
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	tagModel, _ := suite.getModels()
 
 	// 批量创建100个标签
@@ -302,7 +309,7 @@ func (suite *TagManagementTestSuite) TestTagManagement_Performance() {
 
 // TestTagManagement_StatusUpdate 测试状态更新
 func (suite *TagManagementTestSuite) TestTagManagement_StatusUpdate() {
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	tagModel, _ := suite.getModels()
 
 	tagRecord := &tag.Tag{Name: "待禁用", Status: tag.StatusEnabled, CreatedBy: 1}
@@ -319,18 +326,17 @@ func (suite *TagManagementTestSuite) TestTagManagement_StatusUpdate() {
 
 // TestTagManagement_EdgeCases 测试边界情况
 func (suite *TagManagementTestSuite) TestTagManagement_EdgeCases() {
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	tagModel, resourceTagModel := suite.getModels()
 
 	// 1. 查询不存在的标签
 	_, err := tagModel.FindOne(ctx, 99999)
 	suite.Equal(tag.ErrNotFound, err)
 
-	// 2. 更新不存在的标签 - GORM不会报错，但affected为0
+	// 2. 更新不存在的标签 - 带版本条件的更新未命中时返回 ErrNotFound
 	nonExistent := &tag.Tag{Id: 99999, Name: "不存在"}
 	err = tagModel.Update(ctx, nonExistent)
-	// 由于GORM特性，更新不存在的记录不会报错
-	suite.NoError(err)
+	suite.ErrorIs(err, tag.ErrNotFound)
 
 	// 3. 删除不存在的标签 - GORM不会报错，但affected为0
 	err = tagModel.Delete(ctx, 99999)
@@ -352,7 +358,7 @@ func (suite *TagManagementTestSuite) TestTagManagement_EdgeCases() {
 
 // TestTagManagement_Concurrency 测试并发安全
 func (suite *TagManagementTestSuite) TestTagManagement_Concurrency() {
-	ctx := context.Background()
+	ctx := tenant.WithTenant(context.Background(), "t1")
 	tagModel, resourceTagModel := suite.getModels()
 
 	tagRecord := &tag.Tag{Name: "并发测试", Status: tag.StatusEnabled, CreatedBy: 1}